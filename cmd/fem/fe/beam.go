@@ -0,0 +1,335 @@
+package fe
+
+import (
+	"math"
+	"wfem/cmd/fem/util"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// localAxes - unit vectors of the local coordinate system of the bar (rows): x goes from the first node to the
+// second one, y lies in the plane of x and the orientation vector (global z by default, global y for the bars
+// parallel to z), z = x * y. In 2D the local z is the global one
+func localAxes(x *mat.Dense, orientation [3]float64) *mat.Dense {
+	_, dim := x.Dims()
+	var ex, v [3]float64
+	for i := 0; i < dim; i++ {
+		ex[i] = x.At(1, i) - x.At(0, i)
+	}
+	ex = normalize(ex)
+	if dim == 2 {
+		return mat.NewDense(3, 3, []float64{ex[0], ex[1], 0.0, -ex[1], ex[0], 0.0, 0.0, 0.0, 1.0})
+	}
+	v = orientation
+	if v == [3]float64{} {
+		v = [3]float64{0.0, 0.0, 1.0}
+		if math.Abs(ex[2]) > 0.999 {
+			v = [3]float64{0.0, 1.0, 0.0}
+		}
+	}
+	dot := v[0]*ex[0] + v[1]*ex[1] + v[2]*ex[2]
+	ey := normalize([3]float64{v[0] - dot*ex[0], v[1] - dot*ex[1], v[2] - dot*ex[2]})
+	ez := [3]float64{ex[1]*ey[2] - ex[2]*ey[1], ex[2]*ey[0] - ex[0]*ey[2], ex[0]*ey[1] - ex[1]*ey[0]}
+	return mat.NewDense(3, 3, []float64{ex[0], ex[1], ex[2], ey[0], ey[1], ey[2], ez[0], ez[1], ez[2]})
+}
+
+func normalize(v [3]float64) [3]float64 {
+	l := math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+	return [3]float64{v[0] / l, v[1] / l, v[2] / l}
+}
+
+// FiniteElementTruss - bar of the plane or space truss, it carries only the axial force
+type FiniteElementTruss struct {
+	FE
+	x *mat.Dense
+}
+
+func NewTruss(x *mat.Dense, params FiniteElementParameters) *FiniteElementTruss {
+	_, dim := x.Dims()
+	return &FiniteElementTruss{FE: FE{size: 2, freedom: dim, FiniteElementParameters: params}, x: x}
+}
+
+func (f *FiniteElementTruss) length() float64 {
+	return util.Volume1d2(f.x)
+}
+
+// direction - the unit vector along the bar for both nodes: (-c, c)
+func (f *FiniteElementTruss) direction() *mat.VecDense {
+	res := mat.NewVecDense(2*f.freedom, nil)
+	for i := 0; i < f.freedom; i++ {
+		c := (f.x.At(1, i) - f.x.At(0, i)) / f.length()
+		res.SetVec(i, -c)
+		res.SetVec(f.freedom+i, c)
+	}
+	return res
+}
+
+// thermalStrain - thermal strain of the bar for the mean temperature of the nodes
+func (f *FiniteElementTruss) thermalStrain() float64 {
+	return 0.5 * f.ThermalExpansion * (f.nodeTemperature(0) + f.nodeTemperature(1))
+}
+
+func (f *FiniteElementTruss) Create() *mat.Dense {
+	res := mat.NewDense(2*f.freedom, 2*f.freedom, nil)
+	res.Outer(f.YoungModulus*f.Area/f.length(), f.direction(), f.direction())
+	return res
+}
+
+// Calculate - the axial strain, stress and force (rows) at the nodes
+func (f *FiniteElementTruss) Calculate(u *mat.VecDense) *mat.Dense {
+	res := mat.NewDense(3, 2, nil)
+	strain := mat.Dot(f.direction(), u) / f.length()
+	stress := f.YoungModulus * (strain - f.thermalStrain())
+	for i := 0; i < 2; i++ {
+		res.Set(0, i, strain)
+		res.Set(1, i, stress)
+		res.Set(2, i, stress*f.Area)
+	}
+	return res
+}
+
+func (f *FiniteElementTruss) Mass(lumped bool) *mat.Dense {
+	m := f.Density * f.Area * f.length()
+	res := mat.NewDense(2*f.freedom, 2*f.freedom, nil)
+	for i := 0; i < f.freedom; i++ {
+		if lumped {
+			res.Set(i, i, m/2.0)
+			res.Set(f.freedom+i, f.freedom+i, m/2.0)
+			continue
+		}
+		res.Set(i, i, m/3.0)
+		res.Set(f.freedom+i, f.freedom+i, m/3.0)
+		res.Set(i, f.freedom+i, m/6.0)
+		res.Set(f.freedom+i, i, m/6.0)
+	}
+	return res
+}
+
+// Geometric - geometric stiffness of the bar with the axial force, it acts across the bar
+func (f *FiniteElementTruss) Geometric(u *mat.VecDense) *mat.Dense {
+	force := f.Calculate(u).At(2, 0)
+	d := f.direction()
+	res := mat.NewDense(2*f.freedom, 2*f.freedom, nil)
+	for i := 0; i < 2*f.freedom; i++ {
+		for j := 0; j < 2*f.freedom; j++ {
+			if i%f.freedom == j%f.freedom {
+				value := 1.0
+				if i/f.freedom != j/f.freedom {
+					value = -1.0
+				}
+				res.Set(i, j, value)
+			}
+			res.Set(i, j, force/f.length()*(res.At(i, j)-d.AtVec(i)*d.AtVec(j)))
+		}
+	}
+	return res
+}
+
+// Load - equivalent nodal forces of the thermal strain
+func (f *FiniteElementTruss) Load() *mat.VecDense {
+	res := mat.NewVecDense(2*f.freedom, nil)
+	res.ScaleVec(f.YoungModulus*f.Area*f.thermalStrain(), f.direction())
+	return res
+}
+
+// FiniteElementBeam - bar of the plane (u, v, rz) or space (u, v, w, rx, ry, rz) frame. The Timoshenko theory is
+// used if the shear coefficient is set, otherwise the Euler-Bernoulli one
+type FiniteElementBeam struct {
+	FE
+	x    *mat.Dense
+	axes *mat.Dense
+}
+
+func NewBeam(x *mat.Dense, params FiniteElementParameters) *FiniteElementBeam {
+	freedom := 6
+	if _, dim := x.Dims(); dim == 2 {
+		freedom = 3
+	}
+	return &FiniteElementBeam{FE: FE{size: 2, freedom: freedom, FiniteElementParameters: params}, x: x,
+		axes: localAxes(x, params.Orientation)}
+}
+
+// planeDof - the degrees of freedom of the plane frame in the local matrices of the space frame
+var planeDof = []int{0, 1, 5, 6, 7, 11}
+
+func (f *FiniteElementBeam) length() float64 {
+	return util.Volume1d2(f.x)
+}
+
+// bending - adding the matrix a of the bending in the plane of the degrees of freedom (w1, r1, w2, r2), the sign
+// of the coupling of the displacements and the rotations is changed for the bending in the x-z plane
+func bending(res *mat.Dense, a [4][4]float64, index [4]int, isXZ bool) {
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			value := a[i][j]
+			if isXZ && i%2 != j%2 {
+				value = -value
+			}
+			res.Set(index[i], index[j], res.At(index[i], index[j])+value)
+		}
+	}
+}
+
+// axial - adding the matrix value * (2 1; 1 2) or value * (1 -1; -1 1) of the degrees of freedom i and j
+func axial(res *mat.Dense, i, j int, diagonal, offDiagonal float64) {
+	res.Set(i, i, res.At(i, i)+diagonal)
+	res.Set(j, j, res.At(j, j)+diagonal)
+	res.Set(i, j, res.At(i, j)+offDiagonal)
+	res.Set(j, i, res.At(j, i)+offDiagonal)
+}
+
+// localStiffness - stiffness matrix of the space frame bar in the local coordinates
+func (f *FiniteElementBeam) localStiffness() *mat.Dense {
+	l := f.length()
+	e := f.YoungModulus
+	g := e / (2.0 + 2.0*f.PoissonRation)
+	res := mat.NewDense(12, 12, nil)
+	axial(res, 0, 6, e*f.Area/l, -e*f.Area/l)
+	axial(res, 3, 9, g*f.TorsionConstant/l, -g*f.TorsionConstant/l)
+	for _, plane := range []struct {
+		inertia float64
+		index   [4]int
+		isXZ    bool
+	}{{f.InertiaZ, [4]int{1, 5, 7, 11}, false}, {f.InertiaY, [4]int{2, 4, 8, 10}, true}} {
+		// Shear deformation parameter
+		phi := 0.0
+		if f.ShearCoefficient > 0 {
+			phi = 12.0 * e * plane.inertia / (f.ShearCoefficient * g * f.Area * l * l)
+		}
+		c := e * plane.inertia / ((1.0 + phi) * l * l * l)
+		bending(res, [4][4]float64{
+			{12.0 * c, 6.0 * l * c, -12.0 * c, 6.0 * l * c},
+			{6.0 * l * c, (4.0 + phi) * l * l * c, -6.0 * l * c, (2.0 - phi) * l * l * c},
+			{-12.0 * c, -6.0 * l * c, 12.0 * c, -6.0 * l * c},
+			{6.0 * l * c, (2.0 - phi) * l * l * c, -6.0 * l * c, (4.0 + phi) * l * l * c},
+		}, plane.index, plane.isXZ)
+	}
+	return res
+}
+
+// transform - the matrix of transformation from the global coordinates to the local ones
+func (f *FiniteElementBeam) transform() *mat.Dense {
+	return util.ExtTransformMatrix(f.axes, 12)
+}
+
+// toGlobal - the local matrix of the space frame bar in the global coordinates, it is reduced to the degrees of
+// freedom of the plane frame
+func (f *FiniteElementBeam) toGlobal(local *mat.Dense) *mat.Dense {
+	t := f.transform()
+	res := util.Mul(util.Mul(t.T(), local), t)
+	if f.freedom == 6 {
+		return res
+	}
+	plane := mat.NewDense(6, 6, nil)
+	for i := range planeDof {
+		for j := range planeDof {
+			plane.Set(i, j, res.At(planeDof[i], planeDof[j]))
+		}
+	}
+	return plane
+}
+
+// thermalForce - the axial force of the restrained thermal expansion for the mean temperature of the nodes
+func (f *FiniteElementBeam) thermalForce() float64 {
+	return 0.5 * f.YoungModulus * f.Area * f.ThermalExpansion * (f.nodeTemperature(0) + f.nodeTemperature(1))
+}
+
+func (f *FiniteElementBeam) Create() *mat.Dense {
+	return f.toGlobal(f.localStiffness())
+}
+
+// localForces - forces acting on the bar at its nodes in the local coordinates
+func (f *FiniteElementBeam) localForces(u *mat.VecDense) *mat.VecDense {
+	global := u
+	if f.freedom == 3 {
+		global = mat.NewVecDense(12, nil)
+		for i := range planeDof {
+			global.SetVec(planeDof[i], u.AtVec(i))
+		}
+	}
+	var local, res mat.VecDense
+	local.MulVec(f.transform(), global)
+	res.MulVec(f.localStiffness(), &local)
+	// The thermal load is excluded
+	res.SetVec(0, res.AtVec(0)+f.thermalForce())
+	res.SetVec(6, res.AtVec(6)-f.thermalForce())
+	return &res
+}
+
+// Calculate - the internal forces (rows): N, Qy, Mz for the plane frame and N, Qy, Qz, Mx, My, Mz for the space one.
+// The forces are the ones acting on the section with the outer normal along the local x
+func (f *FiniteElementBeam) Calculate(u *mat.VecDense) *mat.Dense {
+	forces := f.localForces(u)
+	index := []int{0, 1, 2, 3, 4, 5}
+	if f.freedom == 3 {
+		index = []int{0, 1, 5}
+	}
+	res := mat.NewDense(len(index), 2, nil)
+	for i, k := range index {
+		res.Set(i, 0, -forces.AtVec(k))
+		res.Set(i, 1, forces.AtVec(6+k))
+	}
+	return res
+}
+
+// Mass - consistent (or HRZ lumped) mass matrix, the rotary inertia of the sections is neglected except the torsion
+func (f *FiniteElementBeam) Mass(lumped bool) *mat.Dense {
+	l := f.length()
+	m := f.Density * f.Area * l
+	res := mat.NewDense(12, 12, nil)
+	axial(res, 0, 6, m/3.0, m/6.0)
+	torsion := f.Density * (f.InertiaY + f.InertiaZ) * l
+	axial(res, 3, 9, torsion/3.0, torsion/6.0)
+	c := m / 420.0
+	for _, plane := range []struct {
+		index [4]int
+		isXZ  bool
+	}{{[4]int{1, 5, 7, 11}, false}, {[4]int{2, 4, 8, 10}, true}} {
+		bending(res, [4][4]float64{
+			{156.0 * c, 22.0 * l * c, 54.0 * c, -13.0 * l * c},
+			{22.0 * l * c, 4.0 * l * l * c, 13.0 * l * c, -3.0 * l * l * c},
+			{54.0 * c, 13.0 * l * c, 156.0 * c, -22.0 * l * c},
+			{-13.0 * l * c, -3.0 * l * l * c, -22.0 * l * c, 4.0 * l * l * c},
+		}, plane.index, plane.isXZ)
+	}
+	if lumped {
+		res = lumpMass(res, 6)
+	}
+	return f.toGlobal(res)
+}
+
+// Geometric - geometric stiffness matrix of the bar with the axial force
+func (f *FiniteElementBeam) Geometric(u *mat.VecDense) *mat.Dense {
+	l := f.length()
+	c := -f.localForces(u).AtVec(0) / (30.0 * l)
+	res := mat.NewDense(12, 12, nil)
+	for _, plane := range []struct {
+		index [4]int
+		isXZ  bool
+	}{{[4]int{1, 5, 7, 11}, false}, {[4]int{2, 4, 8, 10}, true}} {
+		bending(res, [4][4]float64{
+			{36.0 * c, 3.0 * l * c, -36.0 * c, 3.0 * l * c},
+			{3.0 * l * c, 4.0 * l * l * c, -3.0 * l * c, -l * l * c},
+			{-36.0 * c, -3.0 * l * c, 36.0 * c, -3.0 * l * c},
+			{3.0 * l * c, -l * l * c, -3.0 * l * c, 4.0 * l * l * c},
+		}, plane.index, plane.isXZ)
+	}
+	return f.toGlobal(res)
+}
+
+// Load - equivalent nodal forces of the thermal strain
+func (f *FiniteElementBeam) Load() *mat.VecDense {
+	local := mat.NewVecDense(12, nil)
+	local.SetVec(0, -f.thermalForce())
+	local.SetVec(6, f.thermalForce())
+	var global mat.VecDense
+	global.MulVec(f.transform().T(), local)
+	if f.freedom == 6 {
+		return &global
+	}
+	res := mat.NewVecDense(6, nil)
+	for i := range planeDof {
+		res.SetVec(i, global.AtVec(planeDof[i]))
+	}
+	return res
+}
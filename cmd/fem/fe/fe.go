@@ -1,20 +1,104 @@
 package fe
 
 import (
+	"fmt"
 	"gonum.org/v1/gonum/mat"
 	"math"
 	"wfem/cmd/fem/util"
 )
 
+// Analysis mode of the two-dimensional problem
+const (
+	PlaneStress int = iota
+	PlaneStrain
+	Axisymmetric
+)
+
+var modes2D = map[string]int{"plane_stress": PlaneStress, "plane_strain": PlaneStrain, "axisymmetric": Axisymmetric}
+
+// Mode2DByName - analysis mode of the 2D problem by its name ("plane_stress", "plane_strain" or "axisymmetric")
+func Mode2DByName(name string) (int, error) {
+	if mode, ok := modes2D[name]; ok {
+		return mode, nil
+	}
+	return 0, fmt.Errorf("unknown analysis mode: %s", name)
+}
+
+// Formulation of the shell elements: the Mindlin shell with the full integration of the transverse shear, the
+// discrete Kirchhoff triangle or the quadrangle with the assumed transverse shear strains
+const (
+	Mindlin int = iota
+	DKT
+	MITC4
+)
+
+var shellFormulations = map[string]int{"mindlin": Mindlin, "dkt": DKT, "mitc4": MITC4}
+
+// ShellFormulationByName - formulation of the shell elements by its name ("mindlin", "dkt" or "mitc4")
+func ShellFormulationByName(name string) (int, error) {
+	if formulation, ok := shellFormulations[name]; ok {
+		return formulation, nil
+	}
+	return 0, fmt.Errorf("unknown shell formulation: %s", name)
+}
+
+// Integration rule of the stiffness matrix of the 2D and solid elements: full, reduced (with the hourglass control
+// of the linear quadrangles and hexahedra) or selective (the volumetric part is integrated by the reduced rule)
+const (
+	FullIntegration int = iota
+	ReducedIntegration
+	SelectiveIntegration
+)
+
+var integrations = map[string]int{"full": FullIntegration, "reduced": ReducedIntegration, "selective": SelectiveIntegration}
+
+// IntegrationByName - integration rule of the elements by its name ("full", "reduced" or "selective")
+func IntegrationByName(name string) (int, error) {
+	if integration, ok := integrations[name]; ok {
+		return integration, nil
+	}
+	return 0, fmt.Errorf("unknown integration rule: %s", name)
+}
+
 type FiniteElementParameters struct {
 	YoungModulus  float64
 	PoissonRation float64
 	Thickness     float64
+	Density       float64
+	// Coefficient of the thermal expansion and the temperatures of the nodes (nil - no thermal strain)
+	ThermalExpansion float64
+	Temperature      []float64
+	// Analysis mode of the 2D element (PlaneStress, PlaneStrain or Axisymmetric about the y axis)
+	Mode int
+	// Cross-section of the truss and frame bars: the moments of inertia about the local y and z axes, the shear
+	// coefficient of the Timoshenko beam (zero - Euler-Bernoulli) and the vector in the local x-y plane
+	Area             float64
+	InertiaY         float64
+	InertiaZ         float64
+	TorsionConstant  float64
+	ShearCoefficient float64
+	Orientation      [3]float64
+	// Material of the 2D, solid and shell elements: the orthotropic constants (zero E1 - isotropic material) or the
+	// general 6x6 elastic matrix (nil - not used) in the material axes, which are set by the axis 1 and the vector
+	// in the 1-2 plane (global x and y by default)
+	Orthotropic   OrthotropicMaterial
+	Anisotropic   *mat.Dense
+	MaterialAxis  [3]float64
+	MaterialPlane [3]float64
+	// Plies of the shell laminate from its bottom face (nil - homogeneous shell), the thickness is their total one
+	Laminate []Ply
+	// Formulation of the shell (Mindlin, DKT for the triangles or MITC4 for the quadrangles)
+	Formulation int
+	// Integration rule of the stiffness matrix (FullIntegration, ReducedIntegration or SelectiveIntegration)
+	Integration int
 }
 
 type FiniteElement interface {
 	Calculate(*mat.VecDense) *mat.Dense
 	Create() *mat.Dense
+	Mass(bool) *mat.Dense
+	Geometric(*mat.VecDense) *mat.Dense
+	Load() *mat.VecDense
 }
 
 type FE struct {
@@ -52,18 +136,14 @@ func (f *FiniteElement1D) Create() *mat.Dense {
 }
 
 func (f *FiniteElement1D) Calculate(u *mat.VecDense) *mat.Dense {
-	res := mat.NewDense(2, 2, nil)
-	B := mat.NewDense(1, f.size, nil)
+	res := mat.NewDense(2, f.size, nil)
 	for i := 0; i < f.size; i++ {
+		strain := 0.0
 		for j := 0; j < f.size; j++ {
-			B.Set(0, f.freedom*j, f.ShapeDx(i, j))
-			strain := mat.NewDense(1, 1, nil)
-			stress := mat.NewDense(1, 1, nil)
-			strain.Mul(B, u)
-			stress.Scale(f.YoungModulus, strain)
-			res.Set(0, i, res.At(0, i)+strain.At(0, 0))
-			res.Set(1, i, res.At(1, i)+strain.At(0, 0))
+			strain += f.ShapeDx(i, j) * u.AtVec(f.freedom*j)
 		}
+		res.Set(0, i, strain)
+		res.Set(1, i, f.YoungModulus*(strain-f.initialStrain(f.nodeTemperature(i), 1, 1).AtVec(0)))
 	}
 	return res
 }
@@ -78,36 +158,102 @@ func NewFE2D(shape ShapeFunction2D, params FiniteElementParameters) *FiniteEleme
 }
 
 func (f *FiniteElement2D) Create() *mat.Dense {
-	var invJacobi mat.Dense
+	if f.Integration == FullIntegration {
+		return f.stiffness(f.elasticMatrix())
+	}
+	shape, corners := reducedShape(f.ShapeFunction2D)
+	reduced := *f
+	reduced.ShapeFunction2D = shape.(ShapeFunction2D)
+	if f.Integration == SelectiveIntegration {
+		m := mat.NewVecDense(f.strainSize(), []float64{1.0, 1.0, 0.0, 1.0}[:f.strainSize()])
+		deviatoric, volumetric := volumetricSplit(f.elasticMatrix(), m)
+		return util.Add(f.stiffness(deviatoric), reduced.stiffness(volumetric))
+	}
+	res := reduced.stiffness(f.elasticMatrix())
+	if corners != nil {
+		_, jacobian := shapeGradient2D(reduced.ShapeFunction2D, 0)
+		volume := (*reduced.W())[0] * reduced.thickness(0) * math.Abs(jacobian)
+		d := f.elasticMatrix()
+		res.Add(res, hourglass(f.X(), corners, 2, f.freedom, volume, uniaxialModulus(d, 2), d.At(2, 2)))
+	}
+	return res
+}
+
+// stiffness - stiffness matrix with the elastic matrix d integrated at the points of the shape functions
+func (f *FiniteElement2D) stiffness(d *mat.Dense) *mat.Dense {
 	res := mat.NewDense(f.size*f.freedom, f.size*f.freedom, nil)
 	// Numerical integration according to the Gauss formula on the interval [-0.5; 0.5]
 	for i := 0; i < len(*f.W()); i++ {
-		jacobi := mat.NewDense(2, 2, nil)
-		for j := 0; j < 2; j++ {
-			for k := 0; k < f.size; k++ {
-				jacobi.Set(0, j, jacobi.At(0, j)+f.ShapeDxi(i, k)*f.X().At(k, j))
-				jacobi.Set(1, j, jacobi.At(1, j)+f.ShapeDeta(i, k)*f.X().At(k, j))
-			}
-		}
-		// Jacobian and inverted Jacobi matrix
-		jacobian := mat.Det(jacobi)
-		_ = invJacobi.Inverse(jacobi)
-		// Gradient Matrix
-		B := mat.NewDense(3, f.size*f.freedom, nil)
-		for j := 0; j < f.size; j++ {
-			B.Set(0, f.freedom*j+0, invJacobi.At(0, 0)*f.ShapeDxi(i, j)+invJacobi.At(0, 1)*f.ShapeDeta(i, j))
-			B.Set(2, f.freedom*j+1, B.At(0, f.freedom*j+0))
-			B.Set(1, f.freedom*j+1, invJacobi.At(1, 0)*f.ShapeDxi(i, j)+invJacobi.At(1, 1)*f.ShapeDeta(i, j))
-			B.Set(2, f.freedom*j+0, B.At(1, f.freedom*j+1))
-		}
+		dn, jacobian := shapeGradient2D(f.ShapeFunction2D, i)
+		B := f.gradientMatrix(dn, i)
 		// Calculation of the local stiffness matrix
-		K := util.Scale((*f.W())[i]*f.Thickness*math.Abs(jacobian), util.Mul(util.Mul(B.T(), f.elasticMatrix()), B))
+		K := util.Scale((*f.W())[i]*f.thickness(i)*math.Abs(jacobian), util.Mul(util.Mul(B.T(), d), B))
 		res.Add(res, K)
 	}
 	return res
 }
 
+// strainSize - number of the strain components: xx, yy, xy and the hoop strain tt in the axisymmetric problem
+func (f *FiniteElement2D) strainSize() int {
+	if f.Mode == Axisymmetric {
+		return 4
+	}
+	return 3
+}
+
+// radius - distance from the axis of symmetry (y) to the i-th integration point
+func (f *FiniteElement2D) radius(i int) float64 {
+	var res float64
+	for j := 0; j < f.size; j++ {
+		res += f.Shape(i, j) * f.X().At(j, 0)
+	}
+	return res
+}
+
+// thickness - the thickness at the i-th integration point, the axisymmetric problem is solved per radian
+func (f *FiniteElement2D) thickness(i int) float64 {
+	if f.Mode == Axisymmetric {
+		return f.radius(i)
+	}
+	return f.Thickness
+}
+
+// gradientMatrix - gradient matrix at the i-th integration point, the hoop strain is u / r
+func (f *FiniteElement2D) gradientMatrix(dn *mat.Dense, i int) *mat.Dense {
+	res := strainMatrix(dn, f.freedom)
+	if f.Mode != Axisymmetric {
+		return res
+	}
+	r := f.radius(i)
+	axisymmetric := mat.NewDense(4, f.size*f.freedom, nil)
+	axisymmetric.Slice(0, 3, 0, f.size*f.freedom).(*mat.Dense).Copy(res)
+	for j := 0; j < f.size; j++ {
+		axisymmetric.Set(3, f.freedom*j, f.Shape(i, j)/r)
+	}
+	return axisymmetric
+}
+
 func (f *FiniteElement2D) elasticMatrix() *mat.Dense {
+	if f.isAnisotropic() {
+		return f.anisotropicMatrix()
+	}
+	e := f.YoungModulus
+	m := f.PoissonRation
+	switch f.Mode {
+	case PlaneStrain:
+		return mat.NewDense(3, 3, []float64{
+			e * (1.0 - m) / (1.0 + m) / (1.0 - 2.0*m), e * m / (1.0 + m) / (1.0 - 2.0*m), 0.0,
+			e * m / (1.0 + m) / (1.0 - 2.0*m), e * (1.0 - m) / (1.0 + m) / (1.0 - 2.0*m), 0.0,
+			0.0, 0.0, 0.5 * e / (1.0 + m),
+		})
+	case Axisymmetric:
+		return mat.NewDense(4, 4, []float64{
+			e * (1.0 - m) / (1.0 + m) / (1.0 - 2.0*m), e * m / (1.0 + m) / (1.0 - 2.0*m), 0.0, e * m / (1.0 + m) / (1.0 - 2.0*m),
+			e * m / (1.0 + m) / (1.0 - 2.0*m), e * (1.0 - m) / (1.0 + m) / (1.0 - 2.0*m), 0.0, e * m / (1.0 + m) / (1.0 - 2.0*m),
+			0.0, 0.0, 0.5 * e / (1.0 + m), 0.0,
+			e * m / (1.0 + m) / (1.0 - 2.0*m), e * m / (1.0 + m) / (1.0 - 2.0*m), 0.0, e * (1.0 - m) / (1.0 + m) / (1.0 - 2.0*m),
+		})
+	}
 	return mat.NewDense(3, 3, []float64{
 		f.YoungModulus / (1.0 - f.PoissonRation*f.PoissonRation), f.PoissonRation * f.YoungModulus / (1.0 - f.PoissonRation*f.PoissonRation), 0.0,
 		f.PoissonRation * f.YoungModulus / (1.0 - f.PoissonRation*f.PoissonRation), f.YoungModulus / (1.0 - f.PoissonRation*f.PoissonRation), 0.0,
@@ -116,8 +262,9 @@ func (f *FiniteElement2D) elasticMatrix() *mat.Dense {
 }
 
 func (f *FiniteElement2D) Calculate(u *mat.VecDense) *mat.Dense {
-	res := mat.NewDense(8, f.size, nil)
-	B := mat.NewDense(3, f.size*f.freedom, nil)
+	size := f.strainSize()
+	res := mat.NewDense(2*size, f.size, nil)
+	B := mat.NewDense(size, f.size*f.freedom, nil)
 	var stress mat.Dense
 	var strain mat.Dense
 	for i := 0; i < f.size; i++ {
@@ -126,12 +273,24 @@ func (f *FiniteElement2D) Calculate(u *mat.VecDense) *mat.Dense {
 			B.Set(2, f.freedom*j+1, B.At(0, f.freedom*j+0))
 			B.Set(1, f.freedom*j+1, f.ShapeDy(i, j))
 			B.Set(2, f.freedom*j+0, B.At(1, f.freedom*j+1))
+			if f.Mode == Axisymmetric {
+				// On the axis the hoop strain is equal to the radial one
+				if r := f.X().At(i, 0); math.Abs(r) > 0 {
+					B.Set(3, f.freedom*j, 0.0)
+					if i == j {
+						B.Set(3, f.freedom*j, 1.0/r)
+					}
+				} else {
+					B.Set(3, f.freedom*j, B.At(0, f.freedom*j))
+				}
+			}
 		}
 		strain.Mul(B, u)
-		stress.Mul(f.elasticMatrix(), &strain)
-		for j := 0; j < 3; j++ {
+		stress.Sub(&strain, f.thermalStrain(f.nodeTemperature(i)))
+		stress.Mul(f.elasticMatrix(), &stress)
+		for j := 0; j < size; j++ {
 			res.Set(j, i, res.At(j, i)+strain.At(j, 0))
-			res.Set(j+3, i, res.At(j+3, i)+stress.At(j, 0))
+			res.Set(j+size, i, res.At(j+size, i)+stress.At(j, 0))
 		}
 	}
 	return res
@@ -147,6 +306,9 @@ func NewFE3D(shape ShapeFunction3D, params FiniteElementParameters) *FiniteEleme
 }
 
 func (f *FiniteElement3D) elasticMatrix() *mat.Dense {
+	if f.isAnisotropic() {
+		return f.materialMatrix(nil, nil)
+	}
 	e := f.YoungModulus
 	m := f.PoissonRation
 	return mat.NewDense(6, 6, []float64{
@@ -160,36 +322,49 @@ func (f *FiniteElement3D) elasticMatrix() *mat.Dense {
 }
 
 func (f *FiniteElement3D) Create() *mat.Dense {
-	var invJacobi mat.Dense
+	if f.Integration == FullIntegration {
+		return f.stiffness(f.elasticMatrix())
+	}
+	shape, corners := reducedShape(f.ShapeFunction3D)
+	reduced := *f
+	reduced.ShapeFunction3D = shape.(ShapeFunction3D)
+	if f.Integration == SelectiveIntegration {
+		deviatoric, volumetric := volumetricSplit(f.elasticMatrix(), mat.NewVecDense(6, []float64{1.0, 1.0, 1.0, 0.0, 0.0, 0.0}))
+		return util.Add(f.stiffness(deviatoric), reduced.stiffness(volumetric))
+	}
+	res := reduced.stiffness(f.elasticMatrix())
+	if corners != nil {
+		_, jacobian := shapeGradient3D(reduced.ShapeFunction3D, 0)
+		volume := (*reduced.W())[0] * math.Abs(jacobian)
+		d := f.elasticMatrix()
+		shear := (d.At(3, 3) + d.At(4, 4) + d.At(5, 5)) / 3.0
+		res.Add(res, hourglass(f.X(), corners, 3, f.freedom, volume, uniaxialModulus(d, 3), shear))
+	}
+	return res
+}
+
+// stiffness - stiffness matrix with the elastic matrix d integrated at the points of the shape functions
+func (f *FiniteElement3D) stiffness(d *mat.Dense) *mat.Dense {
 	res := mat.NewDense(f.size*f.freedom, f.size*f.freedom, nil)
 	// Numerical integration according to the Gauss formula
 	for i := 0; i < len(*f.W()); i++ {
-		jacobi := mat.NewDense(3, 3, nil)
-		for j := 0; j < 3; j++ {
-			for k := 0; k < f.size; k++ {
-				jacobi.Set(0, j, jacobi.At(0, j)+f.ShapeDxi(i, k)*f.X().At(k, j))
-				jacobi.Set(1, j, jacobi.At(1, j)+f.ShapeDeta(i, k)*f.X().At(k, j))
-				jacobi.Set(2, j, jacobi.At(2, j)+f.ShapeDpsi(i, k)*f.X().At(k, j))
-			}
-		}
-		// Jacobian and inverted Jacobi matrix
-		jacobian := mat.Det(jacobi)
-		_ = invJacobi.Inverse(jacobi)
+		// Derivatives of the shape functions and Jacobian
+		dn, jacobian := shapeGradient3D(f.ShapeFunction3D, i)
 		// Gradient Matrix
 		B := mat.NewDense(6, f.size*f.freedom, nil)
 		for j := 0; j < f.size; j++ {
-			B.Set(0, f.freedom*j+0, invJacobi.At(0, 0)*f.ShapeDxi(i, j)+invJacobi.At(0, 1)*f.ShapeDeta(i, j)+invJacobi.At(0, 2)*f.ShapeDpsi(i, j))
+			B.Set(0, f.freedom*j+0, dn.At(0, j))
 			B.Set(3, f.freedom*j+1, B.At(0, f.freedom*j+0))
 			B.Set(5, f.freedom*j+2, B.At(0, f.freedom*j+0))
-			B.Set(1, f.freedom*j+1, invJacobi.At(1, 0)*f.ShapeDxi(i, j)+invJacobi.At(1, 1)*f.ShapeDeta(i, j)+invJacobi.At(1, 2)*f.ShapeDpsi(i, j))
+			B.Set(1, f.freedom*j+1, dn.At(1, j))
 			B.Set(3, f.freedom*j+0, B.At(1, f.freedom*j+1))
 			B.Set(4, f.freedom*j+2, B.At(1, f.freedom*j+1))
-			B.Set(2, f.freedom*j+2, invJacobi.At(2, 0)*f.ShapeDxi(i, j)+invJacobi.At(2, 1)*f.ShapeDeta(i, j)+invJacobi.At(2, 2)*f.ShapeDpsi(i, j))
+			B.Set(2, f.freedom*j+2, dn.At(2, j))
 			B.Set(4, f.freedom*j+1, B.At(2, f.freedom*j+2))
 			B.Set(5, f.freedom*j+0, B.At(2, f.freedom*j+2))
 		}
 		// Calculation of the local stiffness matrix
-		K := util.Scale((*f.W())[i]*math.Abs(jacobian), util.Mul(util.Mul(B.T(), f.elasticMatrix()), B))
+		K := util.Scale((*f.W())[i]*math.Abs(jacobian), util.Mul(util.Mul(B.T(), d), B))
 		res.Add(res, K)
 	}
 	return res
@@ -213,7 +388,8 @@ func (f *FiniteElement3D) Calculate(u *mat.VecDense) *mat.Dense {
 			B.Set(5, f.freedom*j+0, B.At(2, f.freedom*j+2))
 		}
 		strain.Mul(B, u)
-		stress.Mul(f.elasticMatrix(), &strain)
+		stress.Sub(&strain, f.initialStrain(f.nodeTemperature(i), 6, 3))
+		stress.Mul(f.elasticMatrix(), &stress)
 		for j := 0; j < 6; j++ {
 			res.Set(j, i, res.At(j, i)+strain.At(j, 0))
 			res.Set(j+6, i, res.At(j+6, i)+stress.At(j, 0))
@@ -222,6 +398,19 @@ func (f *FiniteElement3D) Calculate(u *mat.VecDense) *mat.Dense {
 	return res
 }
 
+// Hughes-Brezzi penalty of the drilling rotation of the shells relative to the membrane shear stiffness G t, Hughes and
+// Brezzi recommend the shear modulus
+const drillingPenalty = 1.0
+
+// DrillingStabilization - stiffness of the deviation of the drilling rotation minus the membrane rotation from its
+// value at the center of the shell element relative to the penalty. The one-point penalty leaves the other modes of
+// the drilling rotation free, like the hourglass modes of the reduced integration, and the stabilization makes them
+// stable without acting on the linear fields. The deflections change by less than 1% between 1e-2 and 1 and the change
+// vanishes under the refinement, the smaller values let the nodal rotations of the quadrangles drift from the membrane
+// rotation, the larger ones stiffen the coarse distorted membranes. The value keeps Cook's membrane of 2 x 2 shells
+// within 0.5% of the plane elements (see TestFoldedPlate and TestCookMembrane)
+var DrillingStabilization = 0.05
+
 type FiniteElement3DS struct {
 	transformMatrix *mat.Dense
 	FiniteElement2D
@@ -235,60 +424,87 @@ func NewFE3DS(shape ShapeFunction2D, transformMatrix *mat.Dense, params FiniteEl
 	}
 }
 
+// extraElasticMatrix - transverse shear elastic matrix (xz, yz) in the local axes, for the laminate it is averaged
+// over the thickness
 func (f *FiniteElement3DS) extraElasticMatrix() *mat.Dense {
+	if f.Laminate != nil {
+		_, _, _, s := f.laminateMatrix()
+		s.Scale(6.0/5.0/f.Thickness, s)
+		return s
+	}
+	if f.isAnisotropic() {
+		return subMatrix(f.materialMatrix(f.transformMatrix, f.transformMatrix.RawRowView(2)), 5, 4)
+	}
 	return mat.NewDense(2, 2, []float64{f.YoungModulus / (2.0 + 2.0*f.PoissonRation), 0.0, 0.0, f.YoungModulus / (2.0 + 2.0*f.PoissonRation)})
 }
 
 func (f *FiniteElement3DS) Create() *mat.Dense {
-	var invJacobi mat.Dense
+	var a, b, d, s *mat.Dense
 	res := mat.NewDense(f.size*f.freedom, f.size*f.freedom, nil)
+	if f.Laminate != nil {
+		a, b, d, s = f.laminateMatrix()
+	} else {
+		s = util.Scale(f.Thickness*5.0/6.0, f.extraElasticMatrix())
+	}
+	// Penalty factor of the drilling rotation
+	drilling := drillingPenalty * f.Thickness * f.elasticMatrix().At(2, 2)
 	// Numerical integration according to the Gauss formula
 	for i := 0; i < len(*f.W()); i++ {
-		// Jacobian and inverted Jacobi matrix
-		jacobi := mat.NewDense(2, 2, nil)
-		for j := 0; j < 2; j++ {
-			for k := 0; k < f.size; k++ {
-				jacobi.Set(0, j, jacobi.At(0, j)+f.ShapeDxi(i, k)*f.X().At(k, j))
-				jacobi.Set(1, j, jacobi.At(1, j)+f.ShapeDeta(i, k)*f.X().At(k, j))
-			}
-		}
-		jacobian := mat.Det(jacobi)
-		_ = invJacobi.Inverse(jacobi)
+		// Derivatives of the shape functions in the local axes and Jacobian
+		dn, jacobian := shapeGradient2D(f.ShapeFunction2D, i)
 		// Gradient Matrix
 		bm := mat.NewDense(3, f.size*f.freedom, nil)
 		bp := mat.NewDense(3, f.size*f.freedom, nil)
 		bc := mat.NewDense(2, f.size*f.freedom, nil)
+		// The rotations of the normal are expressed by the rotations about the local axes: bx = ty, by = -tx
 		for j := 0; j < f.size; j++ {
-			bm.Set(0, f.freedom*j+0, invJacobi.At(0, 0)*f.ShapeDxi(i, j)+invJacobi.At(0, 1)*f.ShapeDeta(i, j))
+			bm.Set(0, f.freedom*j+0, dn.At(0, j))
 			bm.Set(2, f.freedom*j+1, bm.At(0, f.freedom*j+0))
-			bp.Set(0, f.freedom*j+3, bm.At(0, f.freedom*j+0))
-			bp.Set(2, f.freedom*j+4, bm.At(0, f.freedom*j+0))
+			bp.Set(0, f.freedom*j+4, bm.At(0, f.freedom*j+0))
+			bp.Set(2, f.freedom*j+3, -bm.At(0, f.freedom*j+0))
 			bc.Set(0, f.freedom*j+2, bm.At(0, f.freedom*j+0))
-			bm.Set(1, f.freedom*j+1, invJacobi.At(1, 0)*f.ShapeDxi(i, j)+invJacobi.At(1, 1)*f.ShapeDeta(i, j))
+			bm.Set(1, f.freedom*j+1, dn.At(1, j))
 			bm.Set(2, f.freedom*j+0, bm.At(1, f.freedom*j+1))
-			bp.Set(1, f.freedom*j+4, bm.At(1, f.freedom*j+1))
-			bp.Set(2, f.freedom*j+3, bm.At(1, f.freedom*j+1))
+			bp.Set(1, f.freedom*j+3, -bm.At(1, f.freedom*j+1))
+			bp.Set(2, f.freedom*j+4, bm.At(1, f.freedom*j+1))
 			bc.Set(1, f.freedom*j+2, bm.At(1, f.freedom*j+1))
-			bc.Set(0, f.freedom*j+3, f.Shape(i, j))
-			bc.Set(1, f.freedom*j+4, bc.At(0, f.freedom*j+3))
+			bc.Set(0, f.freedom*j+4, f.Shape(i, j))
+			bc.Set(1, f.freedom*j+3, -f.Shape(i, j))
 		}
+		bp, bc = f.lockingFree(shapeValues(f.ShapeFunction2D, i), bp, bc)
 		// Calculation of the local stiffness matrix
-		K := util.Add(util.Add(util.Scale(f.Thickness, util.Mul(util.Mul(bm.T(), f.elasticMatrix()), bm)),
-			util.Scale(math.Pow(f.Thickness, 3)/12.0, util.Mul(util.Mul(bp.T(), f.elasticMatrix()), bp))),
-			util.Scale(f.Thickness*5.0/6.0, util.Mul(util.Mul(bc.T(), f.extraElasticMatrix()), bc)))
+		var K *mat.Dense
+		if f.Laminate != nil {
+			K = util.Add(util.Mul(util.Mul(bm.T(), a), bm), util.Mul(util.Mul(bp.T(), d), bp))
+			coupling := util.Mul(util.Mul(bm.T(), b), bp)
+			K = util.Add(util.Add(K, coupling), coupling.T())
+		} else {
+			K = util.Add(util.Scale(f.Thickness, util.Mul(util.Mul(bm.T(), f.elasticMatrix()), bm)),
+				util.Scale(math.Pow(f.Thickness, 3)/12.0, util.Mul(util.Mul(bp.T(), f.elasticMatrix()), bp)))
+		}
+		K = util.Add(K, util.Mul(util.Mul(bc.T(), s), bc))
 		res.Add(res, util.Scale((*f.W())[i]*math.Abs(jacobian), K))
 	}
-	// Finding the maximum diagonal element
-	singular := 0.0
-	for i := 0; i < f.size*f.freedom; i++ {
-		if res.At(i, i) > singular {
-			singular = res.At(i, i)
-		}
+	// Hughes-Brezzi: the drilling rotation tz is tied to the in-plane rotation (v,x - u,y) / 2 at the center of the
+	// element only, so the membrane does not lock, the other modes are restrained by the stabilization of the deviation
+	// of tz - (v,x - u,y) / 2 from its center value
+	n, dn, area := centerShape2D(f.ShapeFunction2D)
+	bd := mat.NewDense(1, f.size*f.freedom, nil)
+	for j := 0; j < f.size; j++ {
+		bd.Set(0, f.freedom*j+0, -0.5*dn.At(1, j))
+		bd.Set(0, f.freedom*j+1, 0.5*dn.At(0, j))
+		bd.Set(0, f.freedom*j+5, -n[j])
 	}
-	singular *= 1.0e-3
-	// Removing the singularity
-	for i := 0; i < f.size; i++ {
-		res.Set(f.freedom*(i+1)-1, f.freedom*(i+1)-1, singular)
+	res.Add(res, util.Scale(drilling*area, util.Mul(bd.T(), bd)))
+	for i := 0; i < len(*f.W()); i++ {
+		dni, jacobian := shapeGradient2D(f.ShapeFunction2D, i)
+		bs := mat.NewDense(1, f.size*f.freedom, nil)
+		for j := 0; j < f.size; j++ {
+			bs.Set(0, f.freedom*j+0, -0.5*(dni.At(1, j)-dn.At(1, j)))
+			bs.Set(0, f.freedom*j+1, 0.5*(dni.At(0, j)-dn.At(0, j)))
+			bs.Set(0, f.freedom*j+5, -(f.Shape(i, j) - n[j]))
+		}
+		res.Add(res, util.Scale(DrillingStabilization*drilling*(*f.W())[i]*math.Abs(jacobian), util.Mul(bs.T(), bs)))
 	}
 	// Convert from local to global coordinates
 	m := util.ExtTransformMatrix(f.transformMatrix, f.size*f.freedom)
@@ -296,8 +512,10 @@ func (f *FiniteElement3DS) Create() *mat.Dense {
 	return res
 }
 
+// Calculate - strains and stresses on the top face of the shell in the global axes followed by the stresses and
+// failure indices of the plies of the laminate
 func (f *FiniteElement3DS) Calculate(u *mat.VecDense) *mat.Dense {
-	res := mat.NewDense(18, f.size, nil)
+	res := mat.NewDense(12+5*len(f.Laminate), f.size, nil)
 	lu := util.Mul(util.ExtTransformMatrix(f.transformMatrix, f.size*f.freedom), u)
 	index := [6][2]int{{0, 0}, {1, 1}, {2, 2}, {0, 1}, {0, 2}, {1, 2}}
 	for i := 0; i < f.size; i++ {
@@ -307,25 +525,40 @@ func (f *FiniteElement3DS) Calculate(u *mat.VecDense) *mat.Dense {
 		for j := 0; j < f.size; j++ {
 			bm.Set(0, f.freedom*j+0, f.ShapeDx(i, j))
 			bm.Set(2, f.freedom*j+1, bm.At(0, f.freedom*j+0))
-			bp.Set(0, f.freedom*j+3, bm.At(0, f.freedom*j+0))
-			bp.Set(2, f.freedom*j+4, bm.At(0, f.freedom*j+0))
+			bp.Set(0, f.freedom*j+4, bm.At(0, f.freedom*j+0))
+			bp.Set(2, f.freedom*j+3, -bm.At(0, f.freedom*j+0))
 			bc.Set(0, f.freedom*j+2, bm.At(0, f.freedom*j+0))
 			bm.Set(1, f.freedom*j+1, f.ShapeDy(i, j))
 			bm.Set(2, f.freedom*j+0, bm.At(1, f.freedom*j+1))
-			bp.Set(1, f.freedom*j+4, bm.At(1, f.freedom*j+1))
-			bp.Set(2, f.freedom*j+3, bm.At(1, f.freedom*j+1))
+			bp.Set(1, f.freedom*j+3, -bm.At(1, f.freedom*j+1))
+			bp.Set(2, f.freedom*j+4, bm.At(1, f.freedom*j+1))
 			bc.Set(1, f.freedom*j+2, bm.At(1, f.freedom*j+1))
 			if i == j {
-				bc.Set(0, f.freedom*j+3, 1.0)
-				bc.Set(1, f.freedom*j+4, 1.0)
+				bc.Set(0, f.freedom*j+4, 1.0)
+				bc.Set(1, f.freedom*j+3, -1.0)
 			}
 		}
+		node := make([]float64, f.size)
+		node[i] = 1.0
+		bp, bc = f.lockingFree(node, bp, bc)
 		strainM := util.Mul(bm, lu)
 		strainP := util.Mul(bp, lu)
 		strainC := util.Mul(bc, lu)
-		stressM := util.Mul(f.elasticMatrix(), strainM)
+		e0 := f.initialStrain(f.nodeTemperature(i), 3, 2)
+		stressM := util.Mul(f.elasticMatrix(), util.Sub(strainM, e0))
 		stressP := util.Scale(f.Thickness*0.5, util.Mul(f.elasticMatrix(), strainP))
 		stressC := util.Mul(f.extraElasticMatrix(), strainC)
+		if f.Laminate != nil {
+			// The stresses on the top face are those of the last ply
+			stressM = util.Mul(subMatrix(f.plyMatrix(len(f.Laminate)-1), 0, 1, 3),
+				util.Sub(util.Add(strainM, util.Scale(f.Thickness*0.5, strainP)), e0))
+			stressP = mat.NewDense(3, 1, nil)
+			for k := range f.Laminate {
+				for j, value := range f.plyStress(k, strainM, strainP, e0) {
+					res.Set(12+5*k+j, i, value)
+				}
+			}
+		}
 
 		localStrain := mat.NewDense(3, 3, []float64{
 			strainM.At(0, 0) + strainP.At(0, 0), strainM.At(2, 0) + strainP.At(2, 0), strainC.At(0, 0),
@@ -0,0 +1,191 @@
+package fe
+
+import (
+	"math"
+	"wfem/cmd/fem/util"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// shapeGradient2D - derivatives of the shape functions with respect to x and y (rows) at the i-th integration
+// point and the Jacobian
+func shapeGradient2D(shape ShapeFunction2D, i int) (*mat.Dense, float64) {
+	var invJacobi mat.Dense
+	jacobi := mat.NewDense(2, 2, nil)
+	for j := 0; j < 2; j++ {
+		for k := 0; k < shape.Size(); k++ {
+			jacobi.Set(0, j, jacobi.At(0, j)+shape.ShapeDxi(i, k)*shape.X().At(k, j))
+			jacobi.Set(1, j, jacobi.At(1, j)+shape.ShapeDeta(i, k)*shape.X().At(k, j))
+		}
+	}
+	_ = invJacobi.Inverse(jacobi)
+	res := mat.NewDense(2, shape.Size(), nil)
+	for j := 0; j < shape.Size(); j++ {
+		res.Set(0, j, invJacobi.At(0, 0)*shape.ShapeDxi(i, j)+invJacobi.At(0, 1)*shape.ShapeDeta(i, j))
+		res.Set(1, j, invJacobi.At(1, 0)*shape.ShapeDxi(i, j)+invJacobi.At(1, 1)*shape.ShapeDeta(i, j))
+	}
+	return res, mat.Det(jacobi)
+}
+
+// centerShape2D - the shape functions, their derivatives with respect to x and y (rows) at the center of the element
+// and its area, the values at the center are the means over the integration points (exact for the linear triangle
+// and the bilinear quadrangle)
+func centerShape2D(shape ShapeFunction2D) ([]float64, *mat.Dense, float64) {
+	var invJacobi mat.Dense
+	size, num := shape.Size(), len(*shape.W())
+	n := make([]float64, size)
+	dxi := mat.NewDense(2, size, nil)
+	area := 0.0
+	for i := 0; i < num; i++ {
+		_, jacobian := shapeGradient2D(shape, i)
+		area += (*shape.W())[i] * math.Abs(jacobian)
+		for j := 0; j < size; j++ {
+			n[j] += shape.Shape(i, j) / float64(num)
+			dxi.Set(0, j, dxi.At(0, j)+shape.ShapeDxi(i, j)/float64(num))
+			dxi.Set(1, j, dxi.At(1, j)+shape.ShapeDeta(i, j)/float64(num))
+		}
+	}
+	_ = invJacobi.Inverse(util.Mul(dxi, shape.X().Slice(0, size, 0, 2)))
+	return n, util.Mul(&invJacobi, dxi), area
+}
+
+// shapeGradient3D - derivatives of the shape functions with respect to x, y and z (rows) at the i-th integration
+// point and the Jacobian
+func shapeGradient3D(shape ShapeFunction3D, i int) (*mat.Dense, float64) {
+	var invJacobi mat.Dense
+	jacobi := mat.NewDense(3, 3, nil)
+	for j := 0; j < 3; j++ {
+		for k := 0; k < shape.Size(); k++ {
+			jacobi.Set(0, j, jacobi.At(0, j)+shape.ShapeDxi(i, k)*shape.X().At(k, j))
+			jacobi.Set(1, j, jacobi.At(1, j)+shape.ShapeDeta(i, k)*shape.X().At(k, j))
+			jacobi.Set(2, j, jacobi.At(2, j)+shape.ShapeDpsi(i, k)*shape.X().At(k, j))
+		}
+	}
+	_ = invJacobi.Inverse(jacobi)
+	res := mat.NewDense(3, shape.Size(), nil)
+	for j := 0; j < shape.Size(); j++ {
+		for k := 0; k < 3; k++ {
+			res.Set(k, j, invJacobi.At(k, 0)*shape.ShapeDxi(i, j)+invJacobi.At(k, 1)*shape.ShapeDeta(i, j)+invJacobi.At(k, 2)*shape.ShapeDpsi(i, j))
+		}
+	}
+	return res, mat.Det(jacobi)
+}
+
+// strain - strain vector (in the order of the gradient matrices: xx, yy, xy in 2D and xx, yy, zz, xy, yz, xz in 3D)
+// from the nodal displacements u, freedom - number of the degrees of freedom of the node
+func strain(dn *mat.Dense, u *mat.VecDense, freedom int) *mat.VecDense {
+	dim, size := dn.Dims()
+	// Displacement gradient: du[i][j] = d(u_i)/d(x_j)
+	var du [3][3]float64
+	for i := 0; i < dim; i++ {
+		for j := 0; j < dim; j++ {
+			for k := 0; k < size; k++ {
+				du[i][j] += dn.At(j, k) * u.AtVec(freedom*k+i)
+			}
+		}
+	}
+	if dim == 2 {
+		return mat.NewVecDense(3, []float64{du[0][0], du[1][1], du[0][1] + du[1][0]})
+	}
+	return mat.NewVecDense(6, []float64{du[0][0], du[1][1], du[2][2], du[0][1] + du[1][0], du[1][2] + du[2][1], du[0][2] + du[2][0]})
+}
+
+// stressTensor - stress tensor from the stress vector (in the order of strain)
+func stressTensor(stress *mat.VecDense) *mat.Dense {
+	if stress.Len() == 3 {
+		return mat.NewDense(2, 2, []float64{stress.AtVec(0), stress.AtVec(2), stress.AtVec(2), stress.AtVec(1)})
+	}
+	return mat.NewDense(3, 3, []float64{
+		stress.AtVec(0), stress.AtVec(3), stress.AtVec(5),
+		stress.AtVec(3), stress.AtVec(1), stress.AtVec(4),
+		stress.AtVec(5), stress.AtVec(4), stress.AtVec(2),
+	})
+}
+
+// addGeometric - adding w * dN' S dN to each of the first translations degrees of freedom of the nodes
+func addGeometric(res *mat.Dense, dn, s *mat.Dense, freedom, translations int, w float64) {
+	var g mat.Dense
+	g.Product(dn.T(), s, dn)
+	_, size := dn.Dims()
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			for k := 0; k < translations; k++ {
+				res.Set(freedom*i+k, freedom*j+k, res.At(freedom*i+k, freedom*j+k)+w*g.At(i, j))
+			}
+		}
+	}
+}
+
+// Geometric - the rod has no transverse degrees of freedom, so its geometric stiffness is zero
+func (f *FiniteElement1D) Geometric(_ *mat.VecDense) *mat.Dense {
+	return mat.NewDense(f.size*f.freedom, f.size*f.freedom, nil)
+}
+
+// Geometric - geometric (initial stress) stiffness matrix for the displacements u of the nodes, the stresses
+// include the thermal ones. In the axisymmetric problem the hoop stress adds the term N' Stt N / r^2 to the
+// radial displacements
+func (f *FiniteElement2D) Geometric(u *mat.VecDense) *mat.Dense {
+	res := mat.NewDense(f.size*f.freedom, f.size*f.freedom, nil)
+	for i := 0; i < len(*f.W()); i++ {
+		dn, jacobian := shapeGradient2D(f.ShapeFunction2D, i)
+		w := (*f.W())[i] * f.thickness(i) * math.Abs(jacobian)
+		var stress mat.VecDense
+		stress.MulVec(f.gradientMatrix(dn, i), u)
+		stress.SubVec(&stress, f.thermalStrain(f.temperature(f.ShapeFunction2D, i)))
+		stress.MulVec(f.elasticMatrix(), &stress)
+		addGeometric(res, dn, stressTensor(stress.SliceVec(0, 3).(*mat.VecDense)), f.freedom, f.freedom, w)
+		if f.Mode == Axisymmetric {
+			r := f.radius(i)
+			for j := 0; j < f.size; j++ {
+				for k := 0; k < f.size; k++ {
+					res.Set(f.freedom*j, f.freedom*k, res.At(f.freedom*j, f.freedom*k)+w*stress.AtVec(3)*f.Shape(i, j)*f.Shape(i, k)/(r*r))
+				}
+			}
+		}
+	}
+	return res
+}
+
+// Geometric - geometric (initial stress) stiffness matrix for the displacements u of the nodes
+func (f *FiniteElement3D) Geometric(u *mat.VecDense) *mat.Dense {
+	res := mat.NewDense(f.size*f.freedom, f.size*f.freedom, nil)
+	for i := 0; i < len(*f.W()); i++ {
+		dn, jacobian := shapeGradient3D(f.ShapeFunction3D, i)
+		var stress mat.VecDense
+		stress.SubVec(strain(dn, u, f.freedom), f.initialStrain(f.temperature(f.ShapeFunction3D, i), 6, 3))
+		stress.MulVec(f.elasticMatrix(), &stress)
+		addGeometric(res, dn, stressTensor(&stress), f.freedom, f.freedom, (*f.W())[i]*math.Abs(jacobian))
+	}
+	return res
+}
+
+// Geometric - geometric stiffness matrix of the shell caused by the membrane forces, it is applied to all
+// translational degrees of freedom. The membrane forces of the laminate are A (e - e0) + B k, k - the curvatures
+func (f *FiniteElement3DS) Geometric(u *mat.VecDense) *mat.Dense {
+	var a, b *mat.Dense
+	if f.Laminate != nil {
+		a, b, _, _ = f.laminateMatrix()
+	}
+	res := mat.NewDense(f.size*f.freedom, f.size*f.freedom, nil)
+	t := util.ExtTransformMatrix(f.transformMatrix, f.size*f.freedom)
+	var lu mat.VecDense
+	lu.MulVec(t, u)
+	for i := 0; i < len(*f.W()); i++ {
+		dn, jacobian := shapeGradient2D(f.ShapeFunction2D, i)
+		var force mat.VecDense
+		force.SubVec(strain(dn, &lu, f.freedom), f.initialStrain(f.temperature(f.ShapeFunction2D, i), 3, 2))
+		if f.Laminate != nil {
+			var curvature mat.VecDense
+			curvature.MulVec(f.bendingMatrix(dn), &lu)
+			curvature.MulVec(b, &curvature)
+			force.MulVec(a, &force)
+			force.AddVec(&force, &curvature)
+		} else {
+			force.MulVec(f.elasticMatrix(), &force)
+			force.ScaleVec(f.Thickness, &force)
+		}
+		addGeometric(res, dn, stressTensor(&force), f.freedom, 3, (*f.W())[i]*math.Abs(jacobian))
+	}
+	// Convert from local to global coordinates
+	return util.Mul(util.Mul(t.T(), res), t)
+}
@@ -0,0 +1,163 @@
+package fe
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Natural coordinates of the vertices of the eight-node hexahedron
+var corners3d8 = [][3]float64{
+	{-1.0, -1.0, -1.0}, {1.0, -1.0, -1.0}, {1.0, 1.0, -1.0}, {-1.0, 1.0, -1.0},
+	{-1.0, -1.0, 1.0}, {1.0, -1.0, 1.0}, {1.0, 1.0, 1.0}, {-1.0, 1.0, 1.0},
+}
+
+// reducedShape - the shape functions at the points of the reduced integration rule (nil - the element has no such
+// rule) and the natural coordinates of the vertices of the linear element integrated at its center, which needs the
+// hourglass control (nil - not needed)
+func reducedShape(shape ShapeFunction1D) (ShapeFunction1D, [][3]float64) {
+	switch s := shape.(type) {
+	case *Shape2d4:
+		res := *s
+		res.xi, res.eta, res.w = &[]float64{0.0}, &[]float64{0.0}, &[]float64{4.0}
+		corners := make([][3]float64, len(corners2d4))
+		for i, c := range corners2d4 {
+			corners[i] = [3]float64{c[0], c[1], 0.0}
+		}
+		return &res, corners
+	case *Shape3d8:
+		res := *s
+		res.xi, res.eta, res.psi, res.w = &[]float64{0.0}, &[]float64{0.0}, &[]float64{0.0}, &[]float64{8.0}
+		return &res, corners3d8
+	case *Shape3d20:
+		var xi, eta, psi, w []float64
+		for _, c := range corners3d8 {
+			xi, eta, psi = append(xi, c[0]/math.Sqrt(3.0)), append(eta, c[1]/math.Sqrt(3.0)), append(psi, c[2]/math.Sqrt(3.0))
+			w = append(w, 1.0)
+		}
+		res := *s
+		res.xi, res.eta, res.psi, res.w = &xi, &eta, &psi, &w
+		return &res, nil
+	}
+	return nil, nil
+}
+
+// volumetricSplit - the deviatoric and volumetric parts of the elastic matrix d, m - the volumetric strain vector.
+// The volumetric part d m m^T d / (m^T d m) is the bulk modulus times m m^T for the isotropic material
+func volumetricSplit(d *mat.Dense, m *mat.VecDense) (*mat.Dense, *mat.Dense) {
+	var dm mat.VecDense
+	var volumetric, deviatoric mat.Dense
+	dm.MulVec(d, m)
+	volumetric.Outer(1.0/mat.Dot(m, &dm), &dm, &dm)
+	deviatoric.Sub(d, &volumetric)
+	return &deviatoric, &volumetric
+}
+
+// uniaxialModulus - mean modulus of the first n (normal) strain components of the elastic matrix d when the other
+// stresses are free, it is Young's modulus of the isotropic material
+func uniaxialModulus(d *mat.Dense, n int) float64 {
+	var compliance mat.Dense
+	_ = compliance.Inverse(d)
+	var res float64
+	for i := 0; i < n; i++ {
+		res += 1.0 / compliance.At(i, i) / float64(n)
+	}
+	return res
+}
+
+// hourglass - stiffness of the hourglass modes of the linear quadrangle or hexahedron with the nodes x integrated
+// at its center, corners - natural coordinates of the vertices. The hourglass shape vectors are orthogonal to the
+// linear fields (Flanagan-Belytschko). In the axes of the element a mode along a natural coordinate of its own gets
+// the bending stiffness without the parasitic shear strains (modulus), other modes get the shear stiffness (shear)
+func hourglass(x *mat.Dense, corners [][3]float64, dim, freedom int, volume, modulus, shear float64) *mat.Dense {
+	n := len(corners)
+	// The Jacobi matrix and the gradients of the shape functions at the center
+	var invJacobi mat.Dense
+	jacobi := mat.NewDense(dim, dim, nil)
+	for a, c := range corners {
+		for r := 0; r < dim; r++ {
+			for j := 0; j < dim; j++ {
+				jacobi.Set(r, j, jacobi.At(r, j)+c[r]/float64(n)*x.At(a, j))
+			}
+		}
+	}
+	_ = invJacobi.Inverse(jacobi)
+	b := mat.NewDense(dim, n, nil)
+	for a, c := range corners {
+		for j := 0; j < dim; j++ {
+			for r := 0; r < dim; r++ {
+				b.Set(j, a, b.At(j, a)+invJacobi.At(j, r)*c[r]/float64(n))
+			}
+		}
+	}
+	// The orthonormal axes (rows) of the element along the natural coordinates
+	axes := mat.NewDense(dim, dim, nil)
+	for r := 0; r < dim; r++ {
+		axis := mat.NewVecDense(dim, nil)
+		axis.CopyVec(jacobi.RowView(r))
+		for k := 0; k < r; k++ {
+			axis.AddScaledVec(axis, -mat.Dot(axis, axes.RowView(k)), axes.RowView(k))
+		}
+		axis.ScaleVec(1.0/axis.Norm(2), axis)
+		axes.SetRow(r, axis.RawVector().Data)
+	}
+	// The derivative of the natural coordinate r along its axis
+	gradient := func(r int) float64 {
+		return mat.Dot(invJacobi.ColView(r), axes.RowView(r))
+	}
+	// The hourglass modes are the products of the natural coordinates
+	modes := [][]int{{0, 1}}
+	if dim == 3 {
+		modes = [][]int{{0, 1}, {1, 2}, {0, 2}, {0, 1, 2}}
+	}
+	res := mat.NewDense(n*freedom, n*freedom, nil)
+	for _, mode := range modes {
+		h := make([]float64, n)
+		for a, c := range corners {
+			h[a] = 1.0
+			for _, r := range mode {
+				h[a] *= c[r]
+			}
+		}
+		gamma := make([]float64, n)
+		copy(gamma, h)
+		for j := 0; j < dim; j++ {
+			var hx float64
+			for a := range corners {
+				hx += h[a] * x.At(a, j)
+			}
+			for a := range corners {
+				gamma[a] -= hx * b.At(j, a)
+			}
+		}
+		// The mean square of the derivative of the mode with respect to a natural coordinate in the mode is 1/3
+		// or 1/9, the mode displacements are gamma^T u / n
+		weight := math.Pow(1.0/3.0, float64(len(mode)-1))
+		for l := 0; l < dim; l++ {
+			own := false
+			for _, r := range mode {
+				own = own || r == l
+			}
+			var k float64
+			if own {
+				k = modulus * weight * gradient(l) * gradient(l)
+			} else {
+				for _, r := range mode {
+					k += shear * weight * gradient(r) * gradient(r)
+				}
+			}
+			k *= volume / float64(n*n)
+			for a := 0; a < n; a++ {
+				for c := 0; c < n; c++ {
+					for i := 0; i < dim; i++ {
+						for j := 0; j < dim; j++ {
+							res.Set(freedom*a+i, freedom*c+j, res.At(freedom*a+i, freedom*c+j)+
+								k*gamma[a]*gamma[c]*axes.At(l, i)*axes.At(l, j))
+						}
+					}
+				}
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,142 @@
+package fe
+
+import (
+	"math"
+	"wfem/cmd/fem/util"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Ply - layer of the shell laminate: its thickness, the orthotropic material (E1, E2, G12, G13, G23 and Nu12 are
+// used) and the strengths. The fibres (the material axis 1) are turned by Angle degrees about the normal from the
+// reference axis of the laminate
+type Ply struct {
+	Thickness float64
+	Angle     float64
+	Material  OrthotropicMaterial
+	Strength  Strength
+}
+
+// Strength - tensile and compressive (positive values) strengths of the ply along (X) and across (Y) the fibres and
+// its in-plane shear strength S, the failure indices are not calculated if they are not set
+type Strength struct {
+	Xt, Xc, Yt, Yc, S float64
+}
+
+// stiffness - elastic matrix of the ply in its axes in the order of strain: the reduced stiffness of the in-plane
+// components and the transverse shear moduli
+func (p *Ply) stiffness() *mat.Dense {
+	m := p.Material
+	k := 1.0 - m.Nu12*m.Nu12*m.E2/m.E1
+	res := mat.NewDense(6, 6, nil)
+	res.Set(0, 0, m.E1/k)
+	res.Set(0, 1, m.Nu12*m.E2/k)
+	res.Set(1, 0, m.Nu12*m.E2/k)
+	res.Set(1, 1, m.E2/k)
+	res.Set(3, 3, m.G12)
+	res.Set(4, 4, m.G23)
+	res.Set(5, 5, m.G13)
+	return res
+}
+
+// failureIndex - Tsai-Wu and maximum stress failure indices for the stresses s11, s22, s12 in the ply axes, the
+// ply fails if any of them reaches 1
+func (s Strength) failureIndex(s11, s22, s12 float64) (float64, float64) {
+	if s.Xt == 0 || s.Xc == 0 || s.Yt == 0 || s.Yc == 0 || s.S == 0 {
+		return 0, 0
+	}
+	f11 := 1.0 / (s.Xt * s.Xc)
+	f22 := 1.0 / (s.Yt * s.Yc)
+	f12 := -0.5 * math.Sqrt(f11*f22)
+	tsaiWu := (1.0/s.Xt-1.0/s.Xc)*s11 + (1.0/s.Yt-1.0/s.Yc)*s22 + f11*s11*s11 + f22*s22*s22 + s12*s12/(s.S*s.S) +
+		2.0*f12*s11*s22
+	maxStress := math.Abs(s12) / s.S
+	if s11 > 0 {
+		maxStress = math.Max(maxStress, s11/s.Xt)
+	} else {
+		maxStress = math.Max(maxStress, -s11/s.Xc)
+	}
+	if s22 > 0 {
+		maxStress = math.Max(maxStress, s22/s.Yt)
+	} else {
+		maxStress = math.Max(maxStress, -s22/s.Yc)
+	}
+	return tsaiWu, maxStress
+}
+
+// plyAxes - axes of the k-th ply (rows) in the local axes of the shell, the reference axis of the laminate is the
+// material axis 1
+func (f *FiniteElement3DS) plyAxes(k int) *mat.Dense {
+	a := f.materialAxes(f.transformMatrix.RawRowView(2))
+	a.Mul(a, f.transformMatrix.T())
+	phi := math.Atan2(a.At(0, 1), a.At(0, 0)) + f.Laminate[k].Angle*math.Pi/180.0
+	return mat.NewDense(3, 3, []float64{math.Cos(phi), math.Sin(phi), 0.0, -math.Sin(phi), math.Cos(phi), 0.0, 0.0, 0.0, 1.0})
+}
+
+// plyMatrix - elastic matrix of the k-th ply in the local axes of the shell
+func (f *FiniteElement3DS) plyMatrix(k int) *mat.Dense {
+	var res mat.Dense
+	t := strainTransform(f.plyAxes(k))
+	res.Mul(t.T(), f.Laminate[k].stiffness())
+	res.Mul(&res, t)
+	return &res
+}
+
+// plyBounds - coordinates of the bottom and top faces of the k-th ply, the plies are laid from the bottom face
+// (negative local z) of the shell
+func (f *FiniteElement3DS) plyBounds(k int) (float64, float64) {
+	z := -0.5 * f.Thickness
+	for i := 0; i < k; i++ {
+		z += f.Laminate[i].Thickness
+	}
+	return z, z + f.Laminate[k].Thickness
+}
+
+// laminateMatrix - the membrane (A), coupling (B) and bending (D) stiffness matrices of the laminate and its
+// transverse shear stiffness (S) in the local axes of the shell
+func (f *FiniteElement3DS) laminateMatrix() (a, b, d, s *mat.Dense) {
+	a, b, d, s = mat.NewDense(3, 3, nil), mat.NewDense(3, 3, nil), mat.NewDense(3, 3, nil), mat.NewDense(2, 2, nil)
+	for k := range f.Laminate {
+		q := f.plyMatrix(k)
+		z0, z1 := f.plyBounds(k)
+		a.Add(a, util.Scale(z1-z0, subMatrix(q, 0, 1, 3)))
+		b.Add(b, util.Scale((z1*z1-z0*z0)/2.0, subMatrix(q, 0, 1, 3)))
+		d.Add(d, util.Scale((z1*z1*z1-z0*z0*z0)/3.0, subMatrix(q, 0, 1, 3)))
+		s.Add(s, util.Scale(5.0/6.0*(z1-z0), subMatrix(q, 5, 4)))
+	}
+	return a, b, d, s
+}
+
+// plyStress - stresses (S11, S22, S12) in the axes of the k-th ply in the middle of its thickness and the largest
+// Tsai-Wu and maximum stress failure indices over its faces and middle for the membrane strains, curvatures and
+// initial strains of the shell
+func (f *FiniteElement3DS) plyStress(k int, strainM, strainP, e0 mat.Matrix) [5]float64 {
+	var res [5]float64
+	z0, z1 := f.plyBounds(k)
+	t := subMatrix(strainTransform(f.plyAxes(k)), 0, 1, 3)
+	q := subMatrix(f.Laminate[k].stiffness(), 0, 1, 3)
+	for i, z := range []float64{z0, 0.5 * (z0 + z1), z1} {
+		stress := util.Mul(q, util.Mul(t, util.Sub(util.Add(strainM, util.Scale(z, strainP)), e0)))
+		if i == 1 {
+			res[0], res[1], res[2] = stress.At(0, 0), stress.At(1, 0), stress.At(2, 0)
+		}
+		tsaiWu, maxStress := f.Laminate[k].Strength.failureIndex(stress.At(0, 0), stress.At(1, 0), stress.At(2, 0))
+		if i == 0 || tsaiWu > res[3] {
+			res[3] = tsaiWu
+		}
+		res[4] = math.Max(res[4], maxStress)
+	}
+	return res
+}
+
+// bendingMatrix - gradient matrix of the curvatures of the shell (curvature = B u) in the local axes
+func (f *FiniteElement3DS) bendingMatrix(dn *mat.Dense) *mat.Dense {
+	res := mat.NewDense(3, f.size*f.freedom, nil)
+	for j := 0; j < f.size; j++ {
+		res.Set(0, f.freedom*j+4, dn.At(0, j))
+		res.Set(1, f.freedom*j+3, -dn.At(1, j))
+		res.Set(2, f.freedom*j+4, dn.At(1, j))
+		res.Set(2, f.freedom*j+3, -dn.At(0, j))
+	}
+	return res
+}
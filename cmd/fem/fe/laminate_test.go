@@ -0,0 +1,97 @@
+package fe
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// unitShell - the flat square shell [0, 1] x [0, 1] in the global xy plane made of the plies
+func unitShell(t *testing.T, plies []Ply) *FiniteElement3DS {
+	shape, err := NewShape2d4(mat.NewDense(4, 2, []float64{0, 0, 1, 0, 1, 1, 0, 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := FiniteElementParameters{Laminate: plies}
+	for _, ply := range plies {
+		params.Thickness += ply.Thickness
+	}
+	return NewFE3DS(shape, mat.NewDense(3, 3, []float64{1, 0, 0, 0, 1, 0, 0, 0, 1}), params)
+}
+
+// checkMatrix - the matrix is the expected one (rows) up to the error eps
+func checkMatrix(t *testing.T, name string, got *mat.Dense, want [][]float64, eps float64) {
+	for i := range want {
+		for j := range want[i] {
+			if math.Abs(got.At(i, j)-want[i][j]) > eps {
+				t.Fatalf("%s[%d][%d] is %g, want %g", name, i, j, got.At(i, j), want[i][j])
+			}
+		}
+	}
+}
+
+// The ABD matrices of the cross-ply and angle-ply laminates of the unit thickness: the reduced stiffness of the ply
+// is Q11 = E1 / k, Q22 = E2 / k, Q12 = nu12 E2 / k, Q66 = G12, k = 1 - nu12^2 E2 / E1, it is Q22, Q11 for the ply
+// at 90 degrees and Q11 = Q22 = (Q11 + Q22 + 2 Q12 + 4 Q66) / 4, Q12 = (Q11 + Q22 - 4 Q66) / 4 + Q12 / 2,
+// Q66 = (Q11 + Q22 - 2 Q12) / 4, Q16 = Q26 = +-(Q11 - Q22) / 4 at +-45 degrees
+func TestLaminateMatrix(t *testing.T) {
+	material := OrthotropicMaterial{E1: 1.4e+5, E2: 1.0e+4, E3: 1.0e+4, G12: 5.0e+3, G13: 5.0e+3, G23: 3.5e+3, Nu12: 0.3}
+	k := 1.0 - material.Nu12*material.Nu12*material.E2/material.E1
+	q11, q22, q12, q66 := material.E1/k, material.E2/k, material.Nu12*material.E2/k, material.G12
+	q45 := (q11 + q22 + 2.0*q12 + 4.0*q66) / 4.0
+	q1245 := (q11+q22-4.0*q66)/4.0 + 0.5*q12
+	q6645 := (q11 + q22 - 2.0*q12) / 4.0
+	q16 := (q11 - q22) / 4.0
+	ply := func(thickness, angle float64) Ply {
+		return Ply{Thickness: thickness, Angle: angle, Material: material}
+	}
+	tests := []struct {
+		name    string
+		plies   []Ply
+		a, b, d [][]float64
+	}{
+		{"0/90/90/0", []Ply{ply(0.25, 0), ply(0.25, 90), ply(0.25, 90), ply(0.25, 0)},
+			[][]float64{{0.5 * (q11 + q22), q12, 0}, {q12, 0.5 * (q11 + q22), 0}, {0, 0, q66}},
+			[][]float64{{0, 0, 0}, {0, 0, 0}, {0, 0, 0}},
+			[][]float64{{(7.0*q11 + q22) / 96.0, q12 / 12.0, 0}, {q12 / 12.0, (q11 + 7.0*q22) / 96.0, 0}, {0, 0, q66 / 12.0}}},
+		{"0/90", []Ply{ply(0.5, 0), ply(0.5, 90)},
+			[][]float64{{0.5 * (q11 + q22), q12, 0}, {q12, 0.5 * (q11 + q22), 0}, {0, 0, q66}},
+			[][]float64{{0.125 * (q22 - q11), 0, 0}, {0, 0.125 * (q11 - q22), 0}, {0, 0, 0}},
+			[][]float64{{(q11 + q22) / 24.0, q12 / 12.0, 0}, {q12 / 12.0, (q11 + q22) / 24.0, 0}, {0, 0, q66 / 12.0}}},
+		{"45/-45", []Ply{ply(0.5, 45), ply(0.5, -45)},
+			[][]float64{{q45, q1245, 0}, {q1245, q45, 0}, {0, 0, q6645}},
+			[][]float64{{0, 0, -0.25 * q16}, {0, 0, -0.25 * q16}, {-0.25 * q16, -0.25 * q16, 0}},
+			[][]float64{{q45 / 12.0, q1245 / 12.0, 0}, {q1245 / 12.0, q45 / 12.0, 0}, {0, 0, q6645 / 12.0}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b, d, _ := unitShell(t, test.plies).laminateMatrix()
+			checkMatrix(t, "A", a, test.a, 1.0e-9*q11)
+			checkMatrix(t, "B", b, test.b, 1.0e-9*q11)
+			checkMatrix(t, "D", d, test.d, 1.0e-9*q11)
+		})
+	}
+}
+
+// The geometric stiffness of the flat unsymmetric laminate with the membrane strain exx = e and the curvature kxx = c
+// is that of the membrane force Nx = A11 e + B11 c: the work of the deflection w = x is Nx for the unit square
+func TestLaminateGeometric(t *testing.T) {
+	const e, c = 1.0e-3, 2.0e-3
+	material := OrthotropicMaterial{E1: 1.4e+5, E2: 1.0e+4, E3: 1.0e+4, G12: 5.0e+3, G13: 5.0e+3, G23: 3.5e+3, Nu12: 0.3}
+	shell := unitShell(t, []Ply{{Thickness: 0.5, Material: material}, {Thickness: 0.5, Angle: 90, Material: material}})
+	a, b, _, _ := shell.laminateMatrix()
+	x := shell.X()
+	u := mat.NewVecDense(24, nil)
+	w := mat.NewVecDense(24, nil)
+	for j := 0; j < 4; j++ {
+		u.SetVec(6*j, e*x.At(j, 0))
+		u.SetVec(6*j+4, c*x.At(j, 0))
+		w.SetVec(6*j+2, x.At(j, 0))
+	}
+	var kw mat.VecDense
+	kw.MulVec(shell.Geometric(u), w)
+	if got, want := mat.Dot(w, &kw), a.At(0, 0)*e+b.At(0, 0)*c; math.Abs(got-want) > 1.0e-9*math.Abs(want) {
+		t.Fatalf("work of the membrane forces is %g, want %g", got, want)
+	}
+}
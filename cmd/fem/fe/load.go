@@ -0,0 +1,171 @@
+package fe
+
+import (
+	"math"
+	"wfem/cmd/fem/util"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// temperature - temperature at the i-th integration point interpolated from the temperatures of the nodes
+func (p *FiniteElementParameters) temperature(shape ShapeFunction1D, i int) float64 {
+	var res float64
+	for j := range p.Temperature {
+		res += shape.Shape(i, j) * p.Temperature[j]
+	}
+	return res
+}
+
+// initialStrain - thermal strain vector of the length size for the temperature t, only the first normal
+// components are non-zero
+func (p *FiniteElementParameters) initialStrain(t float64, size, normal int) *mat.VecDense {
+	res := mat.NewVecDense(size, nil)
+	for i := 0; i < normal; i++ {
+		res.SetVec(i, p.ThermalExpansion*t)
+	}
+	return res
+}
+
+// nodeTemperature - temperature of the i-th node of the element
+func (p *FiniteElementParameters) nodeTemperature(i int) float64 {
+	if p.Temperature == nil {
+		return 0
+	}
+	return p.Temperature[i]
+}
+
+// thermalStrain - thermal strain vector of the 2D element for the temperature t. In the plane strain problem
+// the restrained expansion across the plane is included in the in-plane components
+func (f *FiniteElement2D) thermalStrain(t float64) *mat.VecDense {
+	switch f.Mode {
+	case PlaneStrain:
+		if f.isAnisotropic() {
+			// e0 + Dpp^-1 Dpz e0z, p - the in-plane components, z - the restrained one
+			d := f.materialMatrix(nil, []float64{0.0, 0.0, 1.0})
+			var dpz, res mat.VecDense
+			dpz.ScaleVec(f.ThermalExpansion*t, mat.NewVecDense(3, []float64{d.At(0, 2), d.At(1, 2), d.At(3, 2)}))
+			_ = res.SolveVec(subMatrix(d, 0, 1, 3), &dpz)
+			res.AddVec(&res, f.initialStrain(t, 3, 2))
+			return &res
+		}
+		res := f.initialStrain(t, 3, 2)
+		res.ScaleVec(1.0+f.PoissonRation, res)
+		return res
+	case Axisymmetric:
+		res := f.initialStrain(t, 4, 2)
+		res.SetVec(3, res.AtVec(0))
+		return res
+	}
+	return f.initialStrain(t, 3, 2)
+}
+
+// strainMatrix - gradient matrix B (strain = B u) in the order of strain
+func strainMatrix(dn *mat.Dense, freedom int) *mat.Dense {
+	dim, size := dn.Dims()
+	if dim == 2 {
+		res := mat.NewDense(3, size*freedom, nil)
+		for j := 0; j < size; j++ {
+			res.Set(0, freedom*j+0, dn.At(0, j))
+			res.Set(1, freedom*j+1, dn.At(1, j))
+			res.Set(2, freedom*j+0, dn.At(1, j))
+			res.Set(2, freedom*j+1, dn.At(0, j))
+		}
+		return res
+	}
+	res := mat.NewDense(6, size*freedom, nil)
+	for j := 0; j < size; j++ {
+		res.Set(0, freedom*j+0, dn.At(0, j))
+		res.Set(1, freedom*j+1, dn.At(1, j))
+		res.Set(2, freedom*j+2, dn.At(2, j))
+		res.Set(3, freedom*j+0, dn.At(1, j))
+		res.Set(3, freedom*j+1, dn.At(0, j))
+		res.Set(4, freedom*j+1, dn.At(2, j))
+		res.Set(4, freedom*j+2, dn.At(1, j))
+		res.Set(5, freedom*j+0, dn.At(2, j))
+		res.Set(5, freedom*j+2, dn.At(0, j))
+	}
+	return res
+}
+
+// addInitialStrainLoad - adding w * B' D e0 to the load vector
+func addInitialStrainLoad(res *mat.VecDense, b, d *mat.Dense, e0 *mat.VecDense, w float64) {
+	var s, load mat.VecDense
+	s.MulVec(d, e0)
+	load.MulVec(b.T(), &s)
+	res.AddScaledVec(res, w, &load)
+}
+
+// Load - equivalent nodal forces of the thermal strain
+func (f *FiniteElement1D) Load() *mat.VecDense {
+	res := mat.NewVecDense(f.size*f.freedom, nil)
+	if f.Temperature == nil {
+		return res
+	}
+	jacobian := (f.X().At(1, 0) - f.X().At(0, 0)) * 0.5
+	b := mat.NewDense(1, f.size, nil)
+	for j := 0; j < f.size; j++ {
+		b.Set(0, j, f.ShapeDx(0, j))
+	}
+	d := mat.NewDense(1, 1, []float64{f.YoungModulus})
+	for i := 0; i < len(*f.W()); i++ {
+		e0 := f.initialStrain(f.temperature(f.ShapeFunction1D, i), 1, 1)
+		addInitialStrainLoad(res, b, d, e0, (*f.W())[i]*f.Thickness*math.Abs(jacobian))
+	}
+	return res
+}
+
+// Load - equivalent nodal forces of the thermal strain
+func (f *FiniteElement2D) Load() *mat.VecDense {
+	res := mat.NewVecDense(f.size*f.freedom, nil)
+	if f.Temperature == nil {
+		return res
+	}
+	for i := 0; i < len(*f.W()); i++ {
+		dn, jacobian := shapeGradient2D(f.ShapeFunction2D, i)
+		e0 := f.thermalStrain(f.temperature(f.ShapeFunction2D, i))
+		addInitialStrainLoad(res, f.gradientMatrix(dn, i), f.elasticMatrix(), e0, (*f.W())[i]*f.thickness(i)*math.Abs(jacobian))
+	}
+	return res
+}
+
+// Load - equivalent nodal forces of the thermal strain
+func (f *FiniteElement3D) Load() *mat.VecDense {
+	res := mat.NewVecDense(f.size*f.freedom, nil)
+	if f.Temperature == nil {
+		return res
+	}
+	for i := 0; i < len(*f.W()); i++ {
+		dn, jacobian := shapeGradient3D(f.ShapeFunction3D, i)
+		e0 := f.initialStrain(f.temperature(f.ShapeFunction3D, i), 6, 3)
+		addInitialStrainLoad(res, strainMatrix(dn, f.freedom), f.elasticMatrix(), e0, (*f.W())[i]*math.Abs(jacobian))
+	}
+	return res
+}
+
+// Load - equivalent nodal forces of the thermal strain, the temperature is constant through the thickness,
+// so only the membrane forces arise, and the moments of the unsymmetric laminate
+func (f *FiniteElement3DS) Load() *mat.VecDense {
+	res := mat.NewVecDense(f.size*f.freedom, nil)
+	if f.Temperature == nil {
+		return res
+	}
+	var a, b *mat.Dense
+	if f.Laminate != nil {
+		a, b, _, _ = f.laminateMatrix()
+	}
+	for i := 0; i < len(*f.W()); i++ {
+		dn, jacobian := shapeGradient2D(f.ShapeFunction2D, i)
+		e0 := f.initialStrain(f.temperature(f.ShapeFunction2D, i), 3, 2)
+		if f.Laminate != nil {
+			addInitialStrainLoad(res, strainMatrix(dn, f.freedom), a, e0, (*f.W())[i]*math.Abs(jacobian))
+			bp, _ := f.lockingFree(shapeValues(f.ShapeFunction2D, i), f.bendingMatrix(dn), nil)
+			addInitialStrainLoad(res, bp, b, e0, (*f.W())[i]*math.Abs(jacobian))
+			continue
+		}
+		addInitialStrainLoad(res, strainMatrix(dn, f.freedom), f.elasticMatrix(), e0, (*f.W())[i]*f.Thickness*math.Abs(jacobian))
+	}
+	// Convert from local to global coordinates
+	var global mat.VecDense
+	global.MulVec(util.ExtTransformMatrix(f.transformMatrix, f.size*f.freedom).T(), res)
+	return &global
+}
@@ -0,0 +1,98 @@
+package fe
+
+import (
+	"math"
+	"wfem/cmd/fem/util"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// integrateMass - consistent mass matrix, density[k] is the mass per unit of the element measure for the k-th
+// degree of freedom of the node
+func integrateMass(shape ShapeFunction1D, jacobian func(int) float64, density []float64) *mat.Dense {
+	size, freedom := shape.Size(), len(density)
+	res := mat.NewDense(size*freedom, size*freedom, nil)
+	// Numerical integration according to the Gauss formula
+	for i := 0; i < len(*shape.W()); i++ {
+		w := (*shape.W())[i] * math.Abs(jacobian(i))
+		for j := 0; j < size; j++ {
+			for k := 0; k < size; k++ {
+				for l := 0; l < freedom; l++ {
+					res.Set(freedom*j+l, freedom*k+l, res.At(freedom*j+l, freedom*k+l)+w*density[l]*shape.Shape(i, j)*shape.Shape(i, k))
+				}
+			}
+		}
+	}
+	return res
+}
+
+// lumpMass - diagonal mass matrix (HRZ lumping): the diagonal of the consistent matrix is scaled to save the
+// total mass for each degree of freedom of the node
+func lumpMass(m *mat.Dense, freedom int) *mat.Dense {
+	size, _ := m.Dims()
+	res := mat.NewDense(size, size, nil)
+	for k := 0; k < freedom; k++ {
+		total, diag := 0.0, 0.0
+		for i := k; i < size; i += freedom {
+			diag += m.At(i, i)
+			for j := k; j < size; j += freedom {
+				total += m.At(i, j)
+			}
+		}
+		if diag == 0 {
+			continue
+		}
+		for i := k; i < size; i += freedom {
+			res.Set(i, i, m.At(i, i)*total/diag)
+		}
+	}
+	return res
+}
+
+// Mass - mass matrix of the rod, the thickness is the cross-sectional area
+func (f *FiniteElement1D) Mass(lumped bool) *mat.Dense {
+	m := f.Density * f.Thickness * math.Abs(f.X().At(1, 0)-f.X().At(0, 0))
+	if lumped {
+		return mat.NewDense(2, 2, []float64{m / 2.0, 0.0, 0.0, m / 2.0})
+	}
+	return mat.NewDense(2, 2, []float64{m / 3.0, m / 6.0, m / 6.0, m / 3.0})
+}
+
+func (f *FiniteElement2D) Mass(lumped bool) *mat.Dense {
+	m := integrateMass(f.ShapeFunction2D, func(i int) float64 {
+		_, jacobian := shapeGradient2D(f.ShapeFunction2D, i)
+		return jacobian * f.thickness(i)
+	}, []float64{f.Density, f.Density})
+	if lumped {
+		return lumpMass(m, f.freedom)
+	}
+	return m
+}
+
+func (f *FiniteElement3D) Mass(lumped bool) *mat.Dense {
+	m := integrateMass(f.ShapeFunction3D, func(i int) float64 {
+		_, jacobian := shapeGradient3D(f.ShapeFunction3D, i)
+		return jacobian
+	}, []float64{f.Density, f.Density, f.Density})
+	if lumped {
+		return lumpMass(m, f.freedom)
+	}
+	return m
+}
+
+// Mass - mass matrix of the shell, the rotary inertia is the same for all rotations so that the matrix does not
+// depend on the orientation of the local coordinate system
+func (f *FiniteElement3DS) Mass(lumped bool) *mat.Dense {
+	translation := f.Density * f.Thickness
+	rotation := f.Density * math.Pow(f.Thickness, 3) / 12.0
+	m := integrateMass(f.ShapeFunction2D, func(i int) float64 {
+		_, jacobian := shapeGradient2D(f.ShapeFunction2D, i)
+		return jacobian
+	}, []float64{translation, translation, translation, rotation, rotation, rotation})
+	if lumped {
+		m = lumpMass(m, f.freedom)
+	}
+	// Convert from local to global coordinates
+	t := util.ExtTransformMatrix(f.transformMatrix, f.size*f.freedom)
+	return util.Mul(util.Mul(t.T(), m), t)
+}
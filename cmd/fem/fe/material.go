@@ -0,0 +1,177 @@
+package fe
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// OrthotropicMaterial - elastic constants in the material axes 1, 2 and 3: the Young's moduli, the shear moduli
+// and the Poisson's ratios (nu_ij - the strain in the direction j caused by the stress in the direction i)
+type OrthotropicMaterial struct {
+	E1, E2, E3    float64
+	G12, G13, G23 float64
+	Nu12          float64
+	Nu13          float64
+	Nu23          float64
+}
+
+// voigt - pairs of the tensor indices in the order of the strain components: xx, yy, zz, xy, yz, xz
+var voigt = [6][2]int{{0, 0}, {1, 1}, {2, 2}, {0, 1}, {1, 2}, {0, 2}}
+
+// isAnisotropic - the element uses the orthotropic or the general anisotropic material instead of the isotropic one
+func (p *FiniteElementParameters) isAnisotropic() bool {
+	return p.Orthotropic.E1 != 0 || p.Anisotropic != nil
+}
+
+// compliance - compliance matrix of the orthotropic material in the material axes
+func (m *OrthotropicMaterial) compliance() *mat.Dense {
+	return mat.NewDense(6, 6, []float64{
+		1.0 / m.E1, -m.Nu12 / m.E1, -m.Nu13 / m.E1, 0.0, 0.0, 0.0,
+		-m.Nu12 / m.E1, 1.0 / m.E2, -m.Nu23 / m.E2, 0.0, 0.0, 0.0,
+		-m.Nu13 / m.E1, -m.Nu23 / m.E2, 1.0 / m.E3, 0.0, 0.0, 0.0,
+		0.0, 0.0, 0.0, 1.0 / m.G12, 0.0, 0.0,
+		0.0, 0.0, 0.0, 0.0, 1.0 / m.G23, 0.0,
+		0.0, 0.0, 0.0, 0.0, 0.0, 1.0 / m.G13,
+	})
+}
+
+// materialAxes - directions of the material axes (rows) in the global coordinates. The axis 1 is set by
+// MaterialAxis and the axis 2 lies in the plane of MaterialAxis and MaterialPlane (global x and y by default).
+// For the plane elements and shells with the normal n the axis 3 is the normal and the axis 1 is the projection
+// of MaterialAxis onto the element plane
+func (p *FiniteElementParameters) materialAxes(n []float64) *mat.Dense {
+	dot := func(a, b [3]float64) float64 { return a[0]*b[0] + a[1]*b[1] + a[2]*b[2] }
+	cross := func(a, b [3]float64) [3]float64 {
+		return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+	}
+	// The component of v orthogonal to the unit vector e
+	reject := func(v, e [3]float64) [3]float64 {
+		d := dot(v, e)
+		return [3]float64{v[0] - d*e[0], v[1] - d*e[1], v[2] - d*e[2]}
+	}
+	// The global axis most orthogonal to the unit vector e
+	transverse := func(e [3]float64) [3]float64 {
+		res := [3]float64{1.0, 0.0, 0.0}
+		for i := 1; i < 3; i++ {
+			if math.Abs(e[i]) < math.Abs(dot(res, e)) {
+				res = [3]float64{}
+				res[i] = 1.0
+			}
+		}
+		return res
+	}
+	e1 := p.MaterialAxis
+	if e1 == [3]float64{} {
+		e1 = [3]float64{1.0, 0.0, 0.0}
+	}
+	var e2, e3 [3]float64
+	if n != nil {
+		e3 = normalize([3]float64{n[0], n[1], n[2]})
+		if e1 = reject(e1, e3); math.Sqrt(dot(e1, e1)) < 1.0e-10 {
+			e1 = reject(transverse(e3), e3)
+		}
+		e1 = normalize(e1)
+		e2 = cross(e3, e1)
+	} else {
+		e1 = normalize(e1)
+		v := p.MaterialPlane
+		if v == [3]float64{} {
+			v = [3]float64{0.0, 1.0, 0.0}
+		}
+		if v = reject(v, e1); math.Sqrt(dot(v, v)) < 1.0e-10 {
+			v = reject(transverse(e1), e1)
+		}
+		e2 = normalize(v)
+		e3 = cross(e1, e2)
+	}
+	return mat.NewDense(3, 3, []float64{e1[0], e1[1], e1[2], e2[0], e2[1], e2[2], e3[0], e3[1], e3[2]})
+}
+
+// strainTransform - transformation matrix of the strain vector (with the engineering shear strains) to the
+// coordinate system whose axes are the rows of a
+func strainTransform(a *mat.Dense) *mat.Dense {
+	res := mat.NewDense(6, 6, nil)
+	for i, ij := range voigt {
+		for k, kl := range voigt {
+			var value float64
+			if kl[0] == kl[1] {
+				value = a.At(ij[0], kl[0]) * a.At(ij[1], kl[0])
+			} else {
+				value = 0.5 * (a.At(ij[0], kl[0])*a.At(ij[1], kl[1]) + a.At(ij[0], kl[1])*a.At(ij[1], kl[0]))
+			}
+			if ij[0] != ij[1] {
+				value *= 2.0
+			}
+			res.Set(i, k, value)
+		}
+	}
+	return res
+}
+
+// materialMatrix - 6x6 elastic matrix of the orthotropic or anisotropic material in the coordinate system
+// whose axes are the rows of system (nil - global axes), n - normal to the plane of the 2D element or shell
+func (p *FiniteElementParameters) materialMatrix(system *mat.Dense, n []float64) *mat.Dense {
+	var d mat.Dense
+	if p.Anisotropic != nil {
+		d.CloneFrom(p.Anisotropic)
+	} else {
+		_ = d.Inverse(p.Orthotropic.compliance())
+	}
+	// The material axes in the coordinates of the system
+	a := p.materialAxes(n)
+	if system != nil {
+		a.Mul(a, system.T())
+	}
+	t := strainTransform(a)
+	var res mat.Dense
+	res.Mul(t.T(), &d)
+	res.Mul(&res, t)
+	return &res
+}
+
+// subMatrix - rows and columns of m with the given indices
+func subMatrix(m mat.Matrix, index ...int) *mat.Dense {
+	res := mat.NewDense(len(index), len(index), nil)
+	for i := range index {
+		for j := range index {
+			res.Set(i, j, m.At(index[i], index[j]))
+		}
+	}
+	return res
+}
+
+// planeStress - in-plane elastic matrix (xx, yy, xy) of the state with the zero out-of-plane stresses
+func planeStress(d *mat.Dense) *mat.Dense {
+	var compliance, res mat.Dense
+	_ = compliance.Inverse(d)
+	_ = res.Inverse(subMatrix(&compliance, 0, 1, 3))
+	return &res
+}
+
+// anisotropicMatrix - elastic matrix of the 2D element made of the orthotropic or anisotropic material, the
+// hoop direction of the axisymmetric problem is the global z axis
+func (f *FiniteElement2D) anisotropicMatrix() *mat.Dense {
+	d := f.materialMatrix(nil, []float64{0.0, 0.0, 1.0})
+	switch f.Mode {
+	case PlaneStrain:
+		return subMatrix(d, 0, 1, 3)
+	case Axisymmetric:
+		return subMatrix(d, 0, 1, 3, 2)
+	}
+	return planeStress(d)
+}
+
+// elasticMatrix - the shell is in the plane stress state in its local axes, the material axis 3 is the normal. For
+// the laminate it is the membrane stiffness averaged over the thickness
+func (f *FiniteElement3DS) elasticMatrix() *mat.Dense {
+	if f.Laminate != nil {
+		a, _, _, _ := f.laminateMatrix()
+		a.Scale(1.0/f.Thickness, a)
+		return a
+	}
+	if !f.isAnisotropic() {
+		return f.FiniteElement2D.elasticMatrix()
+	}
+	return planeStress(f.materialMatrix(f.transformMatrix, f.transformMatrix.RawRowView(2)))
+}
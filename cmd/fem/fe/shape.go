@@ -1,6 +1,8 @@
 package fe
 
 import (
+	"fmt"
+
 	"gonum.org/v1/gonum/mat"
 	"wfem/cmd/fem/util"
 )
@@ -264,3 +266,372 @@ func (s *Shape3d8) ShapeDy(i, j int) float64 {
 func (s *Shape3d8) ShapeDz(i, j int) float64 {
 	return s.c.At(3, j) + s.c.At(5, j)*s.x.At(i, 0) + s.c.At(6, j)*s.x.At(i, 1) + s.c.At(7, j)*s.x.At(i, 0)*s.x.At(i, 1)
 }
+
+// quadraticShape - shape function of the j-th node of the quadratic simplex element (the vertices are followed by
+// the mid-side nodes of the edges), l - barycentric coordinates of the point
+func quadraticShape(l []float64, edges [][2]int, j int) float64 {
+	if j < len(l) {
+		return l[j] * (2.0*l[j] - 1.0)
+	}
+	e := edges[j-len(l)]
+	return 4.0 * l[e[0]] * l[e[1]]
+}
+
+// quadraticShapeDerivative - derivative of the quadratic shape function, dl - derivatives of the barycentric
+// coordinates
+func quadraticShapeDerivative(l, dl []float64, edges [][2]int, j int) float64 {
+	if j < len(l) {
+		return (4.0*l[j] - 1.0) * dl[j]
+	}
+	e := edges[j-len(l)]
+	return 4.0 * (dl[e[0]]*l[e[1]] + l[e[0]]*dl[e[1]])
+}
+
+// quadraticGradient - derivatives of the shape functions of the quadratic simplex element with respect to the global
+// coordinates (rows) at its nodes, found with the Jacobi matrix at the nodes as for the isoparametric elements,
+// dl[k] - derivatives of the barycentric coordinates with respect to the k-th natural coordinate
+func quadraticGradient(x *mat.Dense, dl [][]float64, edges [][2]int) ([]*mat.Dense, error) {
+	var invJacobi mat.Dense
+	dim, size := len(dl), len(dl[0])+len(edges)
+	res := make([]*mat.Dense, size)
+	for i := 0; i < size; i++ {
+		// Barycentric coordinates of the node
+		l := make([]float64, len(dl[0]))
+		if i < len(l) {
+			l[i] = 1.0
+		} else {
+			e := edges[i-len(l)]
+			l[e[0]], l[e[1]] = 0.5, 0.5
+		}
+		dn := mat.NewDense(dim, size, nil)
+		jacobi := mat.NewDense(dim, dim, nil)
+		for r := 0; r < dim; r++ {
+			for k := 0; k < size; k++ {
+				dn.Set(r, k, quadraticShapeDerivative(l, dl[r], edges, k))
+				for j := 0; j < dim; j++ {
+					jacobi.Set(r, j, jacobi.At(r, j)+dn.At(r, k)*x.At(k, j))
+				}
+			}
+		}
+		if err := invJacobi.Inverse(jacobi); err != nil {
+			return nil, fmt.Errorf("bad finite element")
+		}
+		res[i] = mat.NewDense(dim, size, nil)
+		res[i].Mul(&invJacobi, dn)
+	}
+	return res, nil
+}
+
+// Mid-side nodes of the six-node triangle
+var edges2d6 = [][2]int{{0, 1}, {1, 2}, {2, 0}}
+
+// Shape2d6 - six-node triangle, the vertices are followed by the mid-side nodes, the derivatives at the nodes are
+// found with the Jacobi matrix at the nodes, so the edges may be curved
+type Shape2d6 struct {
+	ShapeData
+	gradient []*mat.Dense
+}
+
+func NewShape2d6(x *mat.Dense) (*Shape2d6, error) {
+	gradient, err := quadraticGradient(x, [][]float64{{-1.0, 1.0, 0.0}, {-1.0, 0.0, 1.0}}, edges2d6)
+	if err != nil {
+		return nil, err
+	}
+	return &Shape2d6{ShapeData: ShapeData{x: x, xi: &[]float64{0.445948490916, 0.108103018168, 0.445948490916, 0.091576213510, 0.816847572980, 0.091576213510},
+		eta: &[]float64{0.445948490916, 0.445948490916, 0.108103018168, 0.091576213510, 0.091576213510, 0.816847572980},
+		w:   &[]float64{0.111690794839, 0.111690794839, 0.111690794839, 0.054975871827, 0.054975871827, 0.054975871827}},
+		gradient: gradient}, nil
+}
+
+func (s *Shape2d6) Size() int {
+	return len(s.gradient)
+}
+
+func (s *Shape2d6) barycentric(i int) []float64 {
+	return []float64{1.0 - (*s.xi)[i] - (*s.eta)[i], (*s.xi)[i], (*s.eta)[i]}
+}
+
+func (s *Shape2d6) Shape(i, j int) float64 {
+	return quadraticShape(s.barycentric(i), edges2d6, j)
+}
+
+func (s *Shape2d6) ShapeDxi(i, j int) float64 {
+	return quadraticShapeDerivative(s.barycentric(i), []float64{-1.0, 1.0, 0.0}, edges2d6, j)
+}
+
+func (s *Shape2d6) ShapeDeta(i, j int) float64 {
+	return quadraticShapeDerivative(s.barycentric(i), []float64{-1.0, 0.0, 1.0}, edges2d6, j)
+}
+
+func (s *Shape2d6) ShapeDx(i, j int) float64 {
+	return s.gradient[i].At(0, j)
+}
+
+func (s *Shape2d6) ShapeDy(i, j int) float64 {
+	return s.gradient[i].At(1, j)
+}
+
+// Mid-side nodes of the ten-node tetrahedron (in the Gmsh order)
+var edges3d10 = [][2]int{{0, 1}, {1, 2}, {2, 0}, {0, 3}, {2, 3}, {1, 3}}
+
+// Shape3d10 - ten-node tetrahedron, the vertices are followed by the mid-side nodes, the derivatives at the nodes
+// are found with the Jacobi matrix at the nodes, so the edges may be curved
+type Shape3d10 struct {
+	ShapeData
+	gradient []*mat.Dense
+}
+
+func NewShape3d10(x *mat.Dense) (*Shape3d10, error) {
+	gradient, err := quadraticGradient(x, [][]float64{{-1.0, 1.0, 0.0, 0.0}, {-1.0, 0.0, 1.0, 0.0}, {-1.0, 0.0, 0.0, 1.0}}, edges3d10)
+	if err != nil {
+		return nil, err
+	}
+	return &Shape3d10{ShapeData: ShapeData{x: x,
+		xi: &[]float64{0.721794249067, 0.092735250311, 0.092735250311, 0.092735250311, 0.067342242210, 0.310885919263, 0.310885919263, 0.310885919263,
+			0.454496295874, 0.045503704126, 0.045503704126, 0.454496295874, 0.454496295874, 0.045503704126},
+		eta: &[]float64{0.092735250311, 0.721794249067, 0.092735250311, 0.092735250311, 0.310885919263, 0.067342242210, 0.310885919263, 0.310885919263,
+			0.045503704126, 0.454496295874, 0.045503704126, 0.454496295874, 0.045503704126, 0.454496295874},
+		psi: &[]float64{0.092735250311, 0.092735250311, 0.721794249067, 0.092735250311, 0.310885919263, 0.310885919263, 0.067342242210, 0.310885919263,
+			0.045503704126, 0.045503704126, 0.454496295874, 0.045503704126, 0.454496295874, 0.454496295874},
+		w: &[]float64{0.012248840519, 0.012248840519, 0.012248840519, 0.012248840519, 0.018781320953, 0.018781320953, 0.018781320953, 0.018781320953,
+			0.007091003463, 0.007091003463, 0.007091003463, 0.007091003463, 0.007091003463, 0.007091003463}},
+		gradient: gradient}, nil
+}
+
+func (s *Shape3d10) Size() int {
+	return len(s.gradient)
+}
+
+func (s *Shape3d10) barycentric(i int) []float64 {
+	return []float64{1.0 - (*s.xi)[i] - (*s.eta)[i] - (*s.psi)[i], (*s.xi)[i], (*s.eta)[i], (*s.psi)[i]}
+}
+
+func (s *Shape3d10) Shape(i, j int) float64 {
+	return quadraticShape(s.barycentric(i), edges3d10, j)
+}
+
+func (s *Shape3d10) ShapeDxi(i, j int) float64 {
+	return quadraticShapeDerivative(s.barycentric(i), []float64{-1.0, 1.0, 0.0, 0.0}, edges3d10, j)
+}
+
+func (s *Shape3d10) ShapeDeta(i, j int) float64 {
+	return quadraticShapeDerivative(s.barycentric(i), []float64{-1.0, 0.0, 1.0, 0.0}, edges3d10, j)
+}
+
+func (s *Shape3d10) ShapeDpsi(i, j int) float64 {
+	return quadraticShapeDerivative(s.barycentric(i), []float64{-1.0, 0.0, 0.0, 1.0}, edges3d10, j)
+}
+
+func (s *Shape3d10) ShapeDx(i, j int) float64 {
+	return s.gradient[i].At(0, j)
+}
+
+func (s *Shape3d10) ShapeDy(i, j int) float64 {
+	return s.gradient[i].At(1, j)
+}
+
+func (s *Shape3d10) ShapeDz(i, j int) float64 {
+	return s.gradient[i].At(2, j)
+}
+
+// isoShape3D - shape functions of the isoparametric element given by the natural coordinates of its nodes.
+// The derivatives with respect to x, y and z at the nodes are found with the Jacobi matrix at the nodes
+type isoShape3D struct {
+	ShapeData
+	nodes    [][3]float64
+	shape    func(p, node [3]float64) [4]float64
+	gradient []*mat.Dense
+}
+
+func newisoShape3D(x *mat.Dense, nodes [][3]float64, shape func(p, node [3]float64) [4]float64, xi, eta, psi, w []float64) (isoShape3D, error) {
+	var invJacobi mat.Dense
+	s := isoShape3D{ShapeData: ShapeData{x: x, xi: &xi, eta: &eta, psi: &psi, w: &w}, nodes: nodes, shape: shape}
+	s.gradient = make([]*mat.Dense, len(nodes))
+	for i := range nodes {
+		jacobi := mat.NewDense(3, 3, nil)
+		for k := range nodes {
+			n := shape(nodes[i], nodes[k])
+			for j := 0; j < 3; j++ {
+				for l := 0; l < 3; l++ {
+					jacobi.Set(l, j, jacobi.At(l, j)+n[l+1]*x.At(k, j))
+				}
+			}
+		}
+		if err := invJacobi.Inverse(jacobi); err != nil {
+			return s, fmt.Errorf("bad finite element")
+		}
+		s.gradient[i] = mat.NewDense(3, len(nodes), nil)
+		for k := range nodes {
+			n := shape(nodes[i], nodes[k])
+			for l := 0; l < 3; l++ {
+				s.gradient[i].Set(l, k, invJacobi.At(l, 0)*n[1]+invJacobi.At(l, 1)*n[2]+invJacobi.At(l, 2)*n[3])
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *isoShape3D) Size() int {
+	return len(s.nodes)
+}
+
+func (s *isoShape3D) point(i int) [3]float64 {
+	return [3]float64{(*s.xi)[i], (*s.eta)[i], (*s.psi)[i]}
+}
+
+func (s *isoShape3D) Shape(i, j int) float64 {
+	return s.shape(s.point(i), s.nodes[j])[0]
+}
+
+func (s *isoShape3D) ShapeDxi(i, j int) float64 {
+	return s.shape(s.point(i), s.nodes[j])[1]
+}
+
+func (s *isoShape3D) ShapeDeta(i, j int) float64 {
+	return s.shape(s.point(i), s.nodes[j])[2]
+}
+
+func (s *isoShape3D) ShapeDpsi(i, j int) float64 {
+	return s.shape(s.point(i), s.nodes[j])[3]
+}
+
+func (s *isoShape3D) ShapeDx(i, j int) float64 {
+	return s.gradient[i].At(0, j)
+}
+
+func (s *isoShape3D) ShapeDy(i, j int) float64 {
+	return s.gradient[i].At(1, j)
+}
+
+func (s *isoShape3D) ShapeDz(i, j int) float64 {
+	return s.gradient[i].At(2, j)
+}
+
+// gauss3x3x3 - 27-point Gauss quadrature of the cube [-1, 1]^3
+func gauss3x3x3() (xi, eta, psi, w []float64) {
+	x := [3]float64{-0.774596669241, 0.0, 0.774596669241}
+	c := [3]float64{0.555555555556, 0.888888888889, 0.555555555556}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				xi, eta, psi = append(xi, x[i]), append(eta, x[j]), append(psi, x[k])
+				w = append(w, c[i]*c[j]*c[k])
+			}
+		}
+	}
+	return xi, eta, psi, w
+}
+
+// Natural coordinates of the nodes of the 27-node hexahedron in the Gmsh order: the vertices, the mid-side nodes,
+// the centers of the faces and the center. The first 20 nodes form the serendipity hexahedron
+var nodes3d27 = [][3]float64{
+	{-1, -1, -1}, {1, -1, -1}, {1, 1, -1}, {-1, 1, -1}, {-1, -1, 1}, {1, -1, 1}, {1, 1, 1}, {-1, 1, 1},
+	{0, -1, -1}, {-1, 0, -1}, {-1, -1, 0}, {1, 0, -1}, {1, -1, 0}, {0, 1, -1}, {1, 1, 0}, {-1, 1, 0},
+	{0, -1, 1}, {-1, 0, 1}, {1, 0, 1}, {0, 1, 1},
+	{0, 0, -1}, {0, -1, 0}, {-1, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {0, 0, 0},
+}
+
+// serendipity - shape function of the 20-node hexahedron and its derivatives with respect to xi, eta and psi
+func serendipity(p, node [3]float64) [4]float64 {
+	var res [4]float64
+	f := [3]float64{1.0 + p[0]*node[0], 1.0 + p[1]*node[1], 1.0 + p[2]*node[2]}
+	mid := -1
+	for k := 0; k < 3; k++ {
+		if node[k] == 0 {
+			mid = k
+		}
+	}
+	if mid < 0 {
+		s := p[0]*node[0] + p[1]*node[1] + p[2]*node[2] - 2.0
+		res[0] = 0.125 * f[0] * f[1] * f[2] * s
+		for k := 0; k < 3; k++ {
+			res[k+1] = 0.125 * node[k] * f[(k+1)%3] * f[(k+2)%3] * (s + f[k])
+		}
+		return res
+	}
+	f[mid] = 1.0 - p[mid]*p[mid]
+	res[0] = 0.25 * f[0] * f[1] * f[2]
+	for k := 0; k < 3; k++ {
+		d := node[k]
+		if k == mid {
+			d = -2.0 * p[mid]
+		}
+		res[k+1] = 0.25 * d * f[(k+1)%3] * f[(k+2)%3]
+	}
+	return res
+}
+
+// lagrange - shape function of the 27-node hexahedron and its derivatives with respect to xi, eta and psi
+func lagrange(p, node [3]float64) [4]float64 {
+	var l, dl [3]float64
+	for k := 0; k < 3; k++ {
+		switch node[k] {
+		case -1:
+			l[k], dl[k] = 0.5*p[k]*(p[k]-1.0), p[k]-0.5
+		case 0:
+			l[k], dl[k] = 1.0-p[k]*p[k], -2.0*p[k]
+		default:
+			l[k], dl[k] = 0.5*p[k]*(p[k]+1.0), p[k]+0.5
+		}
+	}
+	return [4]float64{l[0] * l[1] * l[2], dl[0] * l[1] * l[2], l[0] * dl[1] * l[2], l[0] * l[1] * dl[2]}
+}
+
+type Shape3d20 struct {
+	isoShape3D
+}
+
+func NewShape3d20(x *mat.Dense) (*Shape3d20, error) {
+	xi, eta, psi, w := gauss3x3x3()
+	s, err := newisoShape3D(x, nodes3d27[:20], serendipity, xi, eta, psi, w)
+	if err != nil {
+		return nil, err
+	}
+	return &Shape3d20{s}, nil
+}
+
+type Shape3d27 struct {
+	isoShape3D
+}
+
+func NewShape3d27(x *mat.Dense) (*Shape3d27, error) {
+	xi, eta, psi, w := gauss3x3x3()
+	s, err := newisoShape3D(x, nodes3d27, lagrange, xi, eta, psi, w)
+	if err != nil {
+		return nil, err
+	}
+	return &Shape3d27{s}, nil
+}
+
+// Natural coordinates of the nodes of the wedge: the triangle (xi, eta) is swept along psi
+var nodes3d6 = [][3]float64{{0, 0, -1}, {1, 0, -1}, {0, 1, -1}, {0, 0, 1}, {1, 0, 1}, {0, 1, 1}}
+
+// wedge - shape function of the 6-node wedge and its derivatives with respect to xi, eta and psi
+func wedge(p, node [3]float64) [4]float64 {
+	l := [3]float64{1.0 - p[0] - p[1], p[0], p[1]}
+	dl := [3][2]float64{{-1.0, -1.0}, {1.0, 0.0}, {0.0, 1.0}}
+	k := 0
+	if node[0] == 1 {
+		k = 1
+	} else if node[1] == 1 {
+		k = 2
+	}
+	h := 0.5 * (1.0 + p[2]*node[2])
+	return [4]float64{l[k] * h, dl[k][0] * h, dl[k][1] * h, 0.5 * l[k] * node[2]}
+}
+
+type Shape3d6 struct {
+	isoShape3D
+}
+
+func NewShape3d6(x *mat.Dense) (*Shape3d6, error) {
+	s, err := newisoShape3D(x, nodes3d6, wedge,
+		[]float64{0.166666666667, 0.666666666667, 0.166666666667, 0.166666666667, 0.666666666667, 0.166666666667},
+		[]float64{0.166666666667, 0.166666666667, 0.666666666667, 0.166666666667, 0.166666666667, 0.666666666667},
+		[]float64{-0.57735026919, -0.57735026919, -0.57735026919, 0.57735026919, 0.57735026919, 0.57735026919},
+		[]float64{0.166666666667, 0.166666666667, 0.166666666667, 0.166666666667, 0.166666666667, 0.166666666667})
+	if err != nil {
+		return nil, err
+	}
+	return &Shape3d6{s}, nil
+}
@@ -0,0 +1,180 @@
+package fe
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const shapeEps = 1.0e-10
+
+// Natural coordinates of the vertices of the triangle, quadrangle, tetrahedron and hexahedron, the nodes of the
+// wedge and of the quadratic hexahedra are nodes3d6 and nodes3d27
+var (
+	vertices2d3 = [][]float64{{0, 0}, {1, 0}, {0, 1}}
+	vertices2d4 = [][]float64{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}
+	vertices3d4 = [][]float64{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	vertices3d8 = [][]float64{{-1, -1, -1}, {1, -1, -1}, {1, 1, -1}, {-1, 1, -1}, {-1, -1, 1}, {1, -1, 1}, {1, 1, 1}, {-1, 1, 1}}
+)
+
+// withMidNodes - the vertices followed by the middles of the edges
+func withMidNodes(vertices [][]float64, edges [][2]int) [][]float64 {
+	res := append([][]float64(nil), vertices...)
+	for _, e := range edges {
+		mid := make([]float64, len(vertices[0]))
+		for k := range mid {
+			mid[k] = 0.5 * (vertices[e[0]][k] + vertices[e[1]][k])
+		}
+		res = append(res, mid)
+	}
+	return res
+}
+
+// natural3D - the natural coordinates of the nodes as the rows
+func natural3D(nodes [][3]float64) [][]float64 {
+	res := make([][]float64, len(nodes))
+	for i, p := range nodes {
+		res[i] = []float64{p[0], p[1], p[2]}
+	}
+	return res
+}
+
+// curvedNodes - the nodes of the element given by the natural coordinates moved by the nonlinear mapping, so the
+// edges of the quadratic elements are curved and the linear elements are distorted
+func curvedNodes(natural [][]float64) *mat.Dense {
+	dim := len(natural[0])
+	x := mat.NewDense(len(natural), dim, nil)
+	for i, p := range natural {
+		if dim == 2 {
+			x.Set(i, 0, 1.0+2.0*p[0]+0.5*p[1]+0.1*p[0]*p[1]+0.05*p[0]*p[0])
+			x.Set(i, 1, 0.5-0.3*p[0]+1.5*p[1]+0.1*p[1]*p[1])
+			continue
+		}
+		x.Set(i, 0, 2.0*p[0]+0.3*p[1]+0.1*p[2]+0.1*p[0]*p[1])
+		x.Set(i, 1, -0.2*p[0]+1.5*p[1]+0.2*p[2]+0.05*p[2]*p[2])
+		x.Set(i, 2, 0.1*p[0]+0.2*p[1]+1.8*p[2]+0.1*p[1]*p[2])
+	}
+	return x
+}
+
+// Gradient of the linear field f = 0.7 + 1.3 x - 0.4 y + 0.9 z
+var linearGradient = []float64{1.3, -0.4, 0.9}
+
+func linearField(x *mat.Dense, i int) float64 {
+	_, dim := x.Dims()
+	res := 0.7
+	for k := 0; k < dim; k++ {
+		res += linearGradient[k] * x.At(i, k)
+	}
+	return res
+}
+
+// checkGradient - the derivatives of the shape functions (rows - x, y, z) applied to the nodal values of the linear
+// field give its gradient
+func checkGradient(t *testing.T, where string, x *mat.Dense, dn func(k, j int) float64) {
+	rows, dim := x.Dims()
+	for k := 0; k < dim; k++ {
+		g := 0.0
+		for j := 0; j < rows; j++ {
+			g += dn(k, j) * linearField(x, j)
+		}
+		if math.Abs(g-linearGradient[k]) > shapeEps {
+			t.Fatalf("derivative %d of the linear field at %s is %g, want %g", k, where, g, linearGradient[k])
+		}
+	}
+}
+
+// checkShape - the shape functions sum to 1 and their derivatives with respect to the natural coordinates sum to 0
+// at the integration points, the derivatives at the integration points and at the nodes reproduce the gradient of
+// the linear field
+func checkShape(t *testing.T, shape ShapeFunction2D, derivatives []func(int, int) float64, gradient func(int) *mat.Dense,
+	nodal func(i, k, j int) float64) {
+	x := shape.X()
+	for i := range *shape.W() {
+		sum := 0.0
+		for j := 0; j < shape.Size(); j++ {
+			sum += shape.Shape(i, j)
+		}
+		if math.Abs(sum-1.0) > shapeEps {
+			t.Fatalf("sum of the shape functions at the point %d is %g", i, sum)
+		}
+		for k, d := range derivatives {
+			sum = 0.0
+			for j := 0; j < shape.Size(); j++ {
+				sum += d(i, j)
+			}
+			if math.Abs(sum) > shapeEps {
+				t.Fatalf("sum of the derivatives %d at the point %d is %g", k, i, sum)
+			}
+		}
+		dn := gradient(i)
+		checkGradient(t, "integration point", x, dn.At)
+	}
+	for i := 0; i < shape.Size(); i++ {
+		checkGradient(t, "node", x, func(k, j int) float64 { return nodal(i, k, j) })
+	}
+}
+
+func TestShapeFunctions2D(t *testing.T) {
+	tests := []struct {
+		name    string
+		natural [][]float64
+		create  func(*mat.Dense) (ShapeFunction2D, error)
+	}{
+		{"fe2d3", vertices2d3, func(x *mat.Dense) (ShapeFunction2D, error) { return NewShape2d3(x) }},
+		{"fe2d4", vertices2d4, func(x *mat.Dense) (ShapeFunction2D, error) { return NewShape2d4(x) }},
+		{"fe2d6", withMidNodes(vertices2d3, edges2d6), func(x *mat.Dense) (ShapeFunction2D, error) { return NewShape2d6(x) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			shape, err := test.create(curvedNodes(test.natural))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if shape.Size() != len(test.natural) {
+				t.Fatalf("size is %d, want %d", shape.Size(), len(test.natural))
+			}
+			checkShape(t, shape, []func(int, int) float64{shape.ShapeDxi, shape.ShapeDeta},
+				func(i int) *mat.Dense {
+					dn, _ := shapeGradient2D(shape, i)
+					return dn
+				},
+				func(i, k, j int) float64 { return [2]float64{shape.ShapeDx(i, j), shape.ShapeDy(i, j)}[k] })
+		})
+	}
+}
+
+func TestShapeFunctions3D(t *testing.T) {
+	tests := []struct {
+		name    string
+		natural [][]float64
+		create  func(*mat.Dense) (ShapeFunction3D, error)
+	}{
+		{"fe3d4", vertices3d4, func(x *mat.Dense) (ShapeFunction3D, error) { return NewShape3d4(x) }},
+		{"fe3d8", vertices3d8, func(x *mat.Dense) (ShapeFunction3D, error) { return NewShape3d8(x) }},
+		{"fe3d10", withMidNodes(vertices3d4, edges3d10), func(x *mat.Dense) (ShapeFunction3D, error) { return NewShape3d10(x) }},
+		{"fe3d6", natural3D(nodes3d6), func(x *mat.Dense) (ShapeFunction3D, error) { return NewShape3d6(x) }},
+		{"fe3d20", natural3D(nodes3d27[:20]), func(x *mat.Dense) (ShapeFunction3D, error) { return NewShape3d20(x) }},
+		{"fe3d27", natural3D(nodes3d27), func(x *mat.Dense) (ShapeFunction3D, error) { return NewShape3d27(x) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			shape, err := test.create(curvedNodes(test.natural))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if shape.Size() != len(test.natural) {
+				t.Fatalf("size is %d, want %d", shape.Size(), len(test.natural))
+			}
+			checkShape(t, shape, []func(int, int) float64{shape.ShapeDxi, shape.ShapeDeta, shape.ShapeDpsi},
+				func(i int) *mat.Dense {
+					dn, _ := shapeGradient3D(shape, i)
+					return dn
+				},
+				func(i, k, j int) float64 {
+					return [3]float64{shape.ShapeDx(i, j), shape.ShapeDy(i, j), shape.ShapeDz(i, j)}[k]
+				})
+		})
+	}
+}
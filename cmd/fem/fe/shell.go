@@ -0,0 +1,132 @@
+package fe
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Natural coordinates of the vertices of the four-node quadrangle
+var corners2d4 = [4][2]float64{{-1.0, -1.0}, {1.0, -1.0}, {1.0, 1.0}, {-1.0, 1.0}}
+
+// bilinear - shape functions of the four-node quadrangle and their derivatives with respect to xi and eta
+func bilinear(xi, eta float64) (n, dxi, deta [4]float64) {
+	for j, c := range corners2d4 {
+		n[j] = 0.25 * (1.0 + c[0]*xi) * (1.0 + c[1]*eta)
+		dxi[j] = 0.25 * c[0] * (1.0 + c[1]*eta)
+		deta[j] = 0.25 * c[1] * (1.0 + c[0]*xi)
+	}
+	return n, dxi, deta
+}
+
+// shapeValues - values of the shape functions at the i-th integration point
+func shapeValues(shape ShapeFunction1D, i int) []float64 {
+	res := make([]float64, shape.Size())
+	for j := range res {
+		res[j] = shape.Shape(i, j)
+	}
+	return res
+}
+
+// lockingFree - the curvature (bp) and transverse shear (bc) gradient matrices of the shell formulation at the
+// point where the linear shape functions are n: DKT replaces the curvatures and has no transverse shear, MITC4
+// replaces the transverse shear strains
+func (f *FiniteElement3DS) lockingFree(n []float64, bp, bc *mat.Dense) (*mat.Dense, *mat.Dense) {
+	switch f.Formulation {
+	case DKT:
+		return f.dktCurvature(n), mat.NewDense(2, f.size*f.freedom, nil)
+	case MITC4:
+		var xi, eta float64
+		for j, c := range corners2d4 {
+			xi += n[j] * c[0]
+			eta += n[j] * c[1]
+		}
+		return bp, f.mitc4Shear(xi, eta)
+	}
+	return bp, bc
+}
+
+// dktCurvature - curvature gradient matrix of the discrete Kirchhoff triangle at the point with the barycentric
+// coordinates l. The rotations of the normal are quadratic, the Kirchhoff hypothesis holds at the vertices and the
+// middle of the edges, where the deflection is cubic and the normal rotation is linear along the edge
+func (f *FiniteElement3DS) dktCurvature(l []float64) *mat.Dense {
+	// Rotations (bx, by) of the vertices and the mid-side nodes of the six-node triangle through the nodal
+	// displacements: bx = ty, by = -tx
+	beta := make([]*mat.Dense, 6)
+	for j := 0; j < 3; j++ {
+		beta[j] = mat.NewDense(2, f.size*f.freedom, nil)
+		beta[j].Set(0, f.freedom*j+4, 1.0)
+		beta[j].Set(1, f.freedom*j+3, -1.0)
+	}
+	for k, e := range edges2d6 {
+		p, q := e[0], e[1]
+		dx, dy := f.X().At(q, 0)-f.X().At(p, 0), f.X().At(q, 1)-f.X().At(p, 1)
+		length := math.Sqrt(dx*dx + dy*dy)
+		s := [2]float64{dx / length, dy / length}
+		nrm := [2]float64{s[1], -s[0]}
+		beta[3+k] = mat.NewDense(2, f.size*f.freedom, nil)
+		for r := 0; r < 2; r++ {
+			// b = -3 / (2 L) (wq - wp) s + (n n^T / 2 - s s^T / 4) (bp + bq)
+			beta[3+k].Set(r, f.freedom*p+2, 1.5*s[r]/length)
+			beta[3+k].Set(r, f.freedom*q+2, -1.5*s[r]/length)
+			for _, j := range e {
+				beta[3+k].Set(r, f.freedom*j+4, 0.5*nrm[r]*nrm[0]-0.25*s[r]*s[0])
+				beta[3+k].Set(r, f.freedom*j+3, -(0.5*nrm[r]*nrm[1] - 0.25*s[r]*s[1]))
+			}
+		}
+	}
+	dlx := []float64{f.ShapeDx(0, 0), f.ShapeDx(0, 1), f.ShapeDx(0, 2)}
+	dly := []float64{f.ShapeDy(0, 0), f.ShapeDy(0, 1), f.ShapeDy(0, 2)}
+	res := mat.NewDense(3, f.size*f.freedom, nil)
+	for j := range beta {
+		dx := quadraticShapeDerivative(l, dlx, edges2d6, j)
+		dy := quadraticShapeDerivative(l, dly, edges2d6, j)
+		for c := 0; c < f.size*f.freedom; c++ {
+			res.Set(0, c, res.At(0, c)+dx*beta[j].At(0, c))
+			res.Set(1, c, res.At(1, c)+dy*beta[j].At(1, c))
+			res.Set(2, c, res.At(2, c)+dy*beta[j].At(0, c)+dx*beta[j].At(1, c))
+		}
+	}
+	return res
+}
+
+// mitc4Shear - transverse shear strain (xz, yz) gradient matrix of the MITC4 quadrangle at the point (xi, eta).
+// The covariant shear strains are interpolated from their values in the middle of the edges
+func (f *FiniteElement3DS) mitc4Shear(xi, eta float64) *mat.Dense {
+	// Row of the covariant shear strain w,k + b * x,k at the point (k = 0 - xi, 1 - eta)
+	covariant := func(xi, eta float64, k int) []float64 {
+		n, dxi, deta := bilinear(xi, eta)
+		dn := [2][4]float64{dxi, deta}[k]
+		var dx, dy float64
+		for j := 0; j < f.size; j++ {
+			dx += dn[j] * f.X().At(j, 0)
+			dy += dn[j] * f.X().At(j, 1)
+		}
+		res := make([]float64, f.size*f.freedom)
+		for j := 0; j < f.size; j++ {
+			res[f.freedom*j+2] = dn[j]
+			res[f.freedom*j+3] = -n[j] * dy
+			res[f.freedom*j+4] = n[j] * dx
+		}
+		return res
+	}
+	gxiA, gxiC := covariant(0.0, -1.0, 0), covariant(0.0, 1.0, 0)
+	getaD, getaB := covariant(-1.0, 0.0, 1), covariant(1.0, 0.0, 1)
+	assumed := mat.NewDense(2, f.size*f.freedom, nil)
+	for c := 0; c < f.size*f.freedom; c++ {
+		assumed.Set(0, c, 0.5*(1.0-eta)*gxiA[c]+0.5*(1.0+eta)*gxiC[c])
+		assumed.Set(1, c, 0.5*(1.0-xi)*getaD[c]+0.5*(1.0+xi)*getaB[c])
+	}
+	// The covariant strains are J * (xz, yz)
+	_, dxi, deta := bilinear(xi, eta)
+	jacobi := mat.NewDense(2, 2, nil)
+	for j := 0; j < f.size; j++ {
+		for k := 0; k < 2; k++ {
+			jacobi.Set(0, k, jacobi.At(0, k)+dxi[j]*f.X().At(j, k))
+			jacobi.Set(1, k, jacobi.At(1, k)+deta[j]*f.X().At(j, k))
+		}
+	}
+	var res mat.Dense
+	_ = res.Solve(jacobi, assumed)
+	return &res
+}
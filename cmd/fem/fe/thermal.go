@@ -0,0 +1,196 @@
+package fe
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type ThermalParameters struct {
+	Conductivity float64
+	Thickness    float64
+}
+
+// ThermalElement - finite element of the steady-state heat conduction problem (one unknown per node)
+type ThermalElement interface {
+	Calculate(*mat.VecDense) *mat.Dense
+	Create() *mat.Dense
+}
+
+type ThermalElement2D struct {
+	ThermalParameters
+	ShapeFunction2D
+}
+
+func NewThermalElement2D(shape ShapeFunction2D, params ThermalParameters) *ThermalElement2D {
+	return &ThermalElement2D{ThermalParameters: params, ShapeFunction2D: shape}
+}
+
+// Create - conductivity matrix
+func (f *ThermalElement2D) Create() *mat.Dense {
+	var k mat.Dense
+	res := mat.NewDense(f.Size(), f.Size(), nil)
+	for i := 0; i < len(*f.W()); i++ {
+		dn, jacobian := shapeGradient2D(f.ShapeFunction2D, i)
+		k.Mul(dn.T(), dn)
+		k.Scale((*f.W())[i]*f.Conductivity*f.Thickness*math.Abs(jacobian), &k)
+		res.Add(res, &k)
+	}
+	return res
+}
+
+// Calculate - heat flux components (rows) at the nodes for the temperatures t of the nodes
+func (f *ThermalElement2D) Calculate(t *mat.VecDense) *mat.Dense {
+	res := mat.NewDense(2, f.Size(), nil)
+	for i := 0; i < f.Size(); i++ {
+		for j := 0; j < f.Size(); j++ {
+			res.Set(0, i, res.At(0, i)-f.Conductivity*f.ShapeDx(i, j)*t.AtVec(j))
+			res.Set(1, i, res.At(1, i)-f.Conductivity*f.ShapeDy(i, j)*t.AtVec(j))
+		}
+	}
+	return res
+}
+
+type ThermalElement3D struct {
+	ThermalParameters
+	ShapeFunction3D
+}
+
+func NewThermalElement3D(shape ShapeFunction3D, params ThermalParameters) *ThermalElement3D {
+	return &ThermalElement3D{ThermalParameters: params, ShapeFunction3D: shape}
+}
+
+// Create - conductivity matrix
+func (f *ThermalElement3D) Create() *mat.Dense {
+	var k mat.Dense
+	res := mat.NewDense(f.Size(), f.Size(), nil)
+	for i := 0; i < len(*f.W()); i++ {
+		dn, jacobian := shapeGradient3D(f.ShapeFunction3D, i)
+		k.Mul(dn.T(), dn)
+		k.Scale((*f.W())[i]*f.Conductivity*math.Abs(jacobian), &k)
+		res.Add(res, &k)
+	}
+	return res
+}
+
+// Calculate - heat flux components (rows) at the nodes for the temperatures t of the nodes
+func (f *ThermalElement3D) Calculate(t *mat.VecDense) *mat.Dense {
+	res := mat.NewDense(3, f.Size(), nil)
+	for i := 0; i < f.Size(); i++ {
+		for j := 0; j < f.Size(); j++ {
+			res.Set(0, i, res.At(0, i)-f.Conductivity*f.ShapeDx(i, j)*t.AtVec(j))
+			res.Set(1, i, res.At(1, i)-f.Conductivity*f.ShapeDy(i, j)*t.AtVec(j))
+			res.Set(2, i, res.At(2, i)-f.Conductivity*f.ShapeDz(i, j)*t.AtVec(j))
+		}
+	}
+	return res
+}
+
+// FaceQuadrature - integration points of the boundary element with the node coordinates x (rows): the values of the
+// shape functions at the points (rows) and the weights multiplied by the Jacobian of the boundary. The boundary of the
+// 2D element is the line with two or three nodes, of the solid - the triangle with three or six nodes or the
+// quadrangle with four, eight or nine nodes, the mid-side nodes follow the vertices (in the Gmsh order)
+func FaceQuadrature(x *mat.Dense) (*mat.Dense, []float64) {
+	size, dim := x.Dims()
+	if dim == 3 && size == 4 && mat.Equal(x.RowView(2), x.RowView(3)) {
+		// The triangle stored as the quadrangle with the repeated last node
+		shape, w := FaceQuadrature(x.Slice(0, 3, 0, 3).(*mat.Dense))
+		res := mat.NewDense(len(w), 4, nil)
+		res.Slice(0, len(w), 0, 3).(*mat.Dense).Copy(shape)
+		return res, w
+	}
+	var xi, eta, w []float64
+	// Shape functions and their derivatives by the local coordinates at the point
+	var shape func(s, t float64) (n, dxi, deta []float64)
+	switch {
+	case dim == 2:
+		xi, w = gaussRule(size)
+		eta = make([]float64, len(xi))
+		shape = func(s, _ float64) ([]float64, []float64, []float64) {
+			if size == 2 {
+				return []float64{0.5 * (1.0 - s), 0.5 * (1.0 + s)}, []float64{-0.5, 0.5}, nil
+			}
+			return []float64{0.5 * s * (s - 1.0), 0.5 * s * (s + 1.0), 1.0 - s*s}, []float64{s - 0.5, s + 0.5, -2.0 * s}, nil
+		}
+	case size == 3 || size == 6:
+		a, b := 0.445948490915965, 0.091576213509771
+		xi = []float64{a, 1.0 - 2.0*a, a, b, 1.0 - 2.0*b, b}
+		eta = []float64{a, a, 1.0 - 2.0*a, b, b, 1.0 - 2.0*b}
+		w = []float64{0.111690794839005, 0.111690794839005, 0.111690794839005, 0.054975871827661, 0.054975871827661, 0.054975871827661}
+		shape = func(s, t float64) ([]float64, []float64, []float64) {
+			l := []float64{1.0 - s - t, s, t}
+			if size == 3 {
+				return l, []float64{-1.0, 1.0, 0.0}, []float64{-1.0, 0.0, 1.0}
+			}
+			n, dxi, deta := make([]float64, size), make([]float64, size), make([]float64, size)
+			for j := 0; j < size; j++ {
+				n[j] = quadraticShape(l, edges2d6, j)
+				dxi[j] = quadraticShapeDerivative(l, []float64{-1.0, 1.0, 0.0}, edges2d6, j)
+				deta[j] = quadraticShapeDerivative(l, []float64{-1.0, 0.0, 1.0}, edges2d6, j)
+			}
+			return n, dxi, deta
+		}
+	default:
+		// The 8- and 9-node quadrangles are the faces psi = 1 of the 20- and 27-node hexahedra
+		g, gw := gaussRule(2)
+		if size > 4 {
+			g, gw = gaussRule(3)
+		}
+		for i := range g {
+			for j := range g {
+				xi, eta, w = append(xi, g[i]), append(eta, g[j]), append(w, gw[i]*gw[j])
+			}
+		}
+		nodes := [][2]float64{{-1.0, -1.0}, {1.0, -1.0}, {1.0, 1.0}, {-1.0, 1.0}, {0.0, -1.0}, {1.0, 0.0}, {0.0, 1.0}, {-1.0, 0.0}, {0.0, 0.0}}
+		shape = func(s, t float64) ([]float64, []float64, []float64) {
+			n, dxi, deta := make([]float64, size), make([]float64, size), make([]float64, size)
+			for j, c := range nodes[:size] {
+				switch size {
+				case 4:
+					n[j] = 0.25 * (1.0 + s*c[0]) * (1.0 + t*c[1])
+					dxi[j] = 0.25 * c[0] * (1.0 + t*c[1])
+					deta[j] = 0.25 * c[1] * (1.0 + s*c[0])
+				case 8:
+					f := serendipity([3]float64{s, t, 1.0}, [3]float64{c[0], c[1], 1.0})
+					n[j], dxi[j], deta[j] = f[0], f[1], f[2]
+				default:
+					f := lagrange([3]float64{s, t, 1.0}, [3]float64{c[0], c[1], 1.0})
+					n[j], dxi[j], deta[j] = f[0], f[1], f[2]
+				}
+			}
+			return n, dxi, deta
+		}
+	}
+	res := mat.NewDense(len(w), size, nil)
+	weight := make([]float64, len(w))
+	for i := range w {
+		n, dxi, deta := shape(xi[i], eta[i])
+		res.SetRow(i, n)
+		// Derivatives of the position vector by the local coordinates
+		var a, b [3]float64
+		for j := 0; j < size; j++ {
+			for k := 0; k < dim; k++ {
+				a[k] += dxi[j] * x.At(j, k)
+				if deta != nil {
+					b[k] += deta[j] * x.At(j, k)
+				}
+			}
+		}
+		if dim == 2 {
+			weight[i] = w[i] * math.Hypot(a[0], a[1])
+		} else {
+			weight[i] = w[i] * math.Sqrt(math.Pow(a[1]*b[2]-a[2]*b[1], 2)+math.Pow(a[2]*b[0]-a[0]*b[2], 2)+math.Pow(a[0]*b[1]-a[1]*b[0], 2))
+		}
+	}
+	return res, weight
+}
+
+// gaussRule - abscissas and weights of the Gauss rule with two or three points on [-1, 1]
+func gaussRule(n int) ([]float64, []float64) {
+	if n == 2 {
+		g := 1.0 / math.Sqrt(3.0)
+		return []float64{-g, g}, []float64{1.0, 1.0}
+	}
+	g := math.Sqrt(0.6)
+	return []float64{-g, 0.0, g}, []float64{5.0 / 9.0, 8.0 / 9.0, 5.0 / 9.0}
+}
@@ -0,0 +1,89 @@
+package fe
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// The face matrix of N' N is area / 6 [2 1; 1 2] for the line and area / 12 (1 + delta_ij) for the triangle, the
+// matrices of the quadratic line and triangle are the same as of their mass matrices
+func TestFaceQuadrature(t *testing.T) {
+	tests := []struct {
+		name string
+		x    *mat.Dense
+		want *mat.Dense
+	}{
+		{"line", mat.NewDense(2, 2, []float64{1, 1, 4, 5}),
+			mat.NewDense(2, 2, []float64{5.0 / 3.0, 5.0 / 6.0, 5.0 / 6.0, 5.0 / 3.0})},
+		{"triangle", mat.NewDense(3, 3, []float64{0, 0, 1, 2, 0, 1, 0, 3, 1}),
+			mat.NewDense(3, 3, []float64{0.5, 0.25, 0.25, 0.25, 0.5, 0.25, 0.25, 0.25, 0.5})},
+		{"quadratic line", mat.NewDense(3, 2, []float64{1, 1, 4, 5, 2.5, 3}),
+			scaled(1.0/6.0, 3, []float64{4, -1, 2, -1, 4, 2, 2, 2, 16})},
+		{"quadratic triangle", mat.NewDense(6, 3, []float64{0, 0, 1, 2, 0, 1, 0, 3, 1, 1, 0, 1, 1, 1.5, 1, 0, 1.5, 1}),
+			scaled(1.0/60.0, 6, []float64{
+				6, -1, -1, 0, -4, 0,
+				-1, 6, -1, 0, 0, -4,
+				-1, -1, 6, -4, 0, 0,
+				0, 0, -4, 32, 16, 16,
+				-4, 0, 0, 16, 32, 16,
+				0, -4, 0, 16, 16, 32,
+			})},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			shape, w := FaceQuadrature(test.x)
+			size, _ := test.want.Dims()
+			for i := 0; i < size; i++ {
+				for j := 0; j < size; j++ {
+					got := 0.0
+					for p := range w {
+						got += w[p] * shape.At(p, i) * shape.At(p, j)
+					}
+					if math.Abs(got-test.want.At(i, j)) > 1.0e-12 {
+						t.Fatalf("entry (%d, %d) is %g, want %g", i, j, got, test.want.At(i, j))
+					}
+				}
+			}
+		})
+	}
+	// The integrals of N over the quadrangles are the shares of their areas
+	square := []float64{0, 0, 0, 2, 0, 0, 2, 2, 0, 0, 2, 0, 1, 0, 0, 2, 1, 0, 1, 2, 0, 0, 1, 0, 1, 1, 0}
+	shares := []struct {
+		name  string
+		x     *mat.Dense
+		area  float64
+		share []float64
+	}{
+		{"quadrangle", mat.NewDense(4, 3, []float64{0, 0, 0, 2, 0, 2, 3, 1, 2, 1, 1, 0}), math.Sqrt(12.0),
+			[]float64{0.25, 0.25, 0.25, 0.25}},
+		{"serendipity quadrangle", mat.NewDense(8, 3, square[:24]), 4.0,
+			[]float64{-1.0 / 12.0, -1.0 / 12.0, -1.0 / 12.0, -1.0 / 12.0, 1.0 / 3.0, 1.0 / 3.0, 1.0 / 3.0, 1.0 / 3.0}},
+		{"Lagrange quadrangle", mat.NewDense(9, 3, square), 4.0,
+			[]float64{1.0 / 36.0, 1.0 / 36.0, 1.0 / 36.0, 1.0 / 36.0, 1.0 / 9.0, 1.0 / 9.0, 1.0 / 9.0, 1.0 / 9.0, 4.0 / 9.0}},
+		{"degenerate quadrangle", mat.NewDense(4, 3, []float64{0, 0, 1, 2, 0, 1, 0, 3, 1, 0, 3, 1}), 3.0,
+			[]float64{1.0 / 3.0, 1.0 / 3.0, 1.0 / 3.0, 0.0}},
+	}
+	for _, test := range shares {
+		t.Run(test.name, func(t *testing.T) {
+			shape, w := FaceQuadrature(test.x)
+			for i := range test.share {
+				got := 0.0
+				for p := range w {
+					got += w[p] * shape.At(p, i)
+				}
+				if math.Abs(got-test.share[i]*test.area) > 1.0e-12 {
+					t.Fatalf("integral of N%d is %g, want %g", i, got, test.share[i]*test.area)
+				}
+			}
+		})
+	}
+}
+
+// scaled - the size x size matrix data multiplied by factor
+func scaled(factor float64, size int, data []float64) *mat.Dense {
+	res := mat.NewDense(size, size, data)
+	res.Scale(factor, res)
+	return res
+}
@@ -0,0 +1,166 @@
+package fem
+
+import (
+	"fmt"
+	"math"
+	"time"
+	"wfem/cmd/fem/fe"
+	"wfem/cmd/fem/solver"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// BucklingFEM - linear buckling analysis: the static solution under the given loads and the lowest load factors
+// lambda of the problem (K + lambda Kg) x = 0, Kg - the geometric stiffness matrix built from the element stresses
+type BucklingFEM struct {
+	StaticFEM
+	numModes   int
+	loadFactor []float64
+}
+
+func NewBucklingFEM() BucklingFEM {
+	return BucklingFEM{StaticFEM: NewStaticFEM(), numModes: 5}
+}
+
+// SetNumModes - number of the buckling modes to be found
+func (fem *BucklingFEM) SetNumModes(num int) {
+	fem.numModes = num
+}
+
+// GetLoadFactor - critical load factors in ascending order
+func (fem *BucklingFEM) GetLoadFactor() []float64 {
+	return fem.loadFactor
+}
+
+func (fem *BucklingFEM) Calculate() error {
+	var err error
+	fmt.Printf("Using threads: %d\n", fem.params.NumThread)
+	start := time.Now()
+	if err = fem.checkSparseSolver("buckling"); err != nil {
+		return err
+	}
+	if err = fem.checkSets(); err != nil {
+		return err
+	}
+	if err = fem.setDiscrete(); err != nil {
+		return err
+	}
+	fem.mesh.Renumber()
+	fem.res = nil
+	defer func() {
+		if fem.res != nil {
+			fem.mesh.RestoreNumbering(fem.res)
+		} else {
+			fem.mesh.RestoreNumbering()
+		}
+	}()
+	// Static solution, the stiffness matrix factorized by the sparse solver is used by the subspace iteration
+	stiffness := solver.NewSparseSolver(&fem.mesh, fem.mesh.Freedom())
+	fem.solver = stiffness
+	if err = fem.calcGlobalMatrix(); err != nil {
+		return err
+	}
+	if err = fem.addPointLoad(); err != nil {
+		return err
+	}
+	if err = fem.addVolumeLoad(); err != nil {
+		return err
+	}
+	if err = fem.addSurfaceLoad(); err != nil {
+		return err
+	}
+	if err = fem.addThermalLoad(); err != nil {
+		return err
+	}
+	if err = fem.addBoundaryCondition(stiffness.SetBoundaryCondition); err != nil {
+		return err
+	}
+	u, err := stiffness.Solve()
+	if err != nil {
+		return err
+	}
+	if err = fem.calcResult(u); err != nil {
+		return err
+	}
+	geometric := solver.NewSparseSolver(&fem.mesh, fem.mesh.Freedom())
+	if err = fem.assemble(geometric, fem.mesh.Freedom(), "Building a global geometric stiffness matrix", fem.feMatrix(func(index int, elm fe.FiniteElement) *mat.Dense {
+		return elm.Geometric(fem.feDisplacement(index, u))
+	})); err != nil {
+		return err
+	}
+	if err = fem.addBoundaryCondition(func(index int, _ float64) {
+		geometric.SetBoundaryCondition(index, 0)
+		geometric.SetMatrix(index, index, 0)
+	}); err != nil {
+		return err
+	}
+	// -Kg x = mu K x, lambda = 1 / mu
+	mu, x, iterations, err := solver.Subspace(stiffness, func(x, y []float64) {
+		geometric.MulVec(x, y)
+		floats.Scale(-1.0, y)
+	}, fem.numModes, subspaceEps, maxSubspaceIterations)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Iterations: %d\n", iterations)
+	fem.loadFactor = nil
+	for i := range mu {
+		if mu[i] > 0 {
+			fem.loadFactor = append(fem.loadFactor, 1.0/mu[i])
+		}
+	}
+	if len(fem.loadFactor) == 0 {
+		return fmt.Errorf("the load does not cause buckling")
+	}
+	// The buckling modes follow the static results, each mode is scaled to the unit maximum displacement
+	freedom := fem.mesh.Freedom()
+	static := fem.res
+	rows, cols := static.Dims()
+	fem.res = mat.NewDense(rows+len(fem.loadFactor)*freedom, cols, nil)
+	fem.res.Slice(0, rows, 0, cols).(*mat.Dense).Copy(static)
+	for i := range fem.loadFactor {
+		mode := mat.Col(nil, i, x)
+		scale := 1.0 / math.Max(math.Abs(floats.Max(mode)), math.Abs(floats.Min(mode)))
+		for j := 0; j < fem.mesh.NumVertex(); j++ {
+			for k := 0; k < freedom; k++ {
+				fem.res.Set(rows+i*freedom+k, j, scale*mode[j*freedom+k])
+			}
+		}
+	}
+	fem.printSummary()
+	duration := time.Since(start)
+	fmt.Printf("Lead time: %0.2f sec\n\n", duration.Seconds())
+	return nil
+}
+
+func (fem *BucklingFEM) printSummary() {
+	fem.StaticFEM.printSummary()
+	fmt.Println("----------------------------------------------")
+	fmt.Println("Mode\tLoad factor")
+	for i, f := range fem.loadFactor {
+		fmt.Printf("%d\t%e\n", i+1, f)
+	}
+}
+
+// ResultNames - names of the static results followed by the names of the buckling mode components
+func (fem *BucklingFEM) ResultNames() *[]string {
+	res := *fem.StaticFEM.ResultNames()
+	names := res[:fem.mesh.Freedom()]
+	for range fem.loadFactor {
+		res = append(res, names...)
+	}
+	return &res
+}
+
+// SaveResult - the buckling modes are saved with the load factor instead of the time
+func (fem *BucklingFEM) SaveResult(name string) error {
+	freedom := fem.mesh.Freedom()
+	times := make([]float64, fem.numResult()+len(fem.loadFactor)*freedom)
+	for i := range fem.loadFactor {
+		for k := 0; k < freedom; k++ {
+			times[fem.numResult()+i*freedom+k] = fem.loadFactor[i]
+		}
+	}
+	return fem.saveResult(name, *fem.ResultNames(), times)
+}
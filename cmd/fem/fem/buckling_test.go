@@ -0,0 +1,74 @@
+package fem
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"wfem/cmd/fem/params"
+)
+
+// writeColumn - MESH-file of the rectangle [0, length] x [0, height] divided into nx x ny quadrangles
+func writeColumn(t *testing.T, length, height float64, nx, ny int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "fe2d4\n%d\n", (nx+1)*(ny+1))
+	for j := 0; j <= ny; j++ {
+		for i := 0; i <= nx; i++ {
+			fmt.Fprintf(&b, "%g %g\n", length*float64(i)/float64(nx), height*float64(j)/float64(ny))
+		}
+	}
+	fmt.Fprintf(&b, "%d\n", nx*ny)
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			k := j*(nx+1) + i
+			fmt.Fprintf(&b, "%d %d %d %d\n", k, k+1, k+nx+2, k+nx+1)
+		}
+	}
+	b.WriteString("0\n")
+	name := filepath.Join(t.TempDir(), "column.mesh")
+	if err := os.WriteFile(name, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+// The critical load of the column fixed at one end and compressed at the other is pi^2 E I / (4 L^2)
+func TestBucklingEulerColumn(t *testing.T) {
+	const e, length, height, thickness = 2.0e+5, 10.0, 1.0, 0.1
+	const ny = 4
+	f := NewBucklingFEM()
+	if err := f.SetMesh(writeColumn(t, length, height, 160, ny)); err != nil {
+		t.Fatal(err)
+	}
+	f.SetNumModes(2)
+	f.AddYoungModulus(fmt.Sprint(e), "")
+	f.AddPoissonRatio("0", "")
+	f.AddThickness(fmt.Sprint(thickness), "")
+	f.AddBoundaryCondition("0", "x == 0", params.X|params.Y)
+	// The unit compressive force is shared by the nodes of the free end
+	f.AddPointLoad(fmt.Sprint(-1.0/(ny+1)), fmt.Sprintf("x == %g", length), params.X)
+	if err := f.Calculate(); err != nil {
+		t.Fatal(err)
+	}
+	want := math.Pi * math.Pi * e * thickness * math.Pow(height, 3) / 12.0 / (4.0 * length * length)
+	if got := f.GetLoadFactor()[0]; math.Abs(got-want) > 0.01*want {
+		t.Fatalf("critical load factor is %g, want %g", got, want)
+	}
+}
+
+func TestBucklingRejectsOtherSolvers(t *testing.T) {
+	f := NewBucklingFEM()
+	if err := f.SetMesh(writeColumn(t, 1.0, 0.1, 4, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetSolver("dense"); err != nil {
+		t.Fatal(err)
+	}
+	f.AddYoungModulus("1", "")
+	f.AddPointLoad("-1", "x == 1", params.X)
+	if err := f.Calculate(); err == nil {
+		t.Fatal("no error for the dense solver")
+	}
+}
@@ -0,0 +1,269 @@
+package fem
+
+import (
+	"fmt"
+	"math"
+	"wfem/cmd/fem/fe"
+	"wfem/cmd/fem/mesh"
+	"wfem/cmd/fem/params"
+	"wfem/cmd/fem/solver"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Stiffness of the rigid links relative to the largest diagonal entry of the global matrix at their unknowns
+const rigidPenalty = 1.0e+6
+
+// discrete - the springs, point masses and rigid links given by the predicates (the value of the parameter is the
+// stiffness or the mass in its directions) and by the node numbers (the original numbering)
+type discrete struct {
+	springs     []params.Parameter
+	masses      []params.Parameter
+	links       []rigidLink
+	nodeSprings []mesh.Spring
+	nodeMasses  []mesh.PointMass
+	nodeLinks   []mesh.RigidLink
+}
+
+type rigidLink struct {
+	master, slaves params.Parameter
+}
+
+// setDiscrete - the discrete elements of the mesh, it is called before the renumbering of the nodes
+func (fem *StaticFEM) setDiscrete() error {
+	m := &fem.mesh
+	coupled := len(m.Springs)+len(m.RigidLinks) > 0
+	m.Springs = append([]mesh.Spring(nil), fem.discrete.nodeSprings...)
+	m.Masses = append([]mesh.PointMass(nil), fem.discrete.nodeMasses...)
+	m.RigidLinks = nil
+	for _, link := range fem.discrete.nodeLinks {
+		m.RigidLinks = append(m.RigidLinks, mesh.RigidLink{Master: link.Master, Slaves: append([]int(nil), link.Slaves...)})
+	}
+	for _, s := range m.Springs {
+		if !fem.isNode(s.Node1) || (s.Node2 >= 0 && !fem.isNode(s.Node2)) {
+			return fmt.Errorf("wrong node of the spring")
+		}
+	}
+	for _, p := range m.Masses {
+		if !fem.isNode(p.Node) {
+			return fmt.Errorf("wrong node of the point mass")
+		}
+	}
+	for _, link := range m.RigidLinks {
+		for _, node := range link.Nodes() {
+			if !fem.isNode(node) {
+				return fmt.Errorf("wrong node of the rigid link")
+			}
+		}
+	}
+	// The elements given by the predicates
+	for _, p := range fem.discrete.springs {
+		err := fem.selectNodes(p, func(node int, value [6]float64) {
+			m.Springs = append(m.Springs, mesh.Spring{Node1: node, Node2: -1, Stiffness: value})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, p := range fem.discrete.masses {
+		err := fem.selectNodes(p, func(node int, value [6]float64) {
+			m.Masses = append(m.Masses, mesh.PointMass{Node: node, Mass: value})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, link := range fem.discrete.links {
+		var master, slaves []int
+		if err := fem.selectNodes(link.master, func(node int, _ [6]float64) {
+			master = append(master, node)
+		}); err != nil {
+			return err
+		}
+		if len(master) != 1 {
+			return fmt.Errorf("the master node of the rigid link is not unique")
+		}
+		if err := fem.selectNodes(link.slaves, func(node int, _ [6]float64) {
+			if node != master[0] {
+				slaves = append(slaves, node)
+			}
+		}); err != nil {
+			return err
+		}
+		if len(slaves) == 0 {
+			return fmt.Errorf("the rigid link has no slave nodes")
+		}
+		m.RigidLinks = append(m.RigidLinks, mesh.RigidLink{Master: master[0], Slaves: slaves})
+	}
+	if coupled || len(m.Springs)+len(m.RigidLinks) > 0 {
+		m.CreateMeshMap()
+	}
+	return nil
+}
+
+func (fem *StaticFEM) isNode(index int) bool {
+	return index >= 0 && index < fem.mesh.NumVertex()
+}
+
+// selectNodes - fun is called for the nodes of the set satisfying the predicate of the parameter with its value in the
+// directions of the parameter
+func (fem *StaticFEM) selectNodes(p params.Parameter, fun func(int, [6]float64)) error {
+	direct := [6]int{params.X, params.Y, params.Z, params.RX, params.RY, params.RZ}
+	for i := range fem.mesh.X {
+		if !fem.inSet(&p, mesh.SetNode, i) {
+			continue
+		}
+		x := mat.NewVecDense(fem.mesh.FeDim(), fem.mesh.X[i])
+		ok, err := p.GetPredicate(x, &fem.params.Variables)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		var value [6]float64
+		if len(p.Value) > 0 {
+			v, err := p.GetValue(x, &fem.params.Variables)
+			if err != nil {
+				return err
+			}
+			for k := range direct {
+				if p.Direct&direct[k] == direct[k] {
+					value[k] = v
+				}
+			}
+		}
+		fun(i, value)
+	}
+	return nil
+}
+
+// addSprings - adding the stiffness of the springs to the global matrix
+func (fem *StaticFEM) addSprings(global solver.Solver) {
+	freedom := fem.mesh.Freedom()
+	for _, s := range fem.mesh.Springs {
+		for l, k := range fem.dofDirections(freedom) {
+			if s.Stiffness[k] == 0 {
+				continue
+			}
+			i := s.Node1*freedom + l
+			global.AddMatrix(i, i, s.Stiffness[k])
+			if s.Node2 >= 0 {
+				j := s.Node2*freedom + l
+				global.AddMatrix(j, j, s.Stiffness[k])
+				global.AddMatrix(i, j, -s.Stiffness[k])
+				global.AddMatrix(j, i, -s.Stiffness[k])
+			}
+		}
+	}
+}
+
+// addPointMasses - adding the point masses to the global mass matrix
+func (fem *StaticFEM) addPointMasses(global solver.Solver) {
+	freedom := fem.mesh.Freedom()
+	for _, p := range fem.mesh.Masses {
+		for l, k := range fem.dofDirections(freedom) {
+			if p.Mass[k] != 0 {
+				global.AddMatrix(p.Node*freedom+l, p.Node*freedom+l, p.Mass[k])
+			}
+		}
+	}
+}
+
+// addRigidLinks - the rigid links are applied by the penalty method: the stiffness P (I - Q Q^T) vanishes only for
+// the rigid body motions of the nodes of the link (the orthonormal columns of Q)
+func (fem *StaticFEM) addRigidLinks(global solver.Solver) {
+	freedom := fem.mesh.Freedom()
+	for i := range fem.mesh.RigidLinks {
+		nodes := fem.mesh.RigidLinks[i].Nodes()
+		index := make([]int, 0, len(nodes)*freedom)
+		for _, node := range nodes {
+			for l := 0; l < freedom; l++ {
+				index = append(index, node*freedom+l)
+			}
+		}
+		var penalty float64
+		for _, j := range index {
+			penalty = math.Max(penalty, math.Abs(global.GetMatrix(j, j)))
+		}
+		if penalty == 0 {
+			penalty = 1.0
+		}
+		penalty *= rigidPenalty
+		q := fem.rigidModes(nodes)
+		var k mat.Dense
+		k.Mul(q, q.T())
+		for a := range index {
+			for b := range index {
+				value := -k.At(a, b)
+				if a == b {
+					value += 1.0
+				}
+				if value != 0 {
+					global.AddMatrix(index[a], index[b], penalty*value)
+				}
+			}
+		}
+	}
+}
+
+// rigidModes - orthonormal basis (columns) of the rigid body motions of the nodes: the translations and the
+// rotations about the center of the nodes restricted to their degrees of freedom. The body of revolution of the
+// axisymmetric problem can only move along its axis
+func (fem *StaticFEM) rigidModes(nodes []int) *mat.Dense {
+	freedom := fem.mesh.Freedom()
+	dof := fem.dofDirections(freedom)
+	var center [3]float64
+	for _, node := range nodes {
+		for j := 0; j < fem.mesh.FeDim(); j++ {
+			center[j] += fem.mesh.X[node][j] / float64(len(nodes))
+		}
+	}
+	// Translations along X, Y, Z (0, 1, 2) and rotations about them (3, 4, 5)
+	candidates := []int{0, 1, 2, 3, 4, 5}
+	if fem.mesh.Is2D() && fem.mode2D == fe.Axisymmetric {
+		candidates = []int{1}
+	}
+	var basis []*mat.VecDense
+	for _, c := range candidates {
+		v := mat.NewVecDense(len(nodes)*freedom, nil)
+		for a, node := range nodes {
+			var r [3]float64
+			for j := 0; j < fem.mesh.FeDim(); j++ {
+				r[j] = fem.mesh.X[node][j] - center[j]
+			}
+			for l, d := range dof {
+				switch {
+				case c < 3 && d == c:
+					v.SetVec(a*freedom+l, 1.0)
+				case c >= 3 && d < 3:
+					// Displacement e x r of the rotation about the axis e
+					var e [3]float64
+					e[c-3] = 1.0
+					u := [3]float64{e[1]*r[2] - e[2]*r[1], e[2]*r[0] - e[0]*r[2], e[0]*r[1] - e[1]*r[0]}
+					v.SetVec(a*freedom+l, u[d])
+				case c >= 3 && d == c:
+					v.SetVec(a*freedom+l, 1.0)
+				}
+			}
+		}
+		// Gram-Schmidt, the motions the nodes cannot have are dropped
+		norm := v.Norm(2)
+		if norm == 0 {
+			continue
+		}
+		for _, q := range basis {
+			v.AddScaledVec(v, -mat.Dot(v, q), q)
+		}
+		if v.Norm(2) < 1.0e-10*norm {
+			continue
+		}
+		v.ScaleVec(1.0/v.Norm(2), v)
+		basis = append(basis, v)
+	}
+	res := mat.NewDense(len(nodes)*freedom, len(basis), nil)
+	for j, q := range basis {
+		res.SetCol(j, q.RawVector().Data)
+	}
+	return res
+}
@@ -3,6 +3,7 @@ package fem
 import (
 	"gonum.org/v1/gonum/mat"
 	"wfem/cmd/fem/mesh"
+	"wfem/cmd/fem/solver"
 )
 
 type FiniteElementMethod interface {
@@ -19,6 +20,7 @@ type FiniteElementMethod interface {
 	AddVariable(string, float64)
 	SetNumTread(int)
 	SetEps(float64)
+	SetSolver(string, ...solver.Option) error
 	SaveResult(string) error
 	GetResult() *mat.Dense
 	GetResultNames() *[]string
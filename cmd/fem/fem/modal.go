@@ -0,0 +1,150 @@
+package fem
+
+import (
+	"fmt"
+	"math"
+	"time"
+	"wfem/cmd/fem/fe"
+	"wfem/cmd/fem/params"
+	"wfem/cmd/fem/solver"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Parameters of the subspace iteration: the relative accuracy of the eigenvalues and the iteration limit
+const (
+	subspaceEps           = 1.0e-6
+	maxSubspaceIterations = 200
+)
+
+// ModalFEM - natural frequencies and mode shapes of the structure, the lowest modes are found by the subspace
+// iteration method with the stiffness matrix factorized by the sparse solver (the other solvers are not supported)
+type ModalFEM struct {
+	StaticFEM
+	numModes  int
+	isLumped  bool
+	frequency []float64
+}
+
+func NewModalFEM() ModalFEM {
+	return ModalFEM{StaticFEM: NewStaticFEM(), numModes: 5}
+}
+
+// SetNumModes - number of the lowest modes to be found
+func (fem *ModalFEM) SetNumModes(num int) {
+	fem.numModes = num
+}
+
+// SetLumpedMass - using the diagonal (lumped) mass matrices instead of the consistent ones
+func (fem *ModalFEM) SetLumpedMass(isLumped bool) {
+	fem.isLumped = isLumped
+}
+
+// GetFrequency - natural frequencies (Hz) in ascending order
+func (fem *ModalFEM) GetFrequency() []float64 {
+	return fem.frequency
+}
+
+func (fem *ModalFEM) Calculate() error {
+	var err error
+	fmt.Printf("Using threads: %d\n", fem.params.NumThread)
+	start := time.Now()
+	if err = fem.checkSparseSolver("modal"); err != nil {
+		return err
+	}
+	if !fem.params.FindParameter(params.Density) {
+		return fmt.Errorf("density is not defined")
+	}
+	if err = fem.checkSets(); err != nil {
+		return err
+	}
+	if err = fem.setDiscrete(); err != nil {
+		return err
+	}
+	fem.mesh.Renumber()
+	fem.res = nil
+	defer func() {
+		if fem.res != nil {
+			fem.mesh.RestoreNumbering(fem.res)
+		} else {
+			fem.mesh.RestoreNumbering()
+		}
+	}()
+	stiffness := solver.NewSparseSolver(&fem.mesh, fem.mesh.Freedom())
+	mass := solver.NewSparseSolver(&fem.mesh, fem.mesh.Freedom())
+	fem.solver = stiffness
+	if err = fem.calcGlobalMatrix(); err != nil {
+		return err
+	}
+	if err = fem.assemble(mass, fem.mesh.Freedom(), "Building a global mass matrix", fem.feMatrix(func(_ int, elm fe.FiniteElement) *mat.Dense {
+		return elm.Mass(fem.isLumped)
+	})); err != nil {
+		return err
+	}
+	fem.addPointMasses(mass)
+	// The constrained unknowns are excluded from both matrices, the prescribed values are ignored
+	if err = fem.addBoundaryCondition(func(index int, _ float64) {
+		stiffness.SetBoundaryCondition(index, 0)
+		mass.SetBoundaryCondition(index, 0)
+		mass.SetMatrix(index, index, 0)
+	}); err != nil {
+		return err
+	}
+	if err = stiffness.Factorize(); err != nil {
+		return err
+	}
+	// M x = mu K x, mu = 1 / omega^2
+	mu, x, iterations, err := solver.Subspace(stiffness, mass.MulVec, fem.numModes, subspaceEps, maxSubspaceIterations)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Iterations: %d\n", iterations)
+	freedom := fem.mesh.Freedom()
+	fem.frequency = make([]float64, len(mu))
+	fem.res = mat.NewDense(len(mu)*freedom, fem.mesh.NumVertex(), nil)
+	for i := range mu {
+		if mu[i] <= 0 {
+			return fmt.Errorf("the mass matrix is singular")
+		}
+		fem.frequency[i] = 1.0 / math.Sqrt(mu[i]) / (2.0 * math.Pi)
+		// Mass normalized mode shape: x' M x = mu x' K x = 1
+		scale := 1.0 / math.Sqrt(mu[i])
+		for j := 0; j < fem.mesh.NumVertex(); j++ {
+			for k := 0; k < freedom; k++ {
+				fem.res.Set(i*freedom+k, j, scale*x.At(j*freedom+k, i))
+			}
+		}
+	}
+	fem.printSummary()
+	duration := time.Since(start)
+	fmt.Printf("Lead time: %0.2f sec\n\n", duration.Seconds())
+	return nil
+}
+
+func (fem *ModalFEM) printSummary() {
+	fmt.Println("----------------------------------------------")
+	fmt.Println("Mode\tFrequency (Hz)")
+	for i, f := range fem.frequency {
+		fmt.Printf("%d\t%e\n", i+1, f)
+	}
+}
+
+// ResultNames - names of the mode shape components, they are repeated for every mode
+func (fem *ModalFEM) ResultNames() *[]string {
+	names := (*fem.StaticFEM.ResultNames())[:fem.mesh.Freedom()]
+	res := make([]string, 0, len(fem.frequency)*len(names))
+	for range fem.frequency {
+		res = append(res, names...)
+	}
+	return &res
+}
+
+// SaveResult - the mode shapes are saved with the natural frequency instead of the time
+func (fem *ModalFEM) SaveResult(name string) error {
+	freedom := fem.mesh.Freedom()
+	times := make([]float64, len(fem.frequency)*freedom)
+	for i := range times {
+		times[i] = fem.frequency[i/freedom]
+	}
+	return fem.saveResult(name, *fem.ResultNames(), times)
+}
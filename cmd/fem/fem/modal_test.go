@@ -0,0 +1,101 @@
+package fem
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"wfem/cmd/fem/params"
+)
+
+// writeBar - MESH-file of the bar [0, length] divided into n rods
+func writeBar(t *testing.T, n int, length float64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "fe1d2\n%d\n", n+1)
+	for i := 0; i <= n; i++ {
+		fmt.Fprintf(&b, "%g\n", length*float64(i)/float64(n))
+	}
+	fmt.Fprintf(&b, "%d\n", n)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "%d %d\n", i, i+1)
+	}
+	b.WriteString("0\n")
+	name := filepath.Join(t.TempDir(), "bar.mesh")
+	if err := os.WriteFile(name, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+// The axial natural frequencies of the bar fixed at one end are (2k - 1) / (4 L) sqrt(E / rho)
+func TestModalBar(t *testing.T) {
+	const e, rho, length = 2.0e+11, 7800.0, 2.0
+	name := writeBar(t, 40, length)
+	for _, isLumped := range []bool{false, true} {
+		t.Run(fmt.Sprintf("lumped=%v", isLumped), func(t *testing.T) {
+			f := NewModalFEM()
+			if err := f.SetMesh(name); err != nil {
+				t.Fatal(err)
+			}
+			f.SetNumModes(3)
+			f.SetLumpedMass(isLumped)
+			f.AddYoungModulus(fmt.Sprint(e), "")
+			f.AddPoissonRatio("0", "")
+			f.AddDensity(fmt.Sprint(rho), "")
+			f.AddThickness("1.0e-4", "")
+			f.AddBoundaryCondition("0", "x == 0", params.X)
+			if err := f.Calculate(); err != nil {
+				t.Fatal(err)
+			}
+			frequency := f.GetFrequency()
+			if len(frequency) != 3 {
+				t.Fatalf("%d frequencies are found", len(frequency))
+			}
+			for k, got := range frequency {
+				want := float64(2*k+1) / (4.0 * length) * math.Sqrt(e/rho)
+				if math.Abs(got-want) > 2.0e-3*want {
+					t.Fatalf("frequency %d is %g, want %g", k+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestModalRejectsOtherSolvers(t *testing.T) {
+	f := NewModalFEM()
+	if err := f.SetMesh(writeBar(t, 4, 1.0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetSolver("pcg"); err != nil {
+		t.Fatal(err)
+	}
+	f.AddYoungModulus("1", "")
+	f.AddDensity("1", "")
+	if err := f.Calculate(); err == nil {
+		t.Fatal("no error for the pcg solver")
+	}
+}
+
+// The point mass m on the end of the light rod of the stiffness k = EA / L vibrates at sqrt(k / m) / (2 pi)
+func TestModalPointMass(t *testing.T) {
+	const k, m = 4.0, 2.0
+	f := NewModalFEM()
+	if err := f.SetMesh(writeBar(t, 1, 1.0)); err != nil {
+		t.Fatal(err)
+	}
+	f.SetNumModes(1)
+	f.AddYoungModulus(fmt.Sprint(k), "")
+	f.AddPoissonRatio("0", "")
+	f.AddDensity("1.0e-9", "")
+	f.AddThickness("1", "")
+	f.AddBoundaryCondition("0", "x == 0", params.X)
+	f.AddPointMass(fmt.Sprint(m), "x == 1", params.X)
+	if err := f.Calculate(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.GetFrequency()[0], math.Sqrt(k/m)/(2.0*math.Pi); math.Abs(got-want) > 1.0e-6*want {
+		t.Fatalf("frequency is %g, want %g", got, want)
+	}
+}
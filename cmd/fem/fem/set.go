@@ -0,0 +1,70 @@
+package fem
+
+import (
+	"fmt"
+	"wfem/cmd/fem/params"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// DefineSet - the named set of the nodes satisfying the predicate and of the elements all nodes of which satisfy it,
+// the predicate is evaluated once for the current mesh. The parameters reference the set by the predicate "@name"
+// or "@name: predicate"
+func (fem *StaticFEM) DefineSet(name, predicate string) error {
+	if len(name) == 0 {
+		return fmt.Errorf("the name of the set is empty")
+	}
+	p := params.Parameter{Predicate: predicate}
+	var nodes []int
+	for i := range fem.mesh.X {
+		ok, err := p.GetPredicate(mat.NewVecDense(fem.mesh.FeDim(), fem.mesh.X[i]), &fem.params.Variables)
+		if err != nil {
+			return err
+		}
+		if ok {
+			nodes = append(nodes, i)
+		}
+	}
+	fem.mesh.AddSet(name, nodes)
+	return nil
+}
+
+// checkSets - all the sets referenced by the parameters must exist
+func (fem *StaticFEM) checkSets() error {
+	var list []params.Parameter
+	list = append(list, fem.params.Params...)
+	list = append(list, fem.discrete.springs...)
+	list = append(list, fem.discrete.masses...)
+	for _, link := range fem.discrete.links {
+		list = append(list, link.master, link.slaves)
+	}
+	for _, l := range fem.laminates {
+		list = append(list, l.predicate)
+	}
+	for _, p := range list {
+		if _, ok := fem.mesh.Sets[p.Set]; len(p.Set) > 0 && !ok {
+			return fmt.Errorf("the set '%s' is not found in the mesh", p.Set)
+		}
+	}
+	return nil
+}
+
+// inSet - whether the node, the finite element or the boundary element (kind) with the index belongs to the set of
+// the parameter, the parameters without the set apply to the whole mesh
+func (fem *StaticFEM) inSet(p *params.Parameter, kind, index int) bool {
+	return len(p.Set) == 0 || fem.mesh.InSet(p.Set, kind, index)
+}
+
+// paramValue - value of the first parameter of the type suitable for the node, the finite element or the boundary
+// element (kind) with the index, x - the point where the value is evaluated
+func (fem *StaticFEM) paramValue(kind, index int, x *mat.VecDense, pType int) (float64, error) {
+	return fem.params.GetSetParamValue(x, pType, func(name string) bool {
+		return fem.mesh.InSet(name, kind, index)
+	})
+}
+
+// newParameter - the parameter with the predicate possibly referencing a set of the mesh
+func newParameter(value, predicate string, direct int) params.Parameter {
+	set, predicate := params.SplitSet(predicate)
+	return params.Parameter{Value: value, Predicate: predicate, Set: set, Direct: direct}
+}
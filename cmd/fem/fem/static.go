@@ -1,9 +1,13 @@
 package fem
 
 import (
+	"bufio"
 	"fmt"
 	"math"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 	"wfem/cmd/fem/fe"
@@ -13,6 +17,7 @@ import (
 	"wfem/cmd/fem/solver"
 	"wfem/cmd/fem/util"
 
+	"golang.org/x/exp/slices"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -24,18 +29,42 @@ type MatrixData struct {
 type VectorData struct {
 	index  int
 	direct int
-	vector [3]float64
+	vector [6]float64
 }
 
 type StaticFEM struct {
-	res    *mat.Dense
-	solver solver.Solver
-	mesh   mesh.Mesh
-	params params.FEMParameters
+	res           *mat.Dense
+	solver        solver.Solver
+	solverName    string
+	solverOptions []solver.Option
+	mesh          mesh.Mesh
+	params        params.FEMParameters
+	// Temperature field loaded from the results of the thermal analysis (the original node numbering)
+	temperatureField []float64
+	// Temperatures of the nodes used for the thermal strains (nil - no thermal strains)
+	temperature []float64
+	// Analysis mode of the 2D problem (fe.PlaneStress, fe.PlaneStrain or fe.Axisymmetric)
+	mode2D int
+	// Formulation of the shell elements (fe.Mindlin, fe.DKT or fe.MITC4)
+	shellFormulation int
+	// Integration rule of the 2D and solid elements (fe.FullIntegration, fe.ReducedIntegration or
+	// fe.SelectiveIntegration)
+	integration int
+	// Laminates of the shells, the first one suitable for the center of an element is used
+	laminates []laminate
+	// Springs, point masses and rigid links
+	discrete discrete
+	// The data of the VTU-files are base64 encoded binary
+	vtkBinary bool
+}
+
+type laminate struct {
+	plies     []fe.Ply
+	predicate params.Parameter
 }
 
 func NewStaticFEM() StaticFEM {
-	return StaticFEM{params: params.New()}
+	return StaticFEM{params: params.New(), solverName: "sparse"}
 }
 
 func (fem *StaticFEM) SetMesh(name string) error {
@@ -74,6 +103,138 @@ func (fem *StaticFEM) AddYoungModulus(value, predicate string) {
 	fem.params.AddYoungModulus(value, predicate)
 }
 
+func (fem *StaticFEM) AddDensity(value, predicate string) {
+	fem.params.AddDensity(value, predicate)
+}
+
+// AddArea - cross-sectional area of the truss and frame bars
+func (fem *StaticFEM) AddArea(value, predicate string) {
+	fem.params.AddArea(value, predicate)
+}
+
+// AddInertia - moment of inertia of the frame bar section about the local y and/or z (direct) axes, the plane
+// frame is bent about the z axis
+func (fem *StaticFEM) AddInertia(value, predicate string, direct int) {
+	fem.params.AddInertia(value, predicate, direct)
+}
+
+func (fem *StaticFEM) AddTorsionConstant(value, predicate string) {
+	fem.params.AddTorsionConstant(value, predicate)
+}
+
+// AddShearCoefficient - shear correction factor, the frame bars are Timoshenko beams if it is set and
+// Euler-Bernoulli beams otherwise
+func (fem *StaticFEM) AddShearCoefficient(value, predicate string) {
+	fem.params.AddShearCoefficient(value, predicate)
+}
+
+// AddOrientation - components (direct) of the vector defining the local x-y plane of the space frame bars
+// (global z by default)
+func (fem *StaticFEM) AddOrientation(value, predicate string, direct int) {
+	fem.params.AddOrientation(value, predicate, direct)
+}
+
+// AddOrthotropicYoungModulus - Young's modulus of the orthotropic material along the material axis 1, 2 or 3
+// (direct - X, Y or Z), the orthotropic material replaces the isotropic one where E1 is set
+func (fem *StaticFEM) AddOrthotropicYoungModulus(value, predicate string, direct int) {
+	fem.params.AddOrthotropicYoungModulus(value, predicate, direct)
+}
+
+// AddShearModulus - shear modulus of the orthotropic material in the material plane 1-2, 1-3 or 2-3
+// (direct - X | Y, X | Z or Y | Z)
+func (fem *StaticFEM) AddShearModulus(value, predicate string, direct int) {
+	fem.params.AddShearModulus(value, predicate, direct)
+}
+
+// AddOrthotropicPoissonRatio - Poisson's ratio nu12, nu13 or nu23 (direct - X | Y, X | Z or Y | Z) of the
+// orthotropic material
+func (fem *StaticFEM) AddOrthotropicPoissonRatio(value, predicate string, direct int) {
+	fem.params.AddOrthotropicPoissonRatio(value, predicate, direct)
+}
+
+// AddMaterialAxis - components (direct) of the vector along the material axis 1 (global x by default), for the
+// 2D elements and shells it is projected onto the element plane
+func (fem *StaticFEM) AddMaterialAxis(value, predicate string, direct int) {
+	fem.params.AddMaterialAxis(value, predicate, direct)
+}
+
+// AddMaterialPlane - components (direct) of the vector lying in the material 1-2 plane of the solid elements
+// (global y by default)
+func (fem *StaticFEM) AddMaterialPlane(value, predicate string, direct int) {
+	fem.params.AddMaterialPlane(value, predicate, direct)
+}
+
+// AddElasticConstant - component (row, column) of the symmetric 6x6 elastic matrix of the anisotropic material in
+// the material axes, the order of the strains is 11, 22, 33, 12, 23, 13
+func (fem *StaticFEM) AddElasticConstant(value, predicate string, row, column int) {
+	fem.params.AddElasticConstant(value, predicate, row, column)
+}
+
+// AddLaminate - the shell elements satisfying the predicate are made of the plies listed from the bottom face,
+// the fibre angles are measured from the material axis 1 projected onto the shell. The thickness of the shell is
+// the total thickness of the plies
+func (fem *StaticFEM) AddLaminate(plies []fe.Ply, predicate string) {
+	fem.laminates = append(fem.laminates, laminate{plies: plies, predicate: newParameter("", predicate, 0)})
+}
+
+// AddSpring - grounded springs of the nodes satisfying the predicate, value - their stiffness in the directions
+func (fem *StaticFEM) AddSpring(value, predicate string, direct int) {
+	fem.discrete.springs = append(fem.discrete.springs, newParameter(value, predicate, direct))
+}
+
+// AddNodeSpring - spring between the nodes with the given numbers (node2 < 0 - the grounded spring of node1), the
+// stiffness components are in the order X, Y, Z, RX, RY, RZ
+func (fem *StaticFEM) AddNodeSpring(node1, node2 int, stiffness [6]float64) {
+	fem.discrete.nodeSprings = append(fem.discrete.nodeSprings, mesh.Spring{Node1: node1, Node2: node2, Stiffness: stiffness})
+}
+
+// AddPointMass - point masses of the nodes satisfying the predicate, value is the mass along X, Y, Z or the rotary
+// inertia about RX, RY, RZ
+func (fem *StaticFEM) AddPointMass(value, predicate string, direct int) {
+	fem.discrete.masses = append(fem.discrete.masses, newParameter(value, predicate, direct))
+}
+
+// AddNodeMass - point mass of the node with the given number, the components are in the order X, Y, Z, RX, RY, RZ
+func (fem *StaticFEM) AddNodeMass(node int, mass [6]float64) {
+	fem.discrete.nodeMasses = append(fem.discrete.nodeMasses, mesh.PointMass{Node: node, Mass: mass})
+}
+
+// AddRigidLink - the nodes satisfying the slaves predicate move together with the only node satisfying the master
+// predicate as a rigid body
+func (fem *StaticFEM) AddRigidLink(master, slaves string) {
+	fem.discrete.links = append(fem.discrete.links, rigidLink{master: newParameter("", master, 0),
+		slaves: newParameter("", slaves, 0)})
+}
+
+// AddNodeRigidLink - rigid link of the master node with the slave nodes given by their numbers
+func (fem *StaticFEM) AddNodeRigidLink(master int, slaves []int) {
+	fem.discrete.nodeLinks = append(fem.discrete.nodeLinks, mesh.RigidLink{Master: master, Slaves: slaves})
+}
+
+// AddTemperature - temperature of the nodes relative to the stress-free state, it causes the thermal strains
+// if the thermal expansion coefficient is set
+func (fem *StaticFEM) AddTemperature(value, predicate string) {
+	fem.params.AddTemperature(value, predicate)
+}
+
+func (fem *StaticFEM) AddThermalExpansion(value, predicate string) {
+	fem.params.AddThermalExpansion(value, predicate)
+}
+
+// LoadTemperature - the temperature field "T" from the .res file of the thermal analysis on the same mesh,
+// it takes precedence over the temperature given by AddTemperature
+func (fem *StaticFEM) LoadTemperature(name string) error {
+	values, err := loadResult(name, "T")
+	if err != nil {
+		return err
+	}
+	if len(values) != fem.mesh.NumVertex() {
+		return fmt.Errorf("the temperature field does not match the mesh")
+	}
+	fem.temperatureField = values
+	return nil
+}
+
 func (fem *StaticFEM) AddVariable(name string, value float64) {
 	fem.params.AddVariable(name, value)
 }
@@ -86,12 +247,77 @@ func (fem *StaticFEM) SetNumThread(num int) {
 	fem.params.SetNumThread(num)
 }
 
+// SetMode2D - analysis mode of the 2D problem: fe.PlaneStress (default), fe.PlaneStrain or fe.Axisymmetric.
+// In the axisymmetric problem x is the radius, y is the axis of symmetry, the loads are given per radian
+func (fem *StaticFEM) SetMode2D(mode int) {
+	fem.mode2D = mode
+}
+
+// SetShellFormulation - formulation of the shell elements: fe.Mindlin (default), fe.DKT for the triangles or
+// fe.MITC4 for the quadrangles, the last two do not lock in the thin shells
+func (fem *StaticFEM) SetShellFormulation(formulation int) {
+	fem.shellFormulation = formulation
+}
+
+// SetIntegration - integration rule of the stiffness matrix: fe.FullIntegration (default), fe.ReducedIntegration
+// (fe2d4, fe3d8 with the hourglass control and fe3d20) or fe.SelectiveIntegration (the volumetric part is integrated
+// by the reduced rule) against the shear and volumetric locking
+func (fem *StaticFEM) SetIntegration(integration int) {
+	fem.integration = integration
+}
+
+// SetVtkBinary - the data of the VTU-files written by SaveResult are base64 encoded binary instead of ASCII
+func (fem *StaticFEM) SetVtkBinary(isBinary bool) {
+	fem.vtkBinary = isBinary
+}
+
+// SetSolver - choosing the registered solver by its name ("sparse", "dense", "pcg", ...)
+func (fem *StaticFEM) SetSolver(name string, opts ...solver.Option) error {
+	if !solver.IsRegistered(name) {
+		return fmt.Errorf("unknown solver: %s", name)
+	}
+	fem.solverName = name
+	fem.solverOptions = opts
+	return nil
+}
+
+// checkSparseSolver - the analyses factorizing the matrices themselves (modal, ...) work with the sparse solver
+// only, the other solvers are rejected instead of being ignored
+func (fem *StaticFEM) checkSparseSolver(analysis string) error {
+	if fem.solverName != "sparse" {
+		return fmt.Errorf("%s analysis supports the sparse solver only, not %s", analysis, fem.solverName)
+	}
+	return nil
+}
+
 func (fem *StaticFEM) Calculate() error {
 	var err error
 	fmt.Printf("Using threads: %d\n", fem.params.NumThread)
 	start := time.Now()
-	fem.solver = solver.NewDenseSolver(&fem.mesh)
-	//fem.solver = solver.NewEigenSolver(&fem.mesh)
+	fmt.Printf("Solver: %s\n", fem.solverName)
+	if err = fem.checkSets(); err != nil {
+		return err
+	}
+	if err = fem.setDiscrete(); err != nil {
+		return err
+	}
+	// The penalty stiffness of the rigid links makes the matrix too ill-conditioned for the conjugate gradients
+	if len(fem.mesh.RigidLinks) > 0 && fem.solverName == "pcg" {
+		return fmt.Errorf("the rigid links are not supported by the pcg solver")
+	}
+	// Bandwidth reduction, the original numbering is restored with the results
+	fem.mesh.Renumber()
+	fem.res = nil
+	defer func() {
+		if fem.res != nil {
+			fem.mesh.RestoreNumbering(fem.res)
+		} else {
+			fem.mesh.RestoreNumbering()
+		}
+	}()
+	if fem.solver, err = solver.New(fem.solverName, &fem.mesh, append([]solver.Option{solver.WithEps(fem.params.Eps)}, fem.solverOptions...)...); err != nil {
+		return err
+	}
 	if err = fem.calcGlobalMatrix(); err != nil {
 		return err
 	}
@@ -104,13 +330,19 @@ func (fem *StaticFEM) Calculate() error {
 	if err = fem.addSurfaceLoad(); err != nil {
 		return err
 	}
-	if err = fem.addBoundaryCondition(); err != nil {
+	if err = fem.addThermalLoad(); err != nil {
+		return err
+	}
+	if err = fem.addBoundaryCondition(fem.solver.SetBoundaryCondition); err != nil {
 		return err
 	}
 	x, err := fem.solver.Solve()
 	if err != nil {
 		return err
 	}
+	if pcg, ok := fem.solver.(*solver.PCGSolver); ok {
+		fmt.Printf("Iterations: %d, residual: %e\n", pcg.Iterations(), pcg.Residual())
+	}
 	err = fem.calcResult(x)
 	if err != nil {
 		return err
@@ -122,55 +354,85 @@ func (fem *StaticFEM) Calculate() error {
 }
 
 func (fem *StaticFEM) calcGlobalMatrix() error {
+	if err := fem.assemble(fem.solver, fem.mesh.Freedom(), "Building a global stiffness matrix", fem.feMatrix(func(_ int, elm fe.FiniteElement) *mat.Dense {
+		return elm.Create()
+	})); err != nil {
+		return err
+	}
+	fem.addSprings(fem.solver)
+	fem.addRigidLinks(fem.solver)
+	return nil
+}
+
+// feMatrix - the local matrix of the index-th mechanical finite element for assemble
+func (fem *StaticFEM) feMatrix(local func(int, fe.FiniteElement) *mat.Dense) func(int) (*mat.Dense, error) {
+	return func(index int) (*mat.Dense, error) {
+		elm, err := fem.createFE(index)
+		if err != nil {
+			return nil, err
+		}
+		return local(index, elm), nil
+	}
+}
+
+// assemble - adding the local matrices of all finite elements to the global one, freedom - number of
+// the unknowns per node
+func (fem *StaticFEM) assemble(global solver.Solver, freedom int, title string, local func(int) (*mat.Dense, error)) error {
 	data := make(chan MatrixData, fem.params.NumThread)
-	errChan := make(chan error, fem.params.NumThread)
 	done := make(chan struct{})
-	step := fem.mesh.NumFE() / fem.params.NumThread
 	go func() {
-		var local MatrixData
-		freedom := fem.mesh.Freedom()
-		size := fem.mesh.FeSize() * freedom
-		msg := progress.NewProgress("Building a global stiffness matrix", 0, fem.mesh.NumFE(), 10)
-		for k := 0; k < fem.mesh.NumFE(); k++ {
+		msg := progress.NewProgress(title, 0, fem.mesh.NumFE(), 10)
+		for elm := range data {
 			msg.AddProgress()
-			local = <-data
+			size := len(fem.mesh.FE[elm.index]) * freedom
 			for i := 0; i < size; i++ {
 				for j := i; j < size; j++ {
-					fem.solver.AddMatrix(fem.mesh.FE[local.index][i/freedom]*freedom+i%freedom, fem.mesh.FE[local.index][j/freedom]*freedom+j%freedom, local.matrix.At(i, j))
+					global.AddMatrix(fem.mesh.FE[elm.index][i/freedom]*freedom+i%freedom, fem.mesh.FE[elm.index][j/freedom]*freedom+j%freedom, elm.matrix.At(i, j))
 					if i != j {
-						fem.solver.AddMatrix(fem.mesh.FE[local.index][j/freedom]*freedom+j%freedom, fem.mesh.FE[local.index][i/freedom]*freedom+i%freedom, local.matrix.At(i, j))
+						global.AddMatrix(fem.mesh.FE[elm.index][j/freedom]*freedom+j%freedom, fem.mesh.FE[elm.index][i/freedom]*freedom+i%freedom, elm.matrix.At(i, j))
 					}
 				}
 			}
 		}
 		done <- struct{}{}
 	}()
+	err := fem.parallel(fem.mesh.NumFE(), func(begin, end int) error {
+		for j := begin; j < end; j++ {
+			matrix, err := local(j)
+			if err != nil {
+				return err
+			}
+			data <- MatrixData{index: j, matrix: matrix}
+		}
+		return nil
+	})
+	close(data)
+	<-done
+	return err
+}
+
+// parallel - calling fun for the parts [begin, end) of the range [0, num) in NumThread goroutines, all parts are
+// waited for and the first error is returned
+func (fem *StaticFEM) parallel(num int, fun func(begin, end int) error) error {
+	errChan := make(chan error, fem.params.NumThread)
+	step := num / fem.params.NumThread
 	for i := 0; i < fem.params.NumThread; i++ {
 		begin := i * step
 		end := (i + 1) * step
 		if i == fem.params.NumThread-1 {
-			end = fem.mesh.NumFE()
+			end = num
 		}
 		go func() {
-			var elm fe.FiniteElement
-			var err error
-			defer func() {
-				errChan <- err
-			}()
-			for j := begin; j < end; j++ {
-				if elm, err = fem.createFE(j); err != nil {
-					return
-				}
-				local := elm.Create()
-				data <- MatrixData{index: j, matrix: local}
-			}
+			errChan <- fun(begin, end)
 		}()
 	}
-	if err := <-errChan; err != nil {
-		return err
+	var res error
+	for i := 0; i < fem.params.NumThread; i++ {
+		if err := <-errChan; err != nil && res == nil {
+			res = err
+		}
 	}
-	<-done
-	return nil
+	return res
 }
 
 func (fem *StaticFEM) GetResult() *mat.Dense {
@@ -185,152 +447,111 @@ func (fem *StaticFEM) printSummary() {
 	}
 }
 
-func (fem *StaticFEM) addBoundaryCondition() error {
-	if !fem.params.FindParameter(params.BoundaryCondition) {
+// addNodeParameter - evaluating the parameters of the given type at the nodes, fun is called with the parameter
+// value for every degree of freedom of the parameter directions
+func (fem *StaticFEM) addNodeParameter(pType int, title string, freedom int, fun func(int, float64)) error {
+	if !fem.params.FindParameter(pType) {
 		return nil
 	}
-	var counter int32
 	done := make(chan struct{})
 	data := make(chan VectorData, fem.params.NumThread)
-	errChan := make(chan error, fem.params.NumThread)
-	step := fem.mesh.NumVertex() / fem.params.NumThread
-	go fem.addData(data, done, fem.solver.SetBoundaryCondition)
-	msg := progress.NewProgress("Using of boundary conditions", 0, fem.mesh.NumVertex(), 10)
-	for i := 0; i < fem.params.NumThread; i++ {
-		begin := i * step
-		end := (i + 1) * step
-		if i == fem.params.NumThread-1 {
-			end = fem.mesh.NumVertex()
-		}
-		go func() {
-			var err error
-			var ok bool
-			var value float64
-			defer func() {
-				errChan <- err
-			}()
-			for j := begin; j < end; j++ {
-				msg.AddProgress()
-				for k := range fem.params.Params {
-					if fem.params.Params[k].Type == params.BoundaryCondition {
-						x := mat.NewVecDense(fem.mesh.FeDim(), fem.mesh.X[j])
-						if len(fem.params.Params[k].Predicate) > 0 {
-							ok, err = fem.params.Params[k].GetPredicate(x, &fem.params.Variables)
-							if err != nil {
-								errChan <- err
-								return
-							}
-							if !ok {
-								continue
-							}
-						}
-						value, err = fem.params.Params[k].GetValue(x, &fem.params.Variables)
+	go fem.addData(data, done, freedom, fun)
+	msg := progress.NewProgress(title, 0, fem.mesh.NumVertex(), 10)
+	err := fem.parallel(fem.mesh.NumVertex(), func(begin, end int) error {
+		for j := begin; j < end; j++ {
+			msg.AddProgress()
+			for k := range fem.params.Params {
+				if fem.params.Params[k].Type == pType && fem.inSet(&fem.params.Params[k], mesh.SetNode, j) {
+					x := mat.NewVecDense(fem.mesh.FeDim(), fem.mesh.X[j])
+					if len(fem.params.Params[k].Predicate) > 0 {
+						ok, err := fem.params.Params[k].GetPredicate(x, &fem.params.Variables)
 						if err != nil {
-							errChan <- err
-							return
+							return err
+						}
+						if !ok {
+							continue
 						}
-						data <- VectorData{index: j, direct: fem.params.Params[k].Direct, vector: [3]float64{value, value, value}}
 					}
-				}
-				atomic.AddInt32(&counter, 1)
-				if int(counter) == fem.mesh.NumVertex() {
-					close(data)
+					value, err := fem.params.Params[k].GetValue(x, &fem.params.Variables)
+					if err != nil {
+						return err
+					}
+					data <- VectorData{index: j, direct: fem.params.Params[k].Direct, vector: [6]float64{value, value, value, value, value, value}}
 				}
 			}
-		}()
-	}
-	if err := <-errChan; err != nil {
-		return err
-	}
+		}
+		return nil
+	})
+	close(data)
 	<-done
-	return nil
+	return err
+}
+
+func (fem *StaticFEM) addBoundaryCondition(fun func(int, float64)) error {
+	return fem.addNodeParameter(params.BoundaryCondition, "Using of boundary conditions", fem.mesh.Freedom(), fun)
 }
 
 func (fem *StaticFEM) addPointLoad() error {
-	if !fem.params.FindParameter(params.PointLoad) {
-		return nil
-	}
-	var counter int32
-	done := make(chan struct{})
-	data := make(chan VectorData, fem.params.NumThread)
-	errChan := make(chan error, fem.params.NumThread)
-	step := fem.mesh.NumVertex() / fem.params.NumThread
-	go fem.addData(data, done, fem.solver.AddVector)
-	msg := progress.NewProgress("Calculation of point loads", 0, fem.mesh.NumVertex(), 10)
-	for i := 0; i < fem.params.NumThread; i++ {
-		begin := i * step
-		end := (i + 1) * step
-		if i == fem.params.NumThread-1 {
-			end = fem.mesh.NumVertex()
-		}
-		go func() {
-			var err error
-			var ok bool
-			var value float64
-			defer func() {
-				errChan <- err
-			}()
-			for j := begin; j < end; j++ {
-				msg.AddProgress()
-				for k := range fem.params.Params {
-					if fem.params.Params[k].Type == params.PointLoad {
-						x := mat.NewVecDense(fem.mesh.FeDim(), fem.mesh.X[j])
-						if len(fem.params.Params[k].Predicate) > 0 {
-							ok, err = fem.params.Params[k].GetPredicate(x, &fem.params.Variables)
-							if err != nil {
-								errChan <- err
-								return
-							}
-							if !ok {
-								continue
-							}
-						}
-						value, err = fem.params.Params[k].GetValue(x, &fem.params.Variables)
-						if err != nil {
-							errChan <- err
-							return
-						}
-						data <- VectorData{index: j, direct: fem.params.Params[k].Direct, vector: [3]float64{value, value, value}}
-					}
-				}
-				atomic.AddInt32(&counter, 1)
-				if int(counter) == fem.mesh.NumVertex() {
-					close(data)
-				}
-			}
-		}()
-	}
-	if err := <-errChan; err != nil {
-		return err
-	}
-	<-done
-	return nil
+	return fem.addNodeParameter(params.PointLoad, "Calculation of point loads", fem.mesh.Freedom(), fem.solver.AddVector)
 }
 
-func (fem *StaticFEM) addData(data chan VectorData, done chan struct{}, fun func(int, float64)) {
+func (fem *StaticFEM) addData(data chan VectorData, done chan struct{}, freedom int, fun func(int, float64)) {
 	var chData VectorData
 	var ok bool
-	direct := [3]int{params.X, params.Y, params.Z}
+	direct := [6]int{params.X, params.Y, params.Z, params.RX, params.RY, params.RZ}
+	dof := fem.dofDirections(freedom)
 	for {
 		if chData, ok = <-data; !ok {
 			done <- struct{}{}
 			break
 		}
-		for l := 0; l < fem.mesh.FeDim(); l++ {
-			if chData.direct&direct[l] == direct[l] {
-				fun(chData.index*fem.mesh.Freedom()+l, chData.vector[l])
+		for l, k := range dof {
+			if chData.direct&direct[k] == direct[k] {
+				fun(chData.index*freedom+l, chData.vector[k])
 			}
 		}
 	}
 }
 
-func (fem *StaticFEM) addVolumeLoad() error {
-	if !fem.params.FindParameter(params.VolumeLoad) {
-		return nil
+// dofDirections - the directions (indices of X, Y, Z, RX, RY, RZ) of the degrees of freedom of the node:
+// the translations are followed by the rotation in the plane or by the three rotations
+func (fem *StaticFEM) dofDirections(freedom int) []int {
+	var res []int
+	for l := 0; l < fem.mesh.FeDim() && l < freedom; l++ {
+		res = append(res, l)
+	}
+	switch freedom - len(res) {
+	case 1:
+		res = append(res, 5)
+	case 3:
+		res = append(res, 3, 4, 5)
 	}
+	return res
+}
+
+// revolution - the factor of the volume (area) of the element with the nodes x, it is the radius of the center
+// in the axisymmetric problem (per radian)
+func (fem *StaticFEM) revolution(x *mat.Dense) float64 {
+	if !fem.mesh.Is2D() || fem.mode2D != fe.Axisymmetric {
+		return 1.0
+	}
+	rows, _ := x.Dims()
+	var r float64
+	for i := 0; i < rows; i++ {
+		r += x.At(i, 0)
+	}
+	return r / float64(rows)
+}
+
+func (fem *StaticFEM) addVolumeLoad() error {
+	return fem.addVolumeParameter(params.VolumeLoad, "Calculation of volume loads", fem.mesh.Freedom(), fem.solver.AddVector)
+}
+
+// volumeShare - shares of the volume of the index-th finite element related to its nodes
+func (fem *StaticFEM) volumeShare(index int) []float64 {
 	var share []float64
-	switch fem.mesh.FeType {
-	case mesh.Fe1d2:
+	switch fem.mesh.ElementType(index) {
+	case mesh.Fe1d2, mesh.Fe2d2t, mesh.Fe3d2t, mesh.Fe2d2b, mesh.Fe3d2b:
 		share = []float64{0.5, 0.5}
 	case mesh.Fe2d3:
 		fallthrough
@@ -344,74 +565,79 @@ func (fem *StaticFEM) addVolumeLoad() error {
 		share = []float64{0.25, 0.25, 0.25, 0.25}
 	case mesh.Fe3d8:
 		share = []float64{0.125, 0.125, 0.125, 0.125, 0.125, 0.125, 0.125, 0.125}
+	case mesh.Fe2d6:
+		// The consistent loads of the quadratic elements are carried only (or mostly) by the mid-side nodes
+		share = []float64{0.0, 0.0, 0.0, 0.33333333333, 0.33333333333, 0.33333333333}
+	case mesh.Fe3d10:
+		share = []float64{-0.05, -0.05, -0.05, -0.05, 0.2, 0.2, 0.2, 0.2, 0.2, 0.2}
+	case mesh.Fe3d6:
+		share = []float64{0.16666666667, 0.16666666667, 0.16666666667, 0.16666666667, 0.16666666667, 0.16666666667}
+	case mesh.Fe3d20:
+		share = []float64{-0.125, -0.125, -0.125, -0.125, -0.125, -0.125, -0.125, -0.125, 0.16666666667, 0.16666666667,
+			0.16666666667, 0.16666666667, 0.16666666667, 0.16666666667, 0.16666666667, 0.16666666667, 0.16666666667,
+			0.16666666667, 0.16666666667, 0.16666666667}
+	case mesh.Fe3d27:
+		share = []float64{0.00462962963, 0.00462962963, 0.00462962963, 0.00462962963, 0.00462962963, 0.00462962963,
+			0.00462962963, 0.00462962963, 0.01851851852, 0.01851851852, 0.01851851852, 0.01851851852, 0.01851851852,
+			0.01851851852, 0.01851851852, 0.01851851852, 0.01851851852, 0.01851851852, 0.01851851852, 0.01851851852,
+			0.07407407407, 0.07407407407, 0.07407407407, 0.07407407407, 0.07407407407, 0.07407407407, 0.2962962963}
+	}
+	return share
+}
+
+// addVolumeParameter - distributing the parameters of the given type (per unit volume) over the nodes of
+// the finite elements, fun is called with the nodal value for every degree of freedom of the parameter directions
+func (fem *StaticFEM) addVolumeParameter(pType int, title string, freedom int, fun func(int, float64)) error {
+	if !fem.params.FindParameter(pType) {
+		return nil
 	}
-	var counter int32
 	done := make(chan struct{})
 	data := make(chan VectorData, fem.params.NumThread)
-	errChan := make(chan error, fem.params.NumThread)
-	step := fem.mesh.NumFE() / fem.params.NumThread
-	go fem.addData(data, done, fem.solver.AddVector)
-	msg := progress.NewProgress("Calculation of volume loads", 0, fem.mesh.NumFE(), 10)
-	for i := 0; i < fem.params.NumThread; i++ {
-		begin := i * step
-		end := (i + 1) * step
-		if i == fem.params.NumThread-1 {
-			end = fem.mesh.NumFE()
-		}
-		go func() {
-			var err error
-			var ok bool
-			var value float64
-			defer func() {
-				errChan <- err
-			}()
-			for j := begin; j < end; j++ {
-				msg.AddProgress()
-				for k := range fem.params.Params {
-					if fem.params.Params[k].Type == params.VolumeLoad {
-						x := fem.mesh.FeCenter(j)
-						if len(fem.params.Params[k].Predicate) > 0 {
-							ok, err = fem.params.Params[k].GetPredicate(x, &fem.params.Variables)
-							if err != nil {
-								errChan <- err
-								return
-							}
-							if !ok {
-								continue
-							}
-						}
-						value, err = fem.params.Params[k].GetValue(x, &fem.params.Variables)
-						volume := fem.mesh.FeVolume(j)
+	go fem.addData(data, done, freedom, fun)
+	msg := progress.NewProgress(title, 0, fem.mesh.NumFE(), 10)
+	err := fem.parallel(fem.mesh.NumFE(), func(begin, end int) error {
+		for j := begin; j < end; j++ {
+			msg.AddProgress()
+			for k := range fem.params.Params {
+				if fem.params.Params[k].Type == pType && fem.inSet(&fem.params.Params[k], mesh.SetFE, j) {
+					x := fem.mesh.FeCenter(j)
+					if len(fem.params.Params[k].Predicate) > 0 {
+						ok, err := fem.params.Params[k].GetPredicate(x, &fem.params.Variables)
 						if err != nil {
-							errChan <- err
-							return
+							return err
 						}
-						for l := 0; l < fem.mesh.FeSize(); l++ {
-							data <- VectorData{index: fem.mesh.FE[j][l], direct: fem.params.Params[k].Direct, vector: [3]float64{volume * value * share[l], volume * value * share[l], volume * value * share[l]}}
+						if !ok {
+							continue
 						}
 					}
-				}
-				atomic.AddInt32(&counter, 1)
-				if int(counter) == fem.mesh.NumFE() {
-					close(data)
+					value, err := fem.params.Params[k].GetValue(x, &fem.params.Variables)
+					if err != nil {
+						return err
+					}
+					volume := fem.mesh.FeVolume(j) * fem.revolution(fem.mesh.FeCoord(j))
+					share := fem.volumeShare(j)
+					for l := range fem.mesh.FE[j] {
+						data <- VectorData{index: fem.mesh.FE[j][l], direct: fem.params.Params[k].Direct, vector: [6]float64{volume * value * share[l], volume * value * share[l], volume * value * share[l]}}
+					}
 				}
 			}
-		}()
-	}
-	if err := <-errChan; err != nil {
-		return err
-	}
+		}
+		return nil
+	})
+	close(data)
 	<-done
-	return nil
+	return err
 }
 
 func (fem *StaticFEM) addSurfaceLoad() error {
-	if !fem.params.FindParameter(params.SurfaceLoad) && !fem.params.FindParameter(params.PressureLoad) {
-		return nil
-	}
+	return fem.addSurfaceParameter("Calculation of pressure/surface loads", fem.mesh.Freedom(), fem.solver.AddVector, params.SurfaceLoad, params.PressureLoad)
+}
+
+// surfaceShare - shares of the area of the index-th boundary element related to its nodes
+func (fem *StaticFEM) surfaceShare(index int) []float64 {
 	var share []float64
 	switch fem.mesh.FeType {
-	case mesh.Fe1d2:
+	case mesh.Fe1d2, mesh.Fe2d2t, mesh.Fe3d2t, mesh.Fe2d2b, mesh.Fe3d2b:
 		share = []float64{1.0}
 	case mesh.Fe2d3:
 		fallthrough
@@ -421,80 +647,85 @@ func (fem *StaticFEM) addSurfaceLoad() error {
 		fallthrough
 	case mesh.Fe3d3s:
 		share = []float64{0.333333333333, 0.333333333333, 0.333333333333}
-	case mesh.Fe3d8:
-		fallthrough
 	case mesh.Fe3d4s:
 		share = []float64{0.25, 0.25, 0.25, 0.25}
+	case mesh.Fe2d6:
+		share = []float64{0.16666666667, 0.16666666667, 0.66666666667}
+	case mesh.Fe3d10:
+		share = []float64{0.0, 0.0, 0.0, 0.33333333333, 0.33333333333, 0.33333333333}
+	case mesh.Fe3d8, mesh.Fe3d6:
+		share = []float64{0.25, 0.25, 0.25, 0.25}
+		if fem.mesh.BE[index][3] == fem.mesh.BE[index][2] {
+			// Triangular face of the wedge or of the tetrahedron of the mixed mesh
+			share = []float64{0.33333333333, 0.33333333333, 0.33333333333, 0.0}
+		}
+	case mesh.Fe3d20:
+		share = []float64{-0.08333333333, -0.08333333333, -0.08333333333, -0.08333333333, 0.33333333333, 0.33333333333,
+			0.33333333333, 0.33333333333}
+	case mesh.Fe3d27:
+		share = []float64{0.02777777778, 0.02777777778, 0.02777777778, 0.02777777778, 0.11111111111, 0.11111111111,
+			0.11111111111, 0.11111111111, 0.44444444444}
+	}
+	return share
+}
+
+// addSurfaceParameter - distributing the parameters of the given types (per unit area) over the nodes of
+// the boundary elements, the pressure is directed along the normal
+func (fem *StaticFEM) addSurfaceParameter(title string, freedom int, fun func(int, float64), types ...int) error {
+	isFound := false
+	for _, pType := range types {
+		isFound = isFound || fem.params.FindParameter(pType)
+	}
+	if !isFound {
+		return nil
 	}
-	var counter int32
 	done := make(chan struct{})
 	data := make(chan VectorData, fem.params.NumThread)
-	errChan := make(chan error, fem.params.NumThread)
-	step := fem.mesh.NumBE() / fem.params.NumThread
-	go fem.addData(data, done, fem.solver.AddVector)
-	msg := progress.NewProgress("Calculation of pressure/surface loads", 0, fem.mesh.NumBE(), 10)
-	for i := 0; i < fem.params.NumThread; i++ {
-		begin := i * step
-		end := (i + 1) * step
-		if i == fem.params.NumThread-1 {
-			end = fem.mesh.NumBE()
-		}
-		go func() {
-			var err error
-			var ok bool
-			var value float64
-			defer func() {
-				errChan <- err
-			}()
-			for j := begin; j < end; j++ {
-				msg.AddProgress()
-				for k := range fem.params.Params {
-					if fem.params.Params[k].Type == params.SurfaceLoad || fem.params.Params[k].Type == params.PressureLoad {
-						x := fem.mesh.BeCoord(j)
-						if len(fem.params.Params[k].Predicate) > 0 {
-							isValidPredicate := true
-							for l := 0; l < fem.mesh.BeSize(); l++ {
-								ok, err = fem.params.Params[k].GetPredicate(x.RowView(l).(*mat.VecDense), &fem.params.Variables)
-								if err != nil {
-									errChan <- err
-									return
-								}
-								if !ok {
-									isValidPredicate = false
-									break
-								}
+	go fem.addData(data, done, freedom, fun)
+	msg := progress.NewProgress(title, 0, fem.mesh.NumBE(), 10)
+	err := fem.parallel(fem.mesh.NumBE(), func(begin, end int) error {
+		for j := begin; j < end; j++ {
+			msg.AddProgress()
+			for k := range fem.params.Params {
+				if slices.Contains(types, fem.params.Params[k].Type) && fem.inSet(&fem.params.Params[k], mesh.SetBE, j) {
+					x := fem.mesh.BeCoord(j)
+					if len(fem.params.Params[k].Predicate) > 0 {
+						isValidPredicate := true
+						for l := 0; l < fem.mesh.BeSize(); l++ {
+							ok, err := fem.params.Params[k].GetPredicate(x.RowView(l).(*mat.VecDense), &fem.params.Variables)
+							if err != nil {
+								return err
 							}
-							if !isValidPredicate {
-								continue
+							if !ok {
+								isValidPredicate = false
+								break
 							}
 						}
-						value, err = fem.params.Params[k].GetValue(x.RowView(0).(*mat.VecDense), &fem.params.Variables)
-						volume := fem.mesh.BeVolume(j)
-						if err != nil {
-							errChan <- err
-							return
-						}
-						normal := [3]float64{1.0, 1.0, 1.0}
-						if fem.params.Params[k].Type == params.PressureLoad {
-							normal = fem.mesh.BeNormal(j)
-						}
-						for l := 0; l < fem.mesh.BeSize(); l++ {
-							data <- VectorData{index: fem.mesh.BE[j][l], direct: fem.params.Params[k].Direct, vector: [3]float64{normal[0] * volume * value * share[l], normal[1] * volume * value * share[l], normal[2] * volume * value * share[l]}}
+						if !isValidPredicate {
+							continue
 						}
 					}
-				}
-				atomic.AddInt32(&counter, 1)
-				if int(counter) == fem.mesh.NumBE() {
-					close(data)
+					value, err := fem.params.Params[k].GetValue(x.RowView(0).(*mat.VecDense), &fem.params.Variables)
+					if err != nil {
+						return err
+					}
+					volume := fem.mesh.BeVolume(j) * fem.revolution(x)
+					share := fem.surfaceShare(j)
+					normal := [3]float64{1.0, 1.0, 1.0}
+					if fem.params.Params[k].Type == params.PressureLoad {
+						normal = fem.mesh.BeNormal(j)
+					}
+					for l := 0; l < fem.mesh.BeSize(); l++ {
+						data <- VectorData{index: fem.mesh.BE[j][l], direct: fem.params.Params[k].Direct, vector: [6]float64{normal[0] * volume * value * share[l], normal[1] * volume * value * share[l], normal[2] * volume * value * share[l]}}
+					}
 				}
 			}
-		}()
-	}
-	if err := <-errChan; err != nil {
-		return err
-	}
+		}
+		return nil
+	})
+	close(data)
 	<-done
-	return nil
+	return err
 }
 
 func (fem *StaticFEM) createFE(index int) (fe.FiniteElement, error) {
@@ -502,26 +733,69 @@ func (fem *StaticFEM) createFE(index int) (fe.FiniteElement, error) {
 	x := fem.mesh.FeCoord(index)
 	feParams := fe.FiniteElementParameters{}
 
-	youngModulus, err := fem.params.GetParamValue(cx, params.YoungModulus)
+	youngModulus, err := fem.paramValue(mesh.SetFE, index, cx, params.YoungModulus)
 	if err != nil {
 		return nil, err
 	}
 	feParams.YoungModulus = youngModulus
-	poissonRatio, err := fem.params.GetParamValue(cx, params.PoissonRatio)
+	poissonRatio, err := fem.paramValue(mesh.SetFE, index, cx, params.PoissonRatio)
 	if err != nil {
 		return nil, err
 	}
 	feParams.PoissonRation = poissonRatio
+	density, err := fem.paramValue(mesh.SetFE, index, cx, params.Density)
+	if err != nil {
+		return nil, err
+	}
+	feParams.Density = density
 
 	if !fem.mesh.Is3D() {
-		thickness, err := fem.params.GetParamValue(cx, params.Thickness)
+		thickness, err := fem.paramValue(mesh.SetFE, index, cx, params.Thickness)
 		if err != nil {
 			return nil, err
 		}
 		feParams.Thickness = thickness
 	}
+	if fem.mesh.Is2D() {
+		feParams.Mode = fem.mode2D
+	}
+	if fem.integration != fe.FullIntegration {
+		// All elements of a mixed mesh must support the rule
+		if feType := fem.mesh.ElementType(index); feType != mesh.Fe2d4 && feType != mesh.Fe3d8 && feType != mesh.Fe3d20 {
+			return nil, fmt.Errorf("the integration rule is not supported by the finite element")
+		}
+		feParams.Integration = fem.integration
+	}
+	if fem.mesh.IsTruss() || fem.mesh.IsBeam() {
+		if err = fem.crossSection(index, cx, &feParams); err != nil {
+			return nil, err
+		}
+	} else if err = fem.material(index, cx, &feParams); err != nil {
+		return nil, err
+	}
+	if fem.mesh.FeType == mesh.Fe3d3s || fem.mesh.FeType == mesh.Fe3d4s {
+		if err = fem.laminate(index, cx, &feParams); err != nil {
+			return nil, err
+		}
+		if (fem.shellFormulation == fe.DKT && fem.mesh.FeType != mesh.Fe3d3s) ||
+			(fem.shellFormulation == fe.MITC4 && fem.mesh.FeType != mesh.Fe3d4s) {
+			return nil, fmt.Errorf("the shell formulation is not supported by the finite element")
+		}
+		feParams.Formulation = fem.shellFormulation
+	}
+	if fem.temperature != nil {
+		thermalExpansion, err := fem.paramValue(mesh.SetFE, index, cx, params.ThermalExpansion)
+		if err != nil {
+			return nil, err
+		}
+		feParams.ThermalExpansion = thermalExpansion
+		feParams.Temperature = make([]float64, len(fem.mesh.FE[index]))
+		for j := range feParams.Temperature {
+			feParams.Temperature[j] = fem.temperature[fem.mesh.FE[index][j]]
+		}
+	}
 
-	switch fem.mesh.FeType {
+	switch fem.mesh.ElementType(index) {
 	case mesh.Fe1d2:
 		shape, err := fe.NewShape1d2(x)
 		if err != nil {
@@ -552,6 +826,36 @@ func (fem *StaticFEM) createFE(index int) (fe.FiniteElement, error) {
 			return nil, err
 		}
 		return fe.NewFE3D(shape, feParams), err
+	case mesh.Fe2d6:
+		shape, err := fe.NewShape2d6(x)
+		if err != nil {
+			return nil, err
+		}
+		return fe.NewFE2D(shape, feParams), err
+	case mesh.Fe3d10:
+		shape, err := fe.NewShape3d10(x)
+		if err != nil {
+			return nil, err
+		}
+		return fe.NewFE3D(shape, feParams), err
+	case mesh.Fe3d6:
+		shape, err := fe.NewShape3d6(x)
+		if err != nil {
+			return nil, err
+		}
+		return fe.NewFE3D(shape, feParams), err
+	case mesh.Fe3d20:
+		shape, err := fe.NewShape3d20(x)
+		if err != nil {
+			return nil, err
+		}
+		return fe.NewFE3D(shape, feParams), err
+	case mesh.Fe3d27:
+		shape, err := fe.NewShape3d27(x)
+		if err != nil {
+			return nil, err
+		}
+		return fe.NewFE3D(shape, feParams), err
 	case mesh.Fe3d3s:
 		transformMatrix := util.TransformMatrix(x)
 		shape, err := fe.NewShape2d3(util.Transpose(util.Mul(transformMatrix, x.T())))
@@ -566,27 +870,246 @@ func (fem *StaticFEM) createFE(index int) (fe.FiniteElement, error) {
 			return nil, err
 		}
 		return fe.NewFE3DS(shape, transformMatrix, feParams), err
+	case mesh.Fe2d2t, mesh.Fe3d2t:
+		return fe.NewTruss(x, feParams), nil
+	case mesh.Fe2d2b, mesh.Fe3d2b:
+		return fe.NewBeam(x, feParams), nil
 	}
 	return nil, fmt.Errorf("bad finite element type")
 }
 
+// crossSection - parameters of the section of the index-th bar with the center cx
+func (fem *StaticFEM) crossSection(index int, cx *mat.VecDense, feParams *fe.FiniteElementParameters) error {
+	var err error
+	for _, p := range []struct {
+		pType int
+		value *float64
+	}{
+		{params.Area, &feParams.Area},
+		{params.InertiaY, &feParams.InertiaY},
+		{params.InertiaZ, &feParams.InertiaZ},
+		{params.TorsionConstant, &feParams.TorsionConstant},
+		{params.ShearCoefficient, &feParams.ShearCoefficient},
+	} {
+		if *p.value, err = fem.paramValue(mesh.SetFE, index, cx, p.pType); err != nil {
+			return err
+		}
+	}
+	if feParams.Area == 0 {
+		return fmt.Errorf("cross-sectional area is not set")
+	}
+	feParams.Orientation, err = fem.vectorParameter(index, cx, params.Orientation)
+	return err
+}
+
+// vectorParameter - the vector assembled from the components (direct) of all the parameters of the type pType
+// suitable for the index-th element with the center cx
+func (fem *StaticFEM) vectorParameter(index int, cx *mat.VecDense, pType int) ([3]float64, error) {
+	var res [3]float64
+	direct := [3]int{params.X, params.Y, params.Z}
+	for k := range fem.params.Params {
+		if fem.params.Params[k].Type != pType || !fem.inSet(&fem.params.Params[k], mesh.SetFE, index) {
+			continue
+		}
+		ok, err := fem.params.Params[k].GetPredicate(cx, &fem.params.Variables)
+		if err != nil {
+			return res, err
+		}
+		if !ok {
+			continue
+		}
+		value, err := fem.params.Params[k].GetValue(cx, &fem.params.Variables)
+		if err != nil {
+			return res, err
+		}
+		for l := range direct {
+			if fem.params.Params[k].Direct&direct[l] == direct[l] {
+				res[l] = value
+			}
+		}
+	}
+	return res, nil
+}
+
+// material - orthotropic or anisotropic material of the index-th element with the center cx. The anisotropic elastic
+// matrix is assembled from all the suitable components, the elements without them keep the isotropic material
+func (fem *StaticFEM) material(index int, cx *mat.VecDense, feParams *fe.FiniteElementParameters) error {
+	var err error
+	if fem.params.FindParameter(params.ElasticConstant) {
+		for k := range fem.params.Params {
+			if fem.params.Params[k].Type != params.ElasticConstant || !fem.inSet(&fem.params.Params[k], mesh.SetFE, index) {
+				continue
+			}
+			ok, err := fem.params.Params[k].GetPredicate(cx, &fem.params.Variables)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			value, err := fem.params.Params[k].GetValue(cx, &fem.params.Variables)
+			if err != nil {
+				return err
+			}
+			component := fem.params.Params[k].Direct
+			if component < 0 || component >= 36 {
+				return fmt.Errorf("wrong index of the elastic constant: %d", component)
+			}
+			row, column := component/6, component%6
+			if feParams.Anisotropic == nil {
+				feParams.Anisotropic = mat.NewDense(6, 6, nil)
+			}
+			feParams.Anisotropic.Set(row, column, value)
+			feParams.Anisotropic.Set(column, row, value)
+		}
+	}
+	if fem.params.FindParameter(params.YoungModulus1) {
+		m := &feParams.Orthotropic
+		for _, p := range []struct {
+			pType int
+			value *float64
+		}{
+			{params.YoungModulus1, &m.E1},
+			{params.YoungModulus2, &m.E2},
+			{params.YoungModulus3, &m.E3},
+			{params.ShearModulus12, &m.G12},
+			{params.ShearModulus13, &m.G13},
+			{params.ShearModulus23, &m.G23},
+			{params.PoissonRatio12, &m.Nu12},
+			{params.PoissonRatio13, &m.Nu13},
+			{params.PoissonRatio23, &m.Nu23},
+		} {
+			if *p.value, err = fem.paramValue(mesh.SetFE, index, cx, p.pType); err != nil {
+				return err
+			}
+		}
+		if m.E1 != 0 && (m.E2 == 0 || m.E3 == 0 || m.G12 == 0 || m.G13 == 0 || m.G23 == 0) {
+			return fmt.Errorf("orthotropic material constants are not set")
+		}
+	}
+	if feParams.MaterialAxis, err = fem.vectorParameter(index, cx, params.MaterialAxis); err != nil {
+		return err
+	}
+	feParams.MaterialPlane, err = fem.vectorParameter(index, cx, params.MaterialPlane)
+	return err
+}
+
+// laminate - plies of the index-th shell element with the center cx
+func (fem *StaticFEM) laminate(index int, cx *mat.VecDense, feParams *fe.FiniteElementParameters) error {
+	for i := range fem.laminates {
+		if !fem.inSet(&fem.laminates[i].predicate, mesh.SetFE, index) {
+			continue
+		}
+		ok, err := fem.laminates[i].predicate.GetPredicate(cx, &fem.params.Variables)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		feParams.Laminate = fem.laminates[i].plies
+		feParams.Thickness = 0
+		for _, ply := range feParams.Laminate {
+			if ply.Thickness <= 0 || ply.Material.E1 <= 0 || ply.Material.E2 <= 0 || ply.Material.G12 <= 0 ||
+				ply.Material.G13 <= 0 || ply.Material.G23 <= 0 {
+				return fmt.Errorf("wrong ply of the laminate")
+			}
+			feParams.Thickness += ply.Thickness
+		}
+		break
+	}
+	return nil
+}
+
+// maxPlies - the largest number of plies of the laminates
+func (fem *StaticFEM) maxPlies() int {
+	var res int
+	for i := range fem.laminates {
+		if len(fem.laminates[i].plies) > res {
+			res = len(fem.laminates[i].plies)
+		}
+	}
+	return res
+}
+
+// calcTemperature - temperatures of the nodes in the current numbering, they are needed only if the thermal
+// expansion coefficient is set
+func (fem *StaticFEM) calcTemperature() error {
+	fem.temperature = nil
+	if !fem.params.FindParameter(params.ThermalExpansion) {
+		return nil
+	}
+	if fem.temperatureField != nil {
+		fem.temperature = fem.mesh.PermuteNodeValues(fem.temperatureField)
+		return nil
+	}
+	if !fem.params.FindParameter(params.Temperature) {
+		return nil
+	}
+	fem.temperature = make([]float64, fem.mesh.NumVertex())
+	for i := range fem.temperature {
+		value, err := fem.paramValue(mesh.SetNode, i, mat.NewVecDense(len(fem.mesh.X[i]), fem.mesh.X[i]), params.Temperature)
+		if err != nil {
+			return err
+		}
+		fem.temperature[i] = value
+	}
+	return nil
+}
+
+// addThermalLoad - equivalent nodal forces of the thermal strains
+func (fem *StaticFEM) addThermalLoad() error {
+	if err := fem.calcTemperature(); err != nil || fem.temperature == nil {
+		return err
+	}
+	freedom := fem.mesh.Freedom()
+	msg := progress.NewProgress("Calculation of thermal loads", 0, fem.mesh.NumFE(), 10)
+	for i := 0; i < fem.mesh.NumFE(); i++ {
+		msg.AddProgress()
+		elm, err := fem.createFE(i)
+		if err != nil {
+			return err
+		}
+		load := elm.Load()
+		for j := range fem.mesh.FE[i] {
+			for k := 0; k < freedom; k++ {
+				fem.solver.AddVector(fem.mesh.FE[i][j]*freedom+k, load.AtVec(j*freedom+k))
+			}
+		}
+	}
+	return nil
+}
+
 func (fem *StaticFEM) numResult() int {
 	var res int
 	switch fem.mesh.FeType {
 	case mesh.Fe1d2:
 		res = 3 // U, Exx, Sxx
-	case mesh.Fe2d3:
+	case mesh.Fe2d3, mesh.Fe2d6:
 		fallthrough
 	case mesh.Fe2d4:
 		res = 8 // U, V, Exx, Eyy, Exy, Sxx, Syy, Sxy
-	case mesh.Fe3d4:
+		if fem.mode2D == fe.Axisymmetric {
+			res = 10 // U, V, Exx, Eyy, Exy, Ett, Sxx, Syy, Sxy, Stt
+		}
+	case mesh.Fe3d4, mesh.Fe3d6, mesh.Fe3d10, mesh.Fe3d20, mesh.Fe3d27:
 		fallthrough
 	case mesh.Fe3d8:
 		res = 15 // U, V, W, Exx, Eyy, Ezz, Exy, Exz, Eyz, Sxx, Syy, Szz, Sxy, Sxz, Syz
 	case mesh.Fe3d3s:
 		fallthrough
 	case mesh.Fe3d4s:
-		res = 18 // U, V, W, Tx, Ty, Tz, Exx, Eyy, Ezz, Exy, Exz, Eyz, Sxx, Syy, Szz, Sxy, Sxz, Syz
+		// U, V, W, Tx, Ty, Tz, Exx, Eyy, Ezz, Exy, Exz, Eyz, Sxx, Syy, Szz, Sxy, Sxz, Syz and S11, S22, S12, TW, MS
+		// of each ply
+		res = 18 + 5*fem.maxPlies()
+	case mesh.Fe2d2t:
+		res = 5 // U, V, Exx, Sxx, N
+	case mesh.Fe3d2t:
+		res = 6 // U, V, W, Exx, Sxx, N
+	case mesh.Fe2d2b:
+		res = 6 // U, V, Tz, N, Qy, Mz
+	case mesh.Fe3d2b:
+		res = 12 // U, V, W, Tx, Ty, Tz, N, Qy, Qz, Mx, My, Mz
 	}
 	return res
 }
@@ -596,11 +1119,14 @@ func (fem *StaticFEM) ResultNames() *[]string {
 	switch fem.mesh.FeType {
 	case mesh.Fe1d2:
 		res = []string{"U", "Exx", "Sxx"}
-	case mesh.Fe2d3:
+	case mesh.Fe2d3, mesh.Fe2d6:
 		fallthrough
 	case mesh.Fe2d4:
 		res = []string{"U", "V", "Exx", "Eyy", "Exy", "Sxx", "Syy", "Sxy"}
-	case mesh.Fe3d4:
+		if fem.mode2D == fe.Axisymmetric {
+			res = []string{"U", "V", "Exx", "Eyy", "Exy", "Ett", "Sxx", "Syy", "Sxy", "Stt"}
+		}
+	case mesh.Fe3d4, mesh.Fe3d6, mesh.Fe3d10, mesh.Fe3d20, mesh.Fe3d27:
 		fallthrough
 	case mesh.Fe3d8:
 		res = []string{"U", "V", "W", "Exx", "Eyy", "Ezz", "Exy", "Exz", "Eyz", "Sxx", "Syy", "Szz", "Sxy", "Sxz", "Syz"}
@@ -608,17 +1134,39 @@ func (fem *StaticFEM) ResultNames() *[]string {
 		fallthrough
 	case mesh.Fe3d4s:
 		res = []string{"U", "V", "W", "Tx", "Ty", "Tz", "Exx", "Eyy", "Ezz", "Exy", "Exz", "Eyz", "Sxx", "Syy", "Szz", "Sxy", "Sxz", "Syz"}
+		for i := 1; i <= fem.maxPlies(); i++ {
+			for _, name := range []string{"S11", "S22", "S12", "TW", "MS"} {
+				res = append(res, fmt.Sprintf("%s_%d", name, i))
+			}
+		}
+	case mesh.Fe2d2t:
+		res = []string{"U", "V", "Exx", "Sxx", "N"}
+	case mesh.Fe3d2t:
+		res = []string{"U", "V", "W", "Exx", "Sxx", "N"}
+	case mesh.Fe2d2b:
+		res = []string{"U", "V", "Tz", "N", "Qy", "Mz"}
+	case mesh.Fe3d2b:
+		res = []string{"U", "V", "W", "Tx", "Ty", "Tz", "N", "Qy", "Qz", "Mx", "My", "Mz"}
 	}
 	return &res
 }
 
+// feDisplacement - the displacement vector of the index-th finite element
+func (fem *StaticFEM) feDisplacement(index int, u *mat.VecDense) *mat.VecDense {
+	res := mat.NewVecDense(len(fem.mesh.FE[index])*fem.mesh.Freedom(), nil)
+	for j := range fem.mesh.FE[index] {
+		for k := 0; k < fem.mesh.Freedom(); k++ {
+			res.SetVec(j*fem.mesh.Freedom()+k, u.AtVec(fem.mesh.Freedom()*fem.mesh.FE[index][j]+k))
+		}
+	}
+	return res
+}
+
 func (fem *StaticFEM) calcResult(u *mat.VecDense) error {
-	var err error
 	//var mt sync.Mutex
 	fem.res = mat.NewDense(fem.numResult(), fem.mesh.NumVertex(), nil)
 	counter := make([]int32, fem.mesh.NumVertex())
 	data := make(chan MatrixData, fem.params.NumThread)
-	errChan := make(chan error, fem.params.NumThread)
 	done := make(chan struct{})
 	// Copy the calculation save
 	for i := 0; i < fem.mesh.NumVertex(); i++ {
@@ -628,13 +1176,13 @@ func (fem *StaticFEM) calcResult(u *mat.VecDense) error {
 	}
 	// Calculation of standard save for all FE
 	go func() {
-		var local MatrixData
 		msg := progress.NewProgress("Calculation of standard FE save", 0, fem.mesh.NumFE(), 10)
-		for k := 0; k < fem.mesh.NumFE(); k++ {
+		for local := range data {
 			msg.AddProgress()
-			local = <-data
-			for i := 0; i < fem.numResult()-fem.mesh.Freedom(); i++ {
-				for j := 0; j < fem.mesh.FeSize(); j++ {
+			// The elements with fewer plies than the other ones have no results of the missing plies
+			rows, _ := local.matrix.Dims()
+			for i := 0; i < fem.numResult()-fem.mesh.Freedom() && i < rows; i++ {
+				for j := range fem.mesh.FE[local.index] {
 					//mt.Lock()
 					fem.res.Set(i+fem.mesh.Freedom(), fem.mesh.FE[local.index][j], fem.res.At(i+fem.mesh.Freedom(), fem.mesh.FE[local.index][j])+local.matrix.At(i, j))
 					//mt.Unlock()
@@ -646,40 +1194,22 @@ func (fem *StaticFEM) calcResult(u *mat.VecDense) error {
 		}
 		done <- struct{}{}
 	}()
-
-	step := fem.mesh.NumFE() / fem.params.NumThread
-	for n := 0; n < fem.params.NumThread; n++ {
-		begin := n * step
-		end := (n + 1) * step
-		if n == fem.params.NumThread-1 {
-			end = fem.mesh.NumFE()
-		}
-		go func() {
-			var elm fe.FiniteElement
-			defer func() {
-				errChan <- err
-			}()
-			for i := begin; i < end; i++ {
-				if elm, err = fem.createFE(i); err != nil {
-					errChan <- err
-					return
-				}
-				// Form the displacement vector for the current FE
-				feU := mat.NewVecDense(fem.mesh.FeSize()*fem.mesh.Freedom(), nil)
-				for j := 0; j < fem.mesh.FeSize(); j++ {
-					for k := 0; k < fem.mesh.Freedom(); k++ {
-						feU.SetVec(j*fem.mesh.Freedom()+k, u.AtVec(fem.mesh.Freedom()*fem.mesh.FE[i][j]+k))
-					}
-				}
-				feRes := elm.Calculate(feU)
-				data <- MatrixData{index: i, matrix: feRes}
+	err := fem.parallel(fem.mesh.NumFE(), func(begin, end int) error {
+		for i := begin; i < end; i++ {
+			elm, err := fem.createFE(i)
+			if err != nil {
+				return err
 			}
-		}()
-	}
-	if err = <-errChan; err != nil {
+			feRes := elm.Calculate(fem.feDisplacement(i, u))
+			data <- MatrixData{index: i, matrix: feRes}
+		}
+		return nil
+	})
+	close(data)
+	<-done
+	if err != nil {
 		return err
 	}
-	<-done
 	// Average save
 	for i := fem.mesh.Freedom(); i < fem.numResult(); i++ {
 		for j := 0; j < fem.mesh.NumVertex(); j++ {
@@ -766,6 +1296,24 @@ func (fem *StaticFEM) calcResult(u *mat.VecDense) error {
 //}
 
 func (fem *StaticFEM) SaveResult(name string) error {
+	return fem.saveResult(name, *fem.ResultNames(), nil)
+}
+
+// saveResult - writing the mesh and the results (rows of fem.res) in the .res format or, by the extension, in the
+// Gmsh MSH-file, the VTU-file or the PVD-collection, times[i] is saved with the i-th function (zero for static results).
+// The .res format has one type of the finite elements per mesh
+func (fem *StaticFEM) saveResult(name string, names []string, times []float64) error {
+	switch strings.ToUpper(filepath.Ext(name)) {
+	case ".MSH":
+		return fem.saveMsh(name, names, times)
+	case ".VTU":
+		return fem.saveVtu(name, names)
+	case ".PVD":
+		return fem.savePvd(name, names, times)
+	}
+	if fem.mesh.Types != nil {
+		return fmt.Errorf("the results of the mesh with the finite elements of different types cannot be written in the RES-file")
+	}
 	file, err := os.Create(name)
 	if err != nil {
 		return fmt.Errorf("error creating result file")
@@ -831,15 +1379,19 @@ func (fem *StaticFEM) SaveResult(name string) error {
 	if _, err = fmt.Fprintf(file, "%02d.%02d.%4d - %02d:%02d:%02d\n", now.Day(), now.Month(), now.Year(), now.Hour(), now.Minute(), now.Second()); err != nil {
 		return err
 	}
-	if _, err = fmt.Fprintf(file, "%d\n", fem.numResult()); err != nil {
+	rows, cols := fem.res.Dims()
+	if _, err = fmt.Fprintf(file, "%d\n", rows); err != nil {
 		return err
 	}
-	rows, cols := fem.res.Dims()
 	for i := 0; i < rows; i++ {
-		if _, err = fmt.Fprintf(file, "%s\n", (*fem.ResultNames())[i]); err != nil {
+		if _, err = fmt.Fprintf(file, "%s\n", names[i]); err != nil {
 			return err
 		}
-		if _, err = fmt.Fprintf(file, "0\n%d\n", cols); err != nil {
+		t := 0.0
+		if times != nil {
+			t = times[i]
+		}
+		if _, err = fmt.Fprintf(file, "%g\n%d\n", t, cols); err != nil {
 			return err
 		}
 		for j := 0; j < cols; j++ {
@@ -851,6 +1403,234 @@ func (fem *StaticFEM) SaveResult(name string) error {
 	return nil
 }
 
+// saveMsh - the results are written as the node views of the MSH-file: the displacements U, V, W as the vector
+// view and every other function as the scalar one. The repeated functions are the steps of their views
+func (fem *StaticFEM) saveMsh(name string, names []string, times []float64) error {
+	var views []mesh.MshView
+	steps := make(map[string]int)
+	for i := 0; i < len(names); i++ {
+		t := 0.0
+		if times != nil {
+			t = times[i]
+		}
+		view := mesh.MshView{Name: names[i], Time: t, Values: make([][]float64, fem.mesh.NumVertex())}
+		components := []int{i}
+		if names[i] == "U" {
+			view.Name = "Displacement"
+			for _, next := range []string{"V", "W"} {
+				if i+1 < len(names) && names[i+1] == next {
+					i++
+					components = append(components, i)
+				}
+			}
+		}
+		for j := range view.Values {
+			for _, k := range components {
+				view.Values[j] = append(view.Values[j], fem.res.At(k, j))
+			}
+			if view.Name == "Displacement" {
+				// Gmsh vectors have three components
+				view.Values[j] = append(view.Values[j], make([]float64, 3-len(components))...)
+			}
+		}
+		view.Step = steps[view.Name]
+		steps[view.Name]++
+		views = append(views, view)
+	}
+	return fem.mesh.SaveMsh(name, views...)
+}
+
+// frames - the rows of the results of every step: the names of the functions are repeated for every step
+func frames(names []string) [][]int {
+	size := len(names)
+	for i := 1; i < len(names); i++ {
+		if names[i] == names[0] {
+			size = i
+			break
+		}
+	}
+	var res [][]int
+	for begin := 0; begin < len(names); begin += size {
+		var rows []int
+		for i := begin; i < begin+size && i < len(names); i++ {
+			rows = append(rows, i)
+		}
+		res = append(res, rows)
+	}
+	return res
+}
+
+// vtkArrays - the point data of the step (rows of the results): the displacements and the rotations are the vectors,
+// the strains and the stresses are the tensors (the tensor shear strains are the halves of the engineering ones,
+// the hoop components of the axisymmetric problem are zz), the other functions are the scalars. suffix is added to
+// the names of the arrays
+func (fem *StaticFEM) vtkArrays(names []string, rows []int, suffix string) []mesh.VtkArray {
+	// The components of the vectors (X, Y, Z) and of the tensors (xx, xy, xz, yx, yy, yz, zx, zy, zz) with the factors
+	type group struct {
+		name       string
+		components [][]string
+		factor     []float64
+	}
+	tensor := func(name, prefix string, shear float64) group {
+		return group{name: name, components: [][]string{{prefix + "xx"}, {prefix + "xy"}, {prefix + "xz"}, {prefix + "xy"},
+			{prefix + "yy"}, {prefix + "yz"}, {prefix + "xz"}, {prefix + "yz"}, {prefix + "zz", prefix + "tt"}},
+			factor: []float64{1.0, shear, shear, shear, 1.0, shear, shear, shear, 1.0}}
+	}
+	groups := []group{
+		{name: "Displacement", components: [][]string{{"U"}, {"V"}, {"W"}}, factor: []float64{1.0, 1.0, 1.0}},
+		{name: "Rotation", components: [][]string{{"Tx"}, {"Ty"}, {"Tz"}}, factor: []float64{1.0, 1.0, 1.0}},
+		tensor("Strain", "E", 0.5),
+		tensor("Stress", "S", 1.0),
+	}
+	row := make(map[string]int)
+	for _, i := range rows {
+		row[names[i]] = i
+	}
+	used := make(map[int]bool)
+	var res []mesh.VtkArray
+	for _, g := range groups {
+		index := make([]int, len(g.components))
+		isFound := false
+		for k, c := range g.components {
+			index[k] = -1
+			for _, componentName := range c {
+				if i, ok := row[componentName]; ok {
+					index[k] = i
+					used[i] = true
+					isFound = true
+				}
+			}
+		}
+		if !isFound {
+			continue
+		}
+		a := mesh.VtkArray{Name: g.name + suffix, Values: make([][]float64, fem.mesh.NumVertex())}
+		for j := range a.Values {
+			a.Values[j] = make([]float64, len(index))
+			for k, i := range index {
+				if i >= 0 {
+					a.Values[j][k] = g.factor[k] * fem.res.At(i, j)
+				}
+			}
+		}
+		res = append(res, a)
+	}
+	for _, i := range rows {
+		if used[i] {
+			continue
+		}
+		a := mesh.VtkArray{Name: names[i] + suffix, Values: make([][]float64, fem.mesh.NumVertex())}
+		for j := range a.Values {
+			a.Values[j] = []float64{fem.res.At(i, j)}
+		}
+		res = append(res, a)
+	}
+	return res
+}
+
+// saveVtu - all steps of the results are written in one VTU-file, the names of the arrays of the steps are
+// numbered if there are several ones
+func (fem *StaticFEM) saveVtu(name string, names []string) error {
+	var arrays []mesh.VtkArray
+	steps := frames(names)
+	for k, rows := range steps {
+		suffix := ""
+		if len(steps) > 1 {
+			suffix = fmt.Sprintf("_%d", k+1)
+		}
+		arrays = append(arrays, fem.vtkArrays(names, rows, suffix)...)
+	}
+	return fem.mesh.SaveVtu(name, fem.vtkBinary, arrays...)
+}
+
+// savePvd - every step of the results is written in its own VTU-file next to the collection
+func (fem *StaticFEM) savePvd(name string, names []string, times []float64) error {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	var dataSets []mesh.PvdDataSet
+	for k, rows := range frames(names) {
+		fileName := fmt.Sprintf("%s_%d.vtu", base, k+1)
+		if err := fem.mesh.SaveVtu(fileName, fem.vtkBinary, fem.vtkArrays(names, rows, "")...); err != nil {
+			return err
+		}
+		t := 0.0
+		if times != nil {
+			t = times[rows[0]]
+		}
+		dataSets = append(dataSets, mesh.PvdDataSet{File: filepath.Base(fileName), Time: t})
+	}
+	return mesh.SavePvd(name, dataSets)
+}
+
+// loadResult - values of the function with the given name from the .res file
+func loadResult(name, function string) ([]float64, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file")
+	}
+	defer func() {
+		err = file.Close()
+	}()
+	scanner := bufio.NewScanner(file)
+	scanner.Split(bufio.ScanLines)
+	next := func() string {
+		scanner.Scan()
+		return strings.TrimSpace(scanner.Text())
+	}
+	count := func() (int, error) {
+		num, err := strconv.Atoi(next())
+		if err != nil {
+			return 0, fmt.Errorf("wrong result file format")
+		}
+		return num, nil
+	}
+	if next() != "FEM Solver Results File" || next() != "Mesh" {
+		return nil, fmt.Errorf("wrong result file format")
+	}
+	// Mesh: the name of the finite element type, the nodes, the finite and the boundary elements
+	next()
+	for i := 0; i < 3; i++ {
+		num, err := count()
+		if err != nil {
+			return nil, err
+		}
+		for j := 0; j < num; j++ {
+			next()
+		}
+	}
+	if next() != "Results" {
+		return nil, fmt.Errorf("wrong result file format")
+	}
+	// Date
+	next()
+	numFunction, err := count()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < numFunction; i++ {
+		funName := next()
+		// Time
+		next()
+		num, err := count()
+		if err != nil {
+			return nil, err
+		}
+		if funName != function {
+			for j := 0; j < num; j++ {
+				next()
+			}
+			continue
+		}
+		res := make([]float64, num)
+		for j := range res {
+			if res[j], err = strconv.ParseFloat(next(), 64); err != nil {
+				return nil, fmt.Errorf("wrong result file format")
+			}
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("function %s is not found in the result file", function)
+}
+
 func (fem *StaticFEM) GetMesh() *mesh.Mesh {
 	return &fem.mesh
 }
@@ -0,0 +1,1319 @@
+package fem
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"wfem/cmd/fem/fe"
+	"wfem/cmd/fem/params"
+	"wfem/cmd/fem/solver"
+
+	"golang.org/x/exp/slices"
+	"gonum.org/v1/gonum/mat"
+)
+
+// writeGrid - MESH-file of the box [0, 2] x [0, 1] (x [0, 1]) divided into nx x ny (x nz) quadrangles (hexahedra)
+// without the boundary elements
+func writeGrid(t *testing.T, nx, ny, nz int) string {
+	var b strings.Builder
+	node := func(i, j, k int) int {
+		return (k*(ny+1)+j)*(nx+1) + i
+	}
+	if nz == 0 {
+		fmt.Fprintf(&b, "fe2d4\n%d\n", (nx+1)*(ny+1))
+	} else {
+		fmt.Fprintf(&b, "fe3d8\n%d\n", (nx+1)*(ny+1)*(nz+1))
+	}
+	for k := 0; k <= nz; k++ {
+		for j := 0; j <= ny; j++ {
+			for i := 0; i <= nx; i++ {
+				x, y := 2.0*float64(i)/float64(nx), float64(j)/float64(ny)
+				if nz == 0 {
+					fmt.Fprintf(&b, "%g %g\n", x, y)
+				} else {
+					fmt.Fprintf(&b, "%g %g %g\n", x, y, float64(k)/float64(nz))
+				}
+			}
+		}
+	}
+	if nz == 0 {
+		fmt.Fprintf(&b, "%d\n", nx*ny)
+		for j := 0; j < ny; j++ {
+			for i := 0; i < nx; i++ {
+				fmt.Fprintf(&b, "%d %d %d %d\n", node(i, j, 0), node(i+1, j, 0), node(i+1, j+1, 0), node(i, j+1, 0))
+			}
+		}
+	} else {
+		fmt.Fprintf(&b, "%d\n", nx*ny*nz)
+		for k := 0; k < nz; k++ {
+			for j := 0; j < ny; j++ {
+				for i := 0; i < nx; i++ {
+					fmt.Fprintf(&b, "%d %d %d %d %d %d %d %d\n", node(i, j, k), node(i+1, j, k), node(i+1, j+1, k),
+						node(i, j+1, k), node(i, j, k+1), node(i+1, j, k+1), node(i+1, j+1, k+1), node(i, j+1, k+1))
+				}
+			}
+		}
+	}
+	b.WriteString("0\n")
+	name := filepath.Join(t.TempDir(), "grid.mesh")
+	if err := os.WriteFile(name, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+// writeMesh - MESH-file of the finite elements of the given type with the nodes x and the boundary elements be
+func writeMesh(t *testing.T, feType string, x [][]float64, fe, be [][]int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%d\n", feType, len(x))
+	for _, node := range x {
+		fmt.Fprintln(&b, strings.Trim(fmt.Sprint(node), "[]"))
+	}
+	for _, elements := range [][][]int{fe, be} {
+		fmt.Fprintf(&b, "%d\n", len(elements))
+		for _, elm := range elements {
+			fmt.Fprintln(&b, strings.Trim(fmt.Sprint(elm), "[]"))
+		}
+	}
+	name := filepath.Join(t.TempDir(), "test.mesh")
+	if err := os.WriteFile(name, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+// cantilever - displacements and stresses of the box fixed at x = 0 and bent by the point loads at x = 2
+func cantilever(t *testing.T, name, solverName string, opts ...solver.Option) *mat.Dense {
+	f := NewStaticFEM()
+	if err := f.SetMesh(name); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetSolver(solverName, opts...); err != nil {
+		t.Fatal(err)
+	}
+	f.AddYoungModulus("2.0e+5", "")
+	f.AddPoissonRatio("0.3", "")
+	f.AddThickness("0.1", "")
+	f.AddBoundaryCondition("0", "x == 0", params.X|params.Y|params.Z)
+	f.AddPointLoad("-1", "x == 2", params.Y)
+	f.AddPointLoad("0.5", "x == 2", params.X)
+	if err := f.Calculate(); err != nil {
+		t.Fatal(err)
+	}
+	return f.GetResult()
+}
+
+// compareResults - the greatest difference of the results related to the greatest value of each result
+func compareResults(t *testing.T, want, got *mat.Dense, eps float64) {
+	rows, cols := want.Dims()
+	if r, c := got.Dims(); r != rows || c != cols {
+		t.Fatalf("results are %dx%d, want %dx%d", r, c, rows, cols)
+	}
+	for i := 0; i < rows; i++ {
+		scale := 0.0
+		for j := 0; j < cols; j++ {
+			scale = math.Max(scale, math.Abs(want.At(i, j)))
+		}
+		for j := 0; j < cols; j++ {
+			if math.Abs(want.At(i, j)-got.At(i, j)) > eps*math.Max(scale, 1.0e-30) {
+				t.Fatalf("result %d of the node %d is %g, want %g", i, j, got.At(i, j), want.At(i, j))
+			}
+		}
+	}
+}
+
+func TestSolversMatchDense(t *testing.T) {
+	meshes := []struct {
+		name       string
+		nx, ny, nz int
+	}{
+		{"quadrangles", 8, 4, 0},
+		{"hexahedra", 4, 2, 2},
+	}
+	tests := []struct {
+		solver string
+		opts   []solver.Option
+		eps    float64
+	}{
+		{"sparse", nil, 1.0e-9},
+		{"sparse", []solver.Option{solver.WithBoundaryMethod(solver.Penalty)}, 1.0e-6},
+		{"pcg", []solver.Option{solver.WithEps(1.0e-12), solver.WithPreconditioner(solver.Jacobi)}, 1.0e-8},
+		{"pcg", []solver.Option{solver.WithEps(1.0e-12), solver.WithPreconditioner(solver.IncompleteCholesky)}, 1.0e-8},
+	}
+	for _, m := range meshes {
+		name := writeGrid(t, m.nx, m.ny, m.nz)
+		want := cantilever(t, name, "dense")
+		for k, test := range tests {
+			t.Run(fmt.Sprintf("%s/%s/%d", m.name, test.solver, k), func(t *testing.T) {
+				compareResults(t, want, cantilever(t, name, test.solver, test.opts...), test.eps)
+			})
+		}
+	}
+}
+
+// The body heated uniformly by dT and supported without constraining its expansion has the displacements
+// alpha dT x and no stresses
+func TestThermalExpansionFree(t *testing.T) {
+	const alpha, dt = 1.2e-5, 50.0
+	tests := []struct {
+		name       string
+		nx, ny, nz int
+		isLoaded   bool
+	}{
+		{"quadrangles", 4, 2, 0, false},
+		{"hexahedra", 4, 2, 2, false},
+		{"quadrangles/loaded", 4, 2, 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name := writeGrid(t, test.nx, test.ny, test.nz)
+			f := NewStaticFEM()
+			if err := f.SetMesh(name); err != nil {
+				t.Fatal(err)
+			}
+			if test.isLoaded {
+				// The temperature is taken from the results of the thermal analysis
+				thermal := NewThermalFEM()
+				if err := thermal.SetMesh(name); err != nil {
+					t.Fatal(err)
+				}
+				thermal.AddThickness("1", "")
+				thermal.AddConductivity("1", "")
+				thermal.AddFixedTemperature(fmt.Sprint(dt), "")
+				if err := thermal.Calculate(); err != nil {
+					t.Fatal(err)
+				}
+				res := filepath.Join(t.TempDir(), "thermal.res")
+				if err := thermal.SaveResult(res); err != nil {
+					t.Fatal(err)
+				}
+				if err := f.LoadTemperature(res); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				f.AddTemperature(fmt.Sprint(dt), "")
+			}
+			f.AddYoungModulus("2.0e+5", "")
+			f.AddPoissonRatio("0.3", "")
+			f.AddThickness("0.1", "")
+			f.AddThermalExpansion(fmt.Sprint(alpha), "")
+			f.AddBoundaryCondition("0", "x == 0", params.X)
+			f.AddBoundaryCondition("0", "y == 0", params.Y)
+			if test.nz > 0 {
+				f.AddBoundaryCondition("0", "z == 0", params.Z)
+			}
+			if err := f.Calculate(); err != nil {
+				t.Fatal(err)
+			}
+			res := f.GetResult()
+			dim := f.GetMesh().FeDim()
+			names := *f.ResultNames()
+			for i, x := range f.GetMesh().X {
+				for k := 0; k < dim; k++ {
+					if got, want := res.At(k, i), alpha*dt*x[k]; math.Abs(got-want) > 1.0e-9 {
+						t.Fatalf("%s of the node %d is %g, want %g", names[k], i, got, want)
+					}
+				}
+				for k := len(names) - 3*(dim-1); k < len(names); k++ {
+					if math.Abs(res.At(k, i)) > 1.0e-6 {
+						t.Fatalf("%s of the node %d is %g", names[k], i, res.At(k, i))
+					}
+				}
+			}
+		})
+	}
+}
+
+// writeRing - MESH-file of the ring a <= r <= b divided into nr x nt quadrangles: the sector [0, angle] of the
+// annulus in the plane or, if angle is zero, the rectangle [a, b] x [0, 1] in the axisymmetric coordinates (r, z),
+// the inner surface r = a is the boundary
+func writeRing(t *testing.T, a, b float64, nr, nt int, angle float64) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "fe2d4\n%d\n", (nr+1)*(nt+1))
+	for j := 0; j <= nt; j++ {
+		for i := 0; i <= nr; i++ {
+			r := a + (b-a)*float64(i)/float64(nr)
+			if angle == 0 {
+				fmt.Fprintf(&s, "%.15g %.15g\n", r, float64(j)/float64(nt))
+			} else {
+				phi := angle * float64(j) / float64(nt)
+				fmt.Fprintf(&s, "%.15g %.15g\n", r*math.Cos(phi), r*math.Sin(phi))
+			}
+		}
+	}
+	fmt.Fprintf(&s, "%d\n", nr*nt)
+	for j := 0; j < nt; j++ {
+		for i := 0; i < nr; i++ {
+			k := j*(nr+1) + i
+			fmt.Fprintf(&s, "%d %d %d %d\n", k, k+1, k+nr+2, k+nr+1)
+		}
+	}
+	fmt.Fprintf(&s, "%d\n", nt)
+	for j := 0; j < nt; j++ {
+		fmt.Fprintf(&s, "%d %d\n", (j+1)*(nr+1), j*(nr+1))
+	}
+	name := filepath.Join(t.TempDir(), "ring.mesh")
+	if err := os.WriteFile(name, []byte(s.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+// The radial displacement of the thick cylinder under the internal pressure p without the axial strain is
+// (1 + nu) / E ((1 - 2 nu) A r + B / r), A = p a^2 / (b^2 - a^2), B = p a^2 b^2 / (b^2 - a^2)
+func TestThickCylinder(t *testing.T) {
+	const a, b, p, e, nu = 1.0, 2.0, 100.0, 2.0e+5, 0.3
+	tests := []struct {
+		name  string
+		mode  int
+		angle float64
+	}{
+		{"plane strain", fe.PlaneStrain, 0.5 * math.Pi},
+		{"axisymmetric", fe.Axisymmetric, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := NewStaticFEM()
+			if err := f.SetMesh(writeRing(t, a, b, 16, 16, test.angle)); err != nil {
+				t.Fatal(err)
+			}
+			f.SetMode2D(test.mode)
+			f.AddYoungModulus(fmt.Sprint(e), "")
+			f.AddPoissonRatio(fmt.Sprint(nu), "")
+			f.AddThickness("1", "")
+			f.AddPressureLoad(fmt.Sprint(p), "")
+			if test.mode == fe.Axisymmetric {
+				f.AddBoundaryCondition("0", "", params.Y)
+			} else {
+				f.AddBoundaryCondition("0", "abs(x) < 1.0e-10", params.X)
+				f.AddBoundaryCondition("0", "abs(y) < 1.0e-10", params.Y)
+			}
+			if err := f.Calculate(); err != nil {
+				t.Fatal(err)
+			}
+			res := f.GetResult()
+			ca, cb := p*a*a/(b*b-a*a), p*a*a*b*b/(b*b-a*a)
+			scale := (1.0 + nu) / e * ((1.0-2.0*nu)*ca*a + cb/a)
+			for i, x := range f.GetMesh().X {
+				r, got := x[0], res.At(0, i)
+				if test.mode != fe.Axisymmetric {
+					r, got = math.Hypot(x[0], x[1]), math.Hypot(res.At(0, i), res.At(1, i))
+				}
+				want := (1.0 + nu) / e * ((1.0-2.0*nu)*ca*r + cb/r)
+				if math.Abs(got-want) > 5.0e-3*scale {
+					t.Fatalf("radial displacement at r = %g is %g, want %g", r, got, want)
+				}
+			}
+		})
+	}
+}
+
+// Two bars at 45 degrees hanging the load P: the axial forces are P / sqrt(2), the deflection is sqrt(2) P / (E A)
+func TestTruss(t *testing.T) {
+	const e, area, p = 2.0e+11, 1.0e-4, 1000.0
+	f := NewStaticFEM()
+	if err := f.SetMesh(writeMesh(t, "fe2d2t", [][]float64{{0, 0}, {-1, 1}, {1, 1}}, [][]int{{1, 0}, {0, 2}}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	f.AddYoungModulus(fmt.Sprint(e), "")
+	f.AddArea(fmt.Sprint(area), "")
+	f.AddBoundaryCondition("0", "y == 1", params.X|params.Y)
+	f.AddPointLoad(fmt.Sprint(-p), "y == 0", params.Y)
+	if err := f.Calculate(); err != nil {
+		t.Fatal(err)
+	}
+	res := f.GetResult()
+	if got, want := res.At(1, 0), -math.Sqrt2*p/(e*area); math.Abs(got-want) > 1.0e-9*math.Abs(want) {
+		t.Fatalf("deflection is %g, want %g", got, want)
+	}
+	if got, want := res.At(4, 0), p/math.Sqrt2; math.Abs(got-want) > 1.0e-9*want {
+		t.Fatalf("axial force is %g, want %g", got, want)
+	}
+}
+
+// The tip deflections of the cantilever frame are P L^3 / (3 E I), the bending moment at the support is P L
+func TestFrameCantilever(t *testing.T) {
+	const e, length, iy, iz, py, pz = 2.0e+11, 2.0, 2.0e-6, 5.0e-6, -100.0, -50.0
+	tests := []struct {
+		name   string
+		feType string
+	}{
+		{"plane", "fe2d2b"},
+		{"space", "fe3d2b"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var x [][]float64
+			var elements [][]int
+			for i := 0; i <= 4; i++ {
+				x = append(x, []float64{length * float64(i) / 4.0, 0})
+				if test.feType == "fe3d2b" {
+					x[i] = append(x[i], 0)
+				}
+				if i > 0 {
+					elements = append(elements, []int{i - 1, i})
+				}
+			}
+			f := NewStaticFEM()
+			if err := f.SetMesh(writeMesh(t, test.feType, x, elements, nil)); err != nil {
+				t.Fatal(err)
+			}
+			f.AddYoungModulus(fmt.Sprint(e), "")
+			f.AddPoissonRatio("0.3", "")
+			f.AddArea("1.0e-3", "")
+			f.AddInertia(fmt.Sprint(iy), "", params.Y)
+			f.AddInertia(fmt.Sprint(iz), "", params.Z)
+			f.AddTorsionConstant("1.0e-6", "")
+			f.AddBoundaryCondition("0", "x == 0", params.X|params.Y|params.Z|params.RX|params.RY|params.RZ)
+			f.AddPointLoad(fmt.Sprint(py), fmt.Sprintf("x == %g", length), params.Y)
+			want := map[int]float64{1: py * math.Pow(length, 3) / (3.0 * e * iz)}
+			if test.feType == "fe3d2b" {
+				// The local y axis of the bar is the global z by default, so the bending in y is about the local y
+				f.AddPointLoad(fmt.Sprint(pz), fmt.Sprintf("x == %g", length), params.Z)
+				want = map[int]float64{1: py * math.Pow(length, 3) / (3.0 * e * iy), 2: pz * math.Pow(length, 3) / (3.0 * e * iz)}
+			}
+			if err := f.Calculate(); err != nil {
+				t.Fatal(err)
+			}
+			res := f.GetResult()
+			names := *f.ResultNames()
+			for k, w := range want {
+				if got := res.At(k, 4); math.Abs(got-w) > 1.0e-9*math.Abs(w) {
+					t.Fatalf("%s at the tip is %g, want %g", names[k], got, w)
+				}
+			}
+			if test.feType == "fe2d2b" {
+				if got, w := math.Abs(res.At(5, 0)), math.Abs(py*length); math.Abs(got-w) > 1.0e-9*w {
+					t.Fatalf("moment at the support is %g, want %g", got, w)
+				}
+			}
+		})
+	}
+}
+
+// mshElement - element of the MSH-file written by writeMsh: its dimension, Gmsh type and nodes (from zero)
+type mshElement struct {
+	dim, elmType int
+	nodes        []int
+}
+
+// writeMsh - MSH-file (4.1, ASCII) of the nodes x and the elements, the consecutive elements of one type form a block
+func writeMsh(t *testing.T, x [][]float64, elements []mshElement) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "$MeshFormat\n4.1 0 8\n$EndMeshFormat\n$Nodes\n1 %d 1 %d\n3 1 0 %d\n", len(x), len(x), len(x))
+	for i := range x {
+		fmt.Fprintln(&b, i+1)
+	}
+	for _, node := range x {
+		fmt.Fprintln(&b, strings.Trim(fmt.Sprint(node), "[]"))
+	}
+	var blocks [][]mshElement
+	for i, e := range elements {
+		if i == 0 || e.dim != elements[i-1].dim || e.elmType != elements[i-1].elmType {
+			blocks = append(blocks, nil)
+		}
+		blocks[len(blocks)-1] = append(blocks[len(blocks)-1], e)
+	}
+	fmt.Fprintf(&b, "$EndNodes\n$Elements\n%d %d 1 %d\n", len(blocks), len(elements), len(elements))
+	tag := 0
+	for i, block := range blocks {
+		fmt.Fprintf(&b, "%d %d %d %d\n", block[0].dim, i+1, block[0].elmType, len(block))
+		for _, e := range block {
+			tag++
+			fmt.Fprint(&b, tag)
+			for _, n := range e.nodes {
+				fmt.Fprintf(&b, " %d", n+1)
+			}
+			fmt.Fprintln(&b)
+		}
+	}
+	fmt.Fprintf(&b, "$EndElements\n")
+	name := filepath.Join(t.TempDir(), "test.msh")
+	if err := os.WriteFile(name, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+// Natural coordinates of the nodes of the 27-node hexahedron in the Gmsh order, the first 20 nodes form the
+// serendipity hexahedron and the first 8 - the linear one
+var hexahedron27 = [][3]float64{
+	{-1, -1, -1}, {1, -1, -1}, {1, 1, -1}, {-1, 1, -1}, {-1, -1, 1}, {1, -1, 1}, {1, 1, 1}, {-1, 1, 1},
+	{0, -1, -1}, {-1, 0, -1}, {-1, -1, 0}, {1, 0, -1}, {1, -1, 0}, {0, 1, -1}, {1, 1, 0}, {-1, 1, 0},
+	{0, -1, 1}, {-1, 0, 1}, {1, 0, 1}, {0, 1, 1},
+	{0, 0, -1}, {0, -1, 0}, {-1, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {0, 0, 0},
+}
+
+// boxMesh - the nodes and the finite elements of the box made of the unit cubes [x0, x0 + 1] x [0, 1] x [z0, z0 + 1],
+// the nodes at the same points are shared
+type boxMesh struct {
+	x        [][]float64
+	index    map[[3]float64]int
+	elements []mshElement
+}
+
+func (m *boxMesh) node(p [3]float64) int {
+	if i, ok := m.index[p]; ok {
+		return i
+	}
+	m.index[p] = len(m.x)
+	m.x = append(m.x, []float64{p[0], p[1], p[2]})
+	return len(m.x) - 1
+}
+
+// add - the finite element of the Gmsh type with the nodes given by the coordinates in the cube (x0, z0)
+func (m *boxMesh) add(x0, z0 float64, elmType int, points ...[3]float64) {
+	elm := mshElement{dim: 3, elmType: elmType}
+	for _, p := range points {
+		elm.nodes = append(elm.nodes, m.node([3]float64{x0 + p[0], p[1], z0 + p[2]}))
+	}
+	m.elements = append(m.elements, elm)
+}
+
+// hexahedron - the cube as the hexahedron with 8, 20 or 27 nodes
+func (m *boxMesh) hexahedron(x0, z0 float64, size int) {
+	var points [][3]float64
+	for _, p := range hexahedron27[:size] {
+		points = append(points, [3]float64{0.5 * (p[0] + 1.0), 0.5 * (p[1] + 1.0), 0.5 * (p[2] + 1.0)})
+	}
+	m.add(x0, z0, map[int]int{8: 5, 20: 17, 27: 12}[size], points...)
+}
+
+// wedges - the cube divided into two wedges along z (isX = false) or x by the diagonal plane through the origin
+func (m *boxMesh) wedges(x0, z0 float64, isX bool) {
+	for _, tri := range [][3][2]float64{{{0, 0}, {1, 0}, {1, 1}}, {{0, 0}, {1, 1}, {0, 1}}} {
+		var points [][3]float64
+		for _, h := range []float64{0, 1} {
+			for _, p := range tri {
+				if isX {
+					points = append(points, [3]float64{h, p[0], p[1]})
+				} else {
+					points = append(points, [3]float64{p[0], p[1], h})
+				}
+			}
+		}
+		m.add(x0, z0, 6, points...)
+	}
+}
+
+// tetrahedra - the cube divided into six tetrahedra around its diagonal through the origin
+func (m *boxMesh) tetrahedra(x0, z0 float64) {
+	for _, axes := range [][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}} {
+		points := [][3]float64{{0, 0, 0}, {0, 0, 0}, {0, 0, 0}, {1, 1, 1}}
+		points[1][axes[0]]++
+		points[2] = points[1]
+		points[2][axes[1]]++
+		// The positive volume
+		a, b, c := points[1], points[2], points[3]
+		if a[0]*(b[1]*c[2]-b[2]*c[1])-a[1]*(b[0]*c[2]-b[2]*c[0])+a[2]*(b[0]*c[1]-b[1]*c[0]) < 0 {
+			points[1], points[2] = points[2], points[1]
+		}
+		m.add(x0, z0, 4, points...)
+	}
+}
+
+// face - adding the boundary elements of the faces of the elements on the plane x[axis] = value: the vertices of a face
+// are ordered around its center and followed by the middles of its edges and the center, if they are the nodes
+func (m *boxMesh) face(axis int, value float64) {
+	types := map[int]int{3: 2, 4: 3, 8: 16, 9: 10}
+	for _, elm := range m.elements[:len(m.elements):len(m.elements)] {
+		if elm.dim != 3 {
+			continue
+		}
+		size := map[int]int{4: 4, 5: 8, 6: 6, 17: 8, 12: 8}[elm.elmType]
+		var vertices [][3]float64
+		var center [3]float64
+		for _, n := range elm.nodes[:size] {
+			if x := m.x[n]; x[axis] == value {
+				vertices = append(vertices, [3]float64{x[0], x[1], x[2]})
+				for k := range center {
+					center[k] += x[k]
+				}
+			}
+		}
+		if len(vertices) < 3 {
+			continue
+		}
+		for k := range center {
+			center[k] /= float64(len(vertices))
+		}
+		u, v := (axis+1)%3, (axis+2)%3
+		sort.Slice(vertices, func(i, j int) bool {
+			return math.Atan2(vertices[i][v]-center[v], vertices[i][u]-center[u]) <
+				math.Atan2(vertices[j][v]-center[v], vertices[j][u]-center[u])
+		})
+		points := vertices
+		if len(vertices) == 4 {
+			for i := range vertices {
+				a, b := vertices[i], vertices[(i+1)%4]
+				points = append(points, [3]float64{0.5 * (a[0] + b[0]), 0.5 * (a[1] + b[1]), 0.5 * (a[2] + b[2])})
+			}
+			points = append(points, center)
+		}
+		face := mshElement{dim: 2}
+		for _, p := range points {
+			n, ok := m.index[p]
+			if !ok {
+				break
+			}
+			face.nodes = append(face.nodes, n)
+		}
+		face.elmType = types[len(face.nodes)]
+		m.elements = append(m.elements, face)
+	}
+}
+
+// The box stretched by the surface loads p in X and q in Z has the uniform stresses Sxx = p and Szz = q and the
+// linear displacements (p - nu q) x / E, -nu (p + q) y / E, (q - nu p) z / E. The mixed mesh is conforming: the
+// triangular faces meet the triangular ones with the same diagonals
+func TestPatchSolids(t *testing.T) {
+	const e, nu, p, q = 2.0e+5, 0.3, 10.0, 5.0
+	tests := []struct {
+		name   string
+		create func(m *boxMesh)
+	}{
+		{"wedges", func(m *boxMesh) { m.wedges(0, 0, false); m.wedges(1, 0, false) }},
+		{"serendipity hexahedra", func(m *boxMesh) { m.hexahedron(0, 0, 20); m.hexahedron(1, 0, 20) }},
+		{"Lagrange hexahedra", func(m *boxMesh) { m.hexahedron(0, 0, 27); m.hexahedron(0, 1, 27) }},
+		{"mixed", func(m *boxMesh) {
+			m.wedges(0, 0, false)
+			m.hexahedron(1, 0, 8)
+			m.tetrahedra(0, 1)
+			m.wedges(1, 1, true)
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := &boxMesh{index: make(map[[3]float64]int)}
+			test.create(m)
+			var length, height float64
+			for _, x := range m.x {
+				length, height = math.Max(length, x[0]), math.Max(height, x[2])
+			}
+			m.face(0, length)
+			m.face(2, height)
+			f := NewStaticFEM()
+			if err := f.SetMesh(writeMsh(t, m.x, m.elements)); err != nil {
+				t.Fatal(err)
+			}
+			f.AddYoungModulus(fmt.Sprint(e), "")
+			f.AddPoissonRatio(fmt.Sprint(nu), "")
+			f.AddBoundaryCondition("0", "x == 0", params.X)
+			f.AddBoundaryCondition("0", "y == 0", params.Y)
+			f.AddBoundaryCondition("0", "z == 0", params.Z)
+			f.AddSurfaceLoad(fmt.Sprint(p), fmt.Sprintf("x == %g", length), params.X)
+			f.AddSurfaceLoad(fmt.Sprint(q), fmt.Sprintf("z == %g", height), params.Z)
+			if err := f.Calculate(); err != nil {
+				t.Fatal(err)
+			}
+			res := f.GetResult()
+			names := *f.ResultNames()
+			for i, x := range f.GetMesh().X {
+				want := []float64{(p - nu*q) * x[0] / e, -nu * (p + q) * x[1] / e, (q - nu*p) * x[2] / e}
+				for k := range want {
+					if got := res.At(k, i); math.Abs(got-want[k]) > 1.0e-9 {
+						t.Fatalf("%s of the node %d is %g, want %g", names[k], i, got, want[k])
+					}
+				}
+				for k, want := range map[string]float64{"Sxx": p, "Syy": 0, "Szz": q, "Sxy": 0, "Sxz": 0, "Syz": 0} {
+					if got := res.At(slices.Index(names, k), i); math.Abs(got-want) > 1.0e-6*p {
+						t.Fatalf("%s of the node %d is %g, want %g", k, i, got, want)
+					}
+				}
+			}
+			if f.GetMesh().Types != nil {
+				if err := f.SaveResult(filepath.Join(t.TempDir(), "test.res")); err == nil {
+					t.Fatal("the results of the mixed mesh are saved in the RES-file")
+				}
+			}
+		})
+	}
+}
+
+// The orthotropic material axes 1, 2, 3 are the global y, z, x for the solid and y, -x, z for the plane elements, the
+// stress s in X causes the strains s / E3, -nu13 s / E1, -nu23 s / E2 of the solid and s / E2, -nu12 s / E1 of the
+// plane element along x, y, z
+func TestOrthotropicMaterial(t *testing.T) {
+	const e1, e2, e3, nu12, nu13, nu23, s = 1.5e+5, 1.0e+4, 8.0e+3, 0.3, 0.25, 0.4, 10.0
+	m := &boxMesh{index: make(map[[3]float64]int)}
+	m.hexahedron(0, 0, 8)
+	m.hexahedron(1, 0, 8)
+	m.face(0, 2)
+	tests := []struct {
+		name   string
+		mesh   string
+		strain []float64
+	}{
+		{"solid", writeMsh(t, m.x, m.elements), []float64{s / e3, -nu13 * s / e1, -nu23 * s / e2}},
+		{"plane", writeMesh(t, "fe2d4", [][]float64{{0, 0}, {1, 0}, {2, 0}, {0, 1}, {1, 1}, {2, 1}},
+			[][]int{{0, 1, 4, 3}, {1, 2, 5, 4}}, [][]int{{2, 5}}), []float64{s / e2, -nu12 * s / e1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := NewStaticFEM()
+			if err := f.SetMesh(test.mesh); err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range []struct {
+				value  float64
+				direct int
+			}{{e1, params.X}, {e2, params.Y}, {e3, params.Z}} {
+				f.AddOrthotropicYoungModulus(fmt.Sprint(c.value), "", c.direct)
+			}
+			f.AddShearModulus("5.0e+3", "", params.X|params.Y)
+			f.AddShearModulus("4.5e+3", "", params.X|params.Z)
+			f.AddShearModulus("3.0e+3", "", params.Y|params.Z)
+			f.AddOrthotropicPoissonRatio(fmt.Sprint(nu12), "", params.X|params.Y)
+			f.AddOrthotropicPoissonRatio(fmt.Sprint(nu13), "", params.X|params.Z)
+			f.AddOrthotropicPoissonRatio(fmt.Sprint(nu23), "", params.Y|params.Z)
+			f.AddMaterialAxis("1", "", params.Y)
+			f.AddMaterialPlane("1", "", params.Z)
+			f.AddThickness("1", "")
+			f.AddBoundaryCondition("0", "x == 0", params.X)
+			f.AddBoundaryCondition("0", "y == 0", params.Y)
+			if len(test.strain) == 3 {
+				f.AddBoundaryCondition("0", "z == 0", params.Z)
+			}
+			f.AddSurfaceLoad(fmt.Sprint(s), "x == 2", params.X)
+			if err := f.Calculate(); err != nil {
+				t.Fatal(err)
+			}
+			res := f.GetResult()
+			names := *f.ResultNames()
+			for i, x := range f.GetMesh().X {
+				for k := range test.strain {
+					if got, want := res.At(k, i), test.strain[k]*x[k]; math.Abs(got-want) > 1.0e-9 {
+						t.Fatalf("%s of the node %d is %g, want %g", names[k], i, got, want)
+					}
+				}
+				if got := res.At(slices.Index(names, "Sxx"), i); math.Abs(got-s) > 1.0e-6*s {
+					t.Fatalf("Sxx of the node %d is %g, want %g", i, got, s)
+				}
+			}
+		})
+	}
+}
+
+// cookMembrane - the vertical deflection of the upper corner of Cook's membrane (E = 1, nu = 1/3) of n x n
+// quadrangles: the tapered panel clamped at x = 0 and sheared by the unit load at x = 48
+func cookMembrane(t *testing.T, feType string, n int) float64 {
+	var x [][]float64
+	var elements [][]int
+	for j := 0; j <= n; j++ {
+		for i := 0; i <= n; i++ {
+			xi, eta := float64(i)/float64(n), float64(j)/float64(n)
+			x = append(x, []float64{48.0 * xi, (1.0-xi)*44.0*eta + xi*(44.0+16.0*eta)})
+			if feType == "fe3d4s" {
+				x[len(x)-1] = append(x[len(x)-1], 0)
+			}
+			if i < n && j < n {
+				k := j*(n+1) + i
+				elements = append(elements, []int{k, k + 1, k + n + 2, k + n + 1})
+			}
+		}
+	}
+	f := NewStaticFEM()
+	if err := f.SetMesh(writeMesh(t, feType, x, elements, nil)); err != nil {
+		t.Fatal(err)
+	}
+	f.AddYoungModulus("1", "")
+	f.AddPoissonRatio(fmt.Sprint(1.0/3.0), "")
+	f.AddThickness("1", "")
+	f.AddBoundaryCondition("0", "x == 0", params.X|params.Y|params.Z|params.RX|params.RY|params.RZ)
+	f.AddPointLoad(fmt.Sprint(1.0/float64(n)), "x == 48 and y > 44 and y < 60", params.Y)
+	f.AddPointLoad(fmt.Sprint(0.5/float64(n)), "x == 48 and (y == 44 or y == 60)", params.Y)
+	if err := f.Calculate(); err != nil {
+		t.Fatal(err)
+	}
+	return f.GetResult().At(1, len(x)-1)
+}
+
+// The drilling rotations do not stiffen the membrane of the shells: the deflection of Cook's membrane is that of the
+// plane stress quadrangles
+func TestCookMembrane(t *testing.T) {
+	for _, n := range []int{2, 4} {
+		if got, want := cookMembrane(t, "fe3d4s", n), cookMembrane(t, "fe2d4", n); math.Abs(got-want) > 5.0e-3*want {
+			t.Fatalf("deflection of the %d x %d shells is %g, want %g", n, n, got, want)
+		}
+	}
+}
+
+// The patch test of the thin shells: the deflection w = (x^2 + 2 x y - 3 y^2) / 2 with the Kirchhoff rotations
+// tx = w,y, ty = -w,x given on the boundary of the distorted patch is reproduced at its inner node, the stresses on
+// the top face are those of the constant curvatures (-w,xx, -w,yy, -2 w,xy) = (-1, 3, -2)
+func TestShellPatch(t *testing.T) {
+	const e, nu, thickness = 2.0e+11, 0.3, 0.01
+	x := [][]float64{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {0, 1, 0}, {0.9, 1.2, 0}, {2, 1, 0}, {0, 2, 0}, {1, 2, 0}, {2, 2, 0}}
+	quadrangles := [][]int{{0, 1, 4, 3}, {1, 2, 5, 4}, {3, 4, 7, 6}, {4, 5, 8, 7}}
+	var triangles [][]int
+	for _, q := range quadrangles {
+		triangles = append(triangles, []int{q[0], q[1], q[2]}, []int{q[0], q[2], q[3]})
+	}
+	tests := []struct {
+		name, feType string
+		formulation  int
+		elements     [][]int
+	}{
+		{"DKT", "fe3d3s", fe.DKT, triangles},
+		{"MITC4", "fe3d4s", fe.MITC4, quadrangles},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := NewStaticFEM()
+			if err := f.SetMesh(writeMesh(t, test.feType, x, test.elements, nil)); err != nil {
+				t.Fatal(err)
+			}
+			f.SetShellFormulation(test.formulation)
+			f.AddYoungModulus(fmt.Sprint(e), "")
+			f.AddPoissonRatio(fmt.Sprint(nu), "")
+			f.AddThickness(fmt.Sprint(thickness), "")
+			boundary := "x == 0 or x == 2 or y == 0 or y == 2"
+			f.AddBoundaryCondition("0", boundary, params.X|params.Y|params.RZ)
+			f.AddBoundaryCondition("(x**2 + 2*x*y - 3*y**2) / 2", boundary, params.Z)
+			f.AddBoundaryCondition("x - 3*y", boundary, params.RX)
+			f.AddBoundaryCondition("-(x + y)", boundary, params.RY)
+			if err := f.Calculate(); err != nil {
+				t.Fatal(err)
+			}
+			res := f.GetResult()
+			names := *f.ResultNames()
+			for k, want := range map[int]float64{2: (0.81 + 2.16 - 4.32) / 2.0, 3: 0.9 - 3.6, 4: -2.1} {
+				if got := res.At(k, 4); math.Abs(got-want) > 1.0e-6*math.Abs(want) {
+					t.Fatalf("%s of the inner node is %g, want %g", names[k], got, want)
+				}
+			}
+			d := 0.5 * thickness * e / (1.0 - nu*nu)
+			stress := map[string]float64{"Sxx": d * (-1.0 + 3.0*nu), "Syy": d * (3.0 - nu), "Sxy": -d * (1.0 - nu)}
+			for name, want := range stress {
+				for i := range x {
+					if got := res.At(slices.Index(names, name), i); math.Abs(got-want) > 1.0e-6*d {
+						t.Fatalf("%s of the node %d is %g, want %g", name, i, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+// patchGrid - the nodes of the cube [0, 2]^dim divided into 2^dim elements (fe2d4 or fe3d8) of different sizes by
+// the planes x = 0.9, y = 1.2 and z = 0.8 through the inner node
+func patchGrid(dim int) ([][]float64, [][]int) {
+	var x [][]float64
+	var elements [][]int
+	nz := dim - 2
+	node := func(i, j, k int) int {
+		return (k*3+j)*3 + i
+	}
+	lines := [][]float64{{0, 0.9, 2}, {0, 1.2, 2}, {0, 0.8, 2}}
+	for k := 0; k <= 2*nz; k++ {
+		for j := 0; j <= 2; j++ {
+			for i := 0; i <= 2; i++ {
+				x = append(x, []float64{lines[0][i], lines[1][j], lines[2][k]}[:dim])
+			}
+		}
+	}
+	for k := 0; k < 2*nz || k == 0; k++ {
+		for j := 0; j < 2; j++ {
+			for i := 0; i < 2; i++ {
+				elm := []int{node(i, j, k), node(i+1, j, k), node(i+1, j+1, k), node(i, j+1, k)}
+				if dim == 3 {
+					elm = append(elm, node(i, j, k+1), node(i+1, j, k+1), node(i+1, j+1, k+1), node(i, j+1, k+1))
+				}
+				elements = append(elements, elm)
+			}
+		}
+	}
+	return x, elements
+}
+
+// The patch test of the reduced and selective integration: the linear displacements given on the boundary of the
+// patch of the unequal elements are reproduced at its inner node, the hourglass stiffness does not spoil the constant
+// strains
+func TestPatchIntegration(t *testing.T) {
+	const e, nu = 2.0e+5, 0.3
+	grad := [][]float64{{1.0e-3, 2.0e-3, -1.0e-3}, {3.0e-3, -1.0e-3, 2.0e-3}, {-2.0e-3, 1.0e-3, 0.5e-3}}
+	tests := []struct {
+		name, feType string
+		dim          int
+		integration  int
+	}{
+		{"reduced fe2d4", "fe2d4", 2, fe.ReducedIntegration},
+		{"selective fe2d4", "fe2d4", 2, fe.SelectiveIntegration},
+		{"reduced fe3d8", "fe3d8", 3, fe.ReducedIntegration},
+		{"selective fe3d8", "fe3d8", 3, fe.SelectiveIntegration},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			x, elements := patchGrid(test.dim)
+			f := NewStaticFEM()
+			if err := f.SetMesh(writeMesh(t, test.feType, x, elements, nil)); err != nil {
+				t.Fatal(err)
+			}
+			f.SetIntegration(test.integration)
+			f.AddYoungModulus(fmt.Sprint(e), "")
+			f.AddPoissonRatio(fmt.Sprint(nu), "")
+			f.AddThickness("1", "")
+			boundary := "x == 0 or x == 2 or y == 0 or y == 2"
+			if test.dim == 3 {
+				boundary += " or z == 0 or z == 2"
+			}
+			for k, direct := range []int{params.X, params.Y, params.Z}[:test.dim] {
+				value := fmt.Sprintf("%g*x %+g*y", grad[k][0], grad[k][1])
+				if test.dim == 3 {
+					value += fmt.Sprintf(" %+g*z", grad[k][2])
+				}
+				f.AddBoundaryCondition(value, boundary, direct)
+			}
+			if err := f.Calculate(); err != nil {
+				t.Fatal(err)
+			}
+			res := f.GetResult()
+			names := *f.ResultNames()
+			inner := len(x) / 2
+			for k := 0; k < test.dim; k++ {
+				want := grad[k][0]*x[inner][0] + grad[k][1]*x[inner][1]
+				if test.dim == 3 {
+					want += grad[k][2] * x[inner][2]
+				}
+				if got := res.At(k, inner); math.Abs(got-want) > 1.0e-12 {
+					t.Fatalf("%s of the inner node is %g, want %g", names[k], got, want)
+				}
+			}
+			want := e / (1.0 - nu*nu) * (grad[0][0] + nu*grad[1][1])
+			if test.dim == 3 {
+				want = e / (1.0 + nu) / (1.0 - 2.0*nu) * ((1.0-nu)*grad[0][0] + nu*(grad[1][1]+grad[2][2]))
+			}
+			for i := range x {
+				if got := res.At(slices.Index(names, "Sxx"), i); math.Abs(got-want) > 1.0e-9*math.Abs(want) {
+					t.Fatalf("Sxx of the node %d is %g, want %g", i, got, want)
+				}
+			}
+		})
+	}
+	t.Run("mixed", func(t *testing.T) {
+		m := &boxMesh{index: make(map[[3]float64]int)}
+		m.wedges(0, 0, false)
+		m.hexahedron(1, 0, 8)
+		f := NewStaticFEM()
+		if err := f.SetMesh(writeMsh(t, m.x, m.elements)); err != nil {
+			t.Fatal(err)
+		}
+		f.SetIntegration(fe.ReducedIntegration)
+		f.AddYoungModulus(fmt.Sprint(e), "")
+		f.AddPoissonRatio(fmt.Sprint(nu), "")
+		f.AddBoundaryCondition("0", "x == 0", params.X|params.Y|params.Z)
+		if err := f.Calculate(); err == nil {
+			t.Fatal("the reduced integration of the wedges is accepted")
+		}
+	})
+}
+
+// discreteModel - two rods of the stiffness EA / L = 1 along x fixed at their outer ends and joined by the spring k
+// between the nodes 1 and 2, the node 2 is held by the grounded spring k2
+func discreteModel(t *testing.T, k, k2 float64) *StaticFEM {
+	f := NewStaticFEM()
+	if err := f.SetMesh(writeMesh(t, "fe1d2", [][]float64{{0}, {1}, {2}, {3}}, [][]int{{0, 1}, {2, 3}}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	f.AddYoungModulus("1", "")
+	f.AddPoissonRatio("0", "")
+	f.AddThickness("1", "")
+	f.AddBoundaryCondition("0", "x == 0 or x == 3", params.X)
+	f.AddNodeSpring(1, 2, [6]float64{k})
+	f.AddSpring(fmt.Sprint(k2), "x == 2", params.X)
+	f.AddPointLoad("1", "x == 1", params.X)
+	return &f
+}
+
+// The displacements of the rods joined by the springs satisfy (1 + k) u1 - k u2 = 1, -k u1 + (1 + k + k2) u2 = 0,
+// the rigid link instead of the springs makes u1 = u2 = 1 / 2
+func TestDiscrete(t *testing.T) {
+	const k, k2 = 2.0, 3.0
+	f := discreteModel(t, k, k2)
+	if err := f.Calculate(); err != nil {
+		t.Fatal(err)
+	}
+	u1 := (1.0 + k + k2) / ((1.0+k)*(1.0+k+k2) - k*k)
+	u2 := k * u1 / (1.0 + k + k2)
+	for i, want := range map[int]float64{1: u1, 2: u2} {
+		if got := f.GetResult().At(0, i); math.Abs(got-want) > 1.0e-9 {
+			t.Fatalf("U of the node %d is %g, want %g", i, got, want)
+		}
+	}
+
+	f = discreteModel(t, 0, 0)
+	f.AddRigidLink("x == 1", "x == 2")
+	if err := f.Calculate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{1, 2} {
+		if got := f.GetResult().At(0, i); math.Abs(got-0.5) > 1.0e-5 {
+			t.Fatalf("U of the linked node %d is %g, want 0.5", i, got)
+		}
+	}
+
+	f = discreteModel(t, 0, 0)
+	f.AddNodeRigidLink(1, []int{2})
+	if err := f.SetSolver("pcg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Calculate(); err == nil {
+		t.Fatal("no error for the rigid link with the pcg solver")
+	}
+}
+
+// The bar of two materials (E1 for x <= 1 and E2, nu = 0) stretched by the unit stress: the loads and the supports are
+// given by the sets, the end x = 2 moves by 1 / E1 + 1 / E2. A parameter referencing a missing set is an error
+func TestSets(t *testing.T) {
+	const e1, e2 = 1.0e+5, 2.0e+5
+	f := NewStaticFEM()
+	if err := f.SetMesh(writeGrid(t, 4, 2, 0)); err != nil {
+		t.Fatal(err)
+	}
+	for name, predicate := range map[string]string{"left": "x == 0", "right": "x == 2", "soft": "x <= 1"} {
+		if err := f.DefineSet(name, predicate); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.AddYoungModulus(fmt.Sprint(e1), "@soft")
+	f.AddYoungModulus(fmt.Sprint(e2), "")
+	f.AddPoissonRatio("0", "")
+	f.AddThickness("1", "")
+	f.AddBoundaryCondition("0", "@left", params.X)
+	f.AddBoundaryCondition("0", "@left: y == 0", params.Y)
+	f.AddPointLoad("0.25", "@right: y == 0 or y == 1", params.X)
+	f.AddPointLoad("0.5", "@ right : y == 0.5", params.X)
+	if err := f.Calculate(); err != nil {
+		t.Fatal(err)
+	}
+	res := f.GetResult()
+	for _, node := range []int{4, 9, 14} {
+		if got, want := res.At(0, node), 1/e1+1/e2; math.Abs(got-want) > 1.0e-9*want {
+			t.Fatalf("displacement of the node %d is %g, want %g", node, got, want)
+		}
+	}
+	if got := res.At(0, 2); math.Abs(got-1/e1) > 1.0e-9/e1 {
+		t.Fatalf("displacement at x = 1 is %g, want %g", got, 1/e1)
+	}
+
+	f.AddPointLoad("1", "@missing", params.Y)
+	if err := f.Calculate(); err == nil {
+		t.Fatal("no error for the missing set")
+	}
+}
+
+// mshViews - values of the node views of the MSH-file by their names
+func mshViews(t *testing.T, name string) map[string][][]float64 {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	views := make(map[string][][]float64)
+	blocks := strings.Split(string(data), "$NodeData\n")
+	for _, block := range blocks[1:] {
+		lines := strings.Split(strings.Split(block, "$EndNodeData")[0], "\n")
+		var values [][]float64
+		for _, line := range lines[8:] {
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			var row []float64
+			for _, field := range fields[1:] {
+				var v float64
+				if _, err = fmt.Sscan(field, &v); err != nil {
+					t.Fatal(err)
+				}
+				row = append(row, v)
+			}
+			values = append(values, row)
+		}
+		views[strings.Trim(lines[1], "\"")] = values
+	}
+	return views
+}
+
+// The plate stretched by the unit stress is written to the MSH-file: the displacements 2 / E, -nu / E of the corner
+// (2, 1) form the vector view with the zero third component and the stress Sxx = 1 is a scalar view
+func TestSaveResultMsh(t *testing.T) {
+	const e, nu = 1.0e+5, 0.3
+	f := NewStaticFEM()
+	if err := f.SetMesh(writeGrid(t, 2, 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	f.AddYoungModulus(fmt.Sprint(e), "")
+	f.AddPoissonRatio(fmt.Sprint(nu), "")
+	f.AddThickness("1", "")
+	f.AddBoundaryCondition("0", "x == 0", params.X)
+	f.AddBoundaryCondition("0", "x == 0 and y == 0", params.Y)
+	f.AddPointLoad("0.5", "x == 2", params.X)
+	if err := f.Calculate(); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(t.TempDir(), "plate.msh")
+	if err := f.SaveResult(name); err != nil {
+		t.Fatal(err)
+	}
+	views := mshViews(t, name)
+	displacement, stress := views["Displacement"], views["Sxx"]
+	if len(displacement) != 6 || len(stress) != 6 {
+		t.Fatalf("views are %v", views)
+	}
+	want := []float64{2 / e, -nu / e, 0}
+	for i := range want {
+		if got := displacement[5][i]; math.Abs(got-want[i]) > 1.0e-6*2/e {
+			t.Fatalf("displacement of the corner is %v, want %v", displacement[5], want)
+		}
+	}
+	for i := range stress {
+		if len(stress[i]) != 1 || math.Abs(stress[i][0]-1) > 1.0e-6 {
+			t.Fatalf("stress of the node %d is %v, want 1", i, stress[i])
+		}
+	}
+}
+
+// vtuArrays - values of the data arrays of the VTU-file written by SaveVtu by their names, the coordinates of the
+// points are the array with the empty name
+func vtuArrays(t *testing.T, name string) map[string][]float64 {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type dataArray struct {
+		XMLName xml.Name
+		Type    string `xml:"type,attr"`
+		Name    string `xml:"Name,attr"`
+		Format  string `xml:"format,attr"`
+		Data    string `xml:",chardata"`
+	}
+	var file struct {
+		Arrays []dataArray `xml:"UnstructuredGrid>Piece>PointData>DataArray"`
+		Points []dataArray `xml:"UnstructuredGrid>Piece>Points>DataArray"`
+		Cells  []dataArray `xml:"UnstructuredGrid>Piece>Cells>DataArray"`
+	}
+	if err = xml.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	res := make(map[string][]float64)
+	for _, a := range append(append(file.Arrays, file.Points...), file.Cells...) {
+		var values []float64
+		if a.Format == "binary" {
+			// The header of 8 bytes is encoded separately in 12 characters
+			text := strings.TrimSpace(a.Data)
+			bytes, err := base64.StdEncoding.DecodeString(text[12:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < len(bytes); {
+				switch a.Type {
+				case "Float64":
+					values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(bytes[i:])))
+					i += 8
+				case "Int64":
+					values = append(values, float64(int64(binary.LittleEndian.Uint64(bytes[i:]))))
+					i += 8
+				default:
+					values = append(values, float64(bytes[i]))
+					i++
+				}
+			}
+		} else {
+			for _, field := range strings.Fields(a.Data) {
+				var v float64
+				if _, err = fmt.Sscan(field, &v); err != nil {
+					t.Fatal(err)
+				}
+				values = append(values, v)
+			}
+		}
+		res[a.Name] = values
+	}
+	return res
+}
+
+// The plate [0, 2] x [0, 1] of the quadrangles or of the quadrangle and two triangles stretched by the unit stress
+// is written to the VTU-file: the displacements of the corner (2, 1) are 2 / E, -nu / E, the stress tensor is
+// Sxx = 1 and the strain tensor is Exx = 1 / E, Eyy = -nu / E at every node
+func TestSaveResultVtu(t *testing.T) {
+	const e, nu = 1.0e+5, 0.3
+	x := [][]float64{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {0, 1, 0}, {1, 1, 0}, {2, 1, 0}}
+	mixed := []mshElement{{2, 3, []int{0, 1, 4, 3}}, {2, 2, []int{1, 2, 5}}, {2, 2, []int{1, 5, 4}}}
+	tests := []struct {
+		name     string
+		mesh     func() string
+		isBinary bool
+		types    []float64
+	}{
+		{"quadrangles", func() string { return writeGrid(t, 2, 1, 0) }, false, []float64{9, 9}},
+		{"quadrangles binary", func() string { return writeGrid(t, 2, 1, 0) }, true, []float64{9, 9}},
+		{"mixed binary", func() string { return writeMsh(t, x, mixed) }, true, []float64{9, 5, 5}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := NewStaticFEM()
+			if err := f.SetMesh(test.mesh()); err != nil {
+				t.Fatal(err)
+			}
+			f.SetVtkBinary(test.isBinary)
+			f.AddYoungModulus(fmt.Sprint(e), "")
+			f.AddPoissonRatio(fmt.Sprint(nu), "")
+			f.AddThickness("1", "")
+			f.AddBoundaryCondition("0", "x == 0", params.X)
+			f.AddBoundaryCondition("0", "x == 0 and y == 0", params.Y)
+			f.AddPointLoad("0.5", "x == 2", params.X)
+			if err := f.Calculate(); err != nil {
+				t.Fatal(err)
+			}
+			name := filepath.Join(t.TempDir(), "plate.vtu")
+			if err := f.SaveResult(name); err != nil {
+				t.Fatal(err)
+			}
+			arrays := vtuArrays(t, name)
+			if !slices.Equal(arrays["types"], test.types) {
+				t.Fatalf("types of the cells are %v, want %v", arrays["types"], test.types)
+			}
+			displacement, strain, stress := arrays["Displacement"], arrays["Strain"], arrays["Stress"]
+			if len(displacement) != 3*6 || len(strain) != 9*6 || len(stress) != 9*6 {
+				t.Fatalf("arrays are %v", arrays)
+			}
+			check := func(what string, got, want []float64, eps float64) {
+				for i := range want {
+					if math.Abs(got[i]-want[i]) > eps {
+						t.Fatalf("%s is %v, want %v", what, got, want)
+					}
+				}
+			}
+			check("displacement of the corner", displacement[15:18], []float64{2 / e, -nu / e, 0}, 1.0e-6*2/e)
+			for j := 0; j < 6; j++ {
+				check(fmt.Sprintf("strain of the node %d", j), strain[9*j:9*j+9], []float64{1 / e, 0, 0, 0, -nu / e, 0, 0, 0, 0},
+					1.0e-6/e)
+				check(fmt.Sprintf("stress of the node %d", j), stress[9*j:9*j+9], []float64{1, 0, 0, 0, 0, 0, 0, 0, 0}, 1.0e-6)
+			}
+		})
+	}
+}
+
+// foldedPlate - the angle cantilever of the shells: the web [0, 8] x [0, 2] in the plane z = 0 with n elements across
+// its height and the flange [0, 8] x [0, 1] along z at its edge y = 2, clamped at x = 0 and sheared in -Y at x = 8 by
+// the unit load. The results of the shells and the index of the node (x, y, 0) of the web by its grid position are
+// returned
+func foldedPlate(t *testing.T, feType string, n int) (*mat.Dense, func(i, j int) int) {
+	const length, height, width = 8.0, 2.0, 1.0
+	nx, nf := 4*n, n/2
+	var x [][]float64
+	index := make(map[[3]int]int)
+	// The nodes on the grid of the step height / n, (i, j, k) - the position along x, y and z
+	node := func(i, j, k int) int {
+		key := [3]int{i, j, k}
+		if res, ok := index[key]; ok {
+			return res
+		}
+		h := height / float64(n)
+		index[key] = len(x)
+		x = append(x, []float64{float64(i) * length / float64(nx), float64(j) * h, float64(k) * h})
+		return index[key]
+	}
+	var elements [][]int
+	add := func(a, b, c, d int) {
+		if feType == "fe3d3s" {
+			elements = append(elements, []int{a, b, c}, []int{a, c, d})
+		} else {
+			elements = append(elements, []int{a, b, c, d})
+		}
+	}
+	for i := 0; i < nx; i++ {
+		for j := 0; j < n; j++ {
+			add(node(i, j, 0), node(i+1, j, 0), node(i+1, j+1, 0), node(i, j+1, 0))
+		}
+		for k := 0; k < nf; k++ {
+			add(node(i, n, k), node(i+1, n, k), node(i+1, n, k+1), node(i, n, k+1))
+		}
+	}
+	f := NewStaticFEM()
+	if err := f.SetMesh(writeMesh(t, feType, x, elements, nil)); err != nil {
+		t.Fatal(err)
+	}
+	f.AddYoungModulus("1000", "")
+	f.AddPoissonRatio("0.3", "")
+	f.AddThickness("0.1", "")
+	f.AddBoundaryCondition("0", "x == 0", params.X|params.Y|params.Z|params.RX|params.RY|params.RZ)
+	// The consistent nodal forces of the uniform shear of the web end
+	f.AddPointLoad(fmt.Sprint(-1.0/float64(n)), fmt.Sprintf("x == %g and z == 0 and y > 0 and y < %g", length, height), params.Y)
+	f.AddPointLoad(fmt.Sprint(-0.5/float64(n)), fmt.Sprintf("x == %g and z == 0 and (y == 0 or y == %g)", length, height), params.Y)
+	if err := f.Calculate(); err != nil {
+		t.Fatal(err)
+	}
+	return f.GetResult(), func(i, j int) int {
+		return index[[3]int{i, j, 0}]
+	}
+}
+
+// rotationError - the greatest difference of the drilling rotations Tz of the inner nodes of the middle 2 <= x <= 6 of
+// the web (away from the singularities at the support and at the load) from the membrane rotation (v,x - u,y) / 2 found
+// by the central differences, related to the greatest rotation
+func rotationError(res *mat.Dense, node func(i, j int) int, n int) float64 {
+	hx, hy := 2.0/float64(n), 2.0/float64(n)
+	var diff, scale float64
+	for i := n; i <= 3*n; i++ {
+		for j := 1; j < n; j++ {
+			vx := (res.At(1, node(i+1, j)) - res.At(1, node(i-1, j))) / (2.0 * hx)
+			uy := (res.At(0, node(i, j+1)) - res.At(0, node(i, j-1))) / (2.0 * hy)
+			omega := 0.5 * (vx - uy)
+			diff = math.Max(diff, math.Abs(res.At(5, node(i, j))-omega))
+			scale = math.Max(scale, math.Abs(omega))
+		}
+	}
+	return diff / scale
+}
+
+// The folded plate joins the drilling rotation of the web with the bending rotation of the flange: the tip deflections
+// of the triangles and of the quadrangles converge under the refinement and approach each other, the nodal rotations
+// Tz of the web converge to its membrane rotations. The deflections hardly depend on the stabilization of the drilling
+// rotations
+func TestFoldedPlate(t *testing.T) {
+	tip := func(res *mat.Dense, node func(i, j int) int, n int) float64 {
+		return res.At(1, node(4*n, n))
+	}
+	deflection := make(map[string][]float64)
+	for _, feType := range []string{"fe3d3s", "fe3d4s"} {
+		var errors []float64
+		for _, n := range []int{2, 4, 8} {
+			res, node := foldedPlate(t, feType, n)
+			deflection[feType] = append(deflection[feType], tip(res, node, n))
+			errors = append(errors, rotationError(res, node, n))
+		}
+		d := deflection[feType]
+		if math.Abs(d[2]-d[1]) > 0.7*math.Abs(d[1]-d[0]) {
+			t.Fatalf("deflections of %s do not converge: %v", feType, d)
+		}
+		if errors[2] > 0.5*errors[0] || errors[2] > 0.015 {
+			t.Fatalf("rotations Tz of %s differ from the membrane rotations by %v", feType, errors)
+		}
+	}
+	triangles, quadrangles := deflection["fe3d3s"], deflection["fe3d4s"]
+	if math.Abs(triangles[2]-quadrangles[2]) > 0.25*math.Abs(triangles[0]-quadrangles[0]) {
+		t.Fatalf("deflections of the triangles %v and of the quadrangles %v do not approach", triangles, quadrangles)
+	}
+
+	defer func(value float64) {
+		fe.DrillingStabilization = value
+	}(fe.DrillingStabilization)
+	for _, feType := range []string{"fe3d3s", "fe3d4s"} {
+		var d []float64
+		for _, value := range []float64{1.0e-2, 1.0} {
+			fe.DrillingStabilization = value
+			res, node := foldedPlate(t, feType, 8)
+			d = append(d, tip(res, node, 8))
+		}
+		if math.Abs(d[1]-d[0]) > 1.0e-2*math.Abs(d[0]) {
+			t.Fatalf("deflections of %s depend on the stabilization: %v", feType, d)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package fem
+
+import (
+	"fmt"
+	"math"
+	"time"
+	"wfem/cmd/fem/fe"
+	"wfem/cmd/fem/mesh"
+	"wfem/cmd/fem/params"
+	"wfem/cmd/fem/progress"
+	"wfem/cmd/fem/solver"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ThermalFEM - steady-state heat conduction: the temperature (one unknown per node) and the heat flux.
+// The fixed temperatures are the boundary conditions in the X direction
+type ThermalFEM struct {
+	StaticFEM
+}
+
+func NewThermalFEM() ThermalFEM {
+	return ThermalFEM{StaticFEM: NewStaticFEM()}
+}
+
+func (fem *ThermalFEM) AddConductivity(value, predicate string) {
+	fem.params.AddConductivity(value, predicate)
+}
+
+func (fem *ThermalFEM) AddFixedTemperature(value, predicate string) {
+	fem.params.AddBoundaryCondition(value, predicate, params.X)
+}
+
+// AddHeatSource - heat generated per unit volume
+func (fem *ThermalFEM) AddHeatSource(value, predicate string) {
+	fem.params.AddHeatSource(value, predicate)
+}
+
+// AddHeatFlux - heat flux per unit area of the boundary (positive value - heat entering the body)
+func (fem *ThermalFEM) AddHeatFlux(value, predicate string) {
+	fem.params.AddHeatFlux(value, predicate)
+}
+
+// AddConvection - heat transfer to the environment with the given temperature through the boundary
+func (fem *ThermalFEM) AddConvection(coefficient, temperature, predicate string) {
+	fem.params.AddConvection(coefficient, temperature, predicate)
+}
+
+func (fem *ThermalFEM) Calculate() error {
+	var err error
+	fmt.Printf("Using threads: %d\n", fem.params.NumThread)
+	start := time.Now()
+	switch fem.mesh.FeType {
+	case mesh.Fe2d3, mesh.Fe2d4, mesh.Fe2d6, mesh.Fe3d4, mesh.Fe3d6, mesh.Fe3d8, mesh.Fe3d10, mesh.Fe3d20, mesh.Fe3d27:
+	default:
+		return fmt.Errorf("heat conduction is not supported for %s", fem.mesh.FeName())
+	}
+	if fem.mesh.Is2D() && fem.mode2D == fe.Axisymmetric {
+		return fmt.Errorf("axisymmetric heat conduction is not supported")
+	}
+	fmt.Printf("Solver: %s\n", fem.solverName)
+	if err = fem.checkSets(); err != nil {
+		return err
+	}
+	fem.mesh.Renumber()
+	fem.res = nil
+	defer func() {
+		if fem.res != nil {
+			fem.mesh.RestoreNumbering(fem.res)
+		} else {
+			fem.mesh.RestoreNumbering()
+		}
+	}()
+	opts := append([]solver.Option{solver.WithEps(fem.params.Eps)}, fem.solverOptions...)
+	if fem.solver, err = solver.New(fem.solverName, &fem.mesh, append(opts, solver.WithFreedom(1))...); err != nil {
+		return err
+	}
+	if err = fem.assemble(fem.solver, 1, "Building a global conductivity matrix", func(index int) (*mat.Dense, error) {
+		elm, err := fem.createThermalFE(index)
+		if err != nil {
+			return nil, err
+		}
+		return elm.Create(), nil
+	}); err != nil {
+		return err
+	}
+	if err = fem.addVolumeParameter(params.HeatSource, "Calculation of heat sources", 1, fem.solver.AddVector); err != nil {
+		return err
+	}
+	if err = fem.addSurfaceParameter("Calculation of heat fluxes", 1, fem.solver.AddVector, params.HeatFlux); err != nil {
+		return err
+	}
+	if err = fem.addConvection(); err != nil {
+		return err
+	}
+	if err = fem.addNodeParameter(params.BoundaryCondition, "Using of boundary conditions", 1, fem.solver.SetBoundaryCondition); err != nil {
+		return err
+	}
+	x, err := fem.solver.Solve()
+	if err != nil {
+		return err
+	}
+	if err = fem.calcResult(x); err != nil {
+		return err
+	}
+	fem.printSummary()
+	duration := time.Since(start)
+	fmt.Printf("Lead time: %0.2f sec\n\n", duration.Seconds())
+	return nil
+}
+
+// addConvection - the heat transfer h (T - Ta) through the boundary elements: the matrix of h N' N and the vector of
+// h Ta N are integrated over the boundary element, h and Ta are evaluated at the integration points
+func (fem *ThermalFEM) addConvection() error {
+	if !fem.params.FindParameter(params.Convection) {
+		return nil
+	}
+	msg := progress.NewProgress("Calculation of convection", 0, fem.mesh.NumBE(), 10)
+	for i := 0; i < fem.mesh.NumBE(); i++ {
+		msg.AddProgress()
+		x := fem.mesh.BeCoord(i)
+		for k := range fem.params.Params {
+			if fem.params.Params[k].Type != params.Convection || !fem.inSet(&fem.params.Params[k], mesh.SetBE, i) {
+				continue
+			}
+			isValidPredicate := true
+			for l := 0; l < fem.mesh.BeSize() && isValidPredicate; l++ {
+				ok, err := fem.params.Params[k].GetPredicate(x.RowView(l).(*mat.VecDense), &fem.params.Variables)
+				if err != nil {
+					return err
+				}
+				isValidPredicate = ok
+			}
+			if !isValidPredicate {
+				continue
+			}
+			// The ambient temperature follows the heat transfer coefficient (see AddConvection)
+			ambient := &fem.params.Params[k+1]
+			shape, w := fe.FaceQuadrature(x)
+			var point mat.Dense
+			point.Mul(shape, x)
+			for p := range w {
+				h, err := fem.params.Params[k].GetValue(point.RowView(p).(*mat.VecDense), &fem.params.Variables)
+				if err != nil {
+					return err
+				}
+				ta, err := ambient.GetValue(point.RowView(p).(*mat.VecDense), &fem.params.Variables)
+				if err != nil {
+					return err
+				}
+				for l := 0; l < fem.mesh.BeSize(); l++ {
+					for m := 0; m < fem.mesh.BeSize(); m++ {
+						fem.solver.AddMatrix(fem.mesh.BE[i][l], fem.mesh.BE[i][m], w[p]*h*shape.At(p, l)*shape.At(p, m))
+					}
+					fem.solver.AddVector(fem.mesh.BE[i][l], w[p]*h*ta*shape.At(p, l))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (fem *ThermalFEM) createThermalFE(index int) (fe.ThermalElement, error) {
+	cx := fem.mesh.FeCenter(index)
+	x := fem.mesh.FeCoord(index)
+	feParams := fe.ThermalParameters{}
+	conductivity, err := fem.paramValue(mesh.SetFE, index, cx, params.Conductivity)
+	if err != nil {
+		return nil, err
+	}
+	feParams.Conductivity = conductivity
+	if !fem.mesh.Is3D() {
+		thickness, err := fem.paramValue(mesh.SetFE, index, cx, params.Thickness)
+		if err != nil {
+			return nil, err
+		}
+		feParams.Thickness = thickness
+	}
+	switch fem.mesh.ElementType(index) {
+	case mesh.Fe2d3:
+		shape, err := fe.NewShape2d3(x)
+		if err != nil {
+			return nil, err
+		}
+		return fe.NewThermalElement2D(shape, feParams), nil
+	case mesh.Fe2d4:
+		shape, err := fe.NewShape2d4(x)
+		if err != nil {
+			return nil, err
+		}
+		return fe.NewThermalElement2D(shape, feParams), nil
+	case mesh.Fe3d4:
+		shape, err := fe.NewShape3d4(x)
+		if err != nil {
+			return nil, err
+		}
+		return fe.NewThermalElement3D(shape, feParams), nil
+	case mesh.Fe3d8:
+		shape, err := fe.NewShape3d8(x)
+		if err != nil {
+			return nil, err
+		}
+		return fe.NewThermalElement3D(shape, feParams), nil
+	case mesh.Fe2d6:
+		shape, err := fe.NewShape2d6(x)
+		if err != nil {
+			return nil, err
+		}
+		return fe.NewThermalElement2D(shape, feParams), nil
+	case mesh.Fe3d10:
+		shape, err := fe.NewShape3d10(x)
+		if err != nil {
+			return nil, err
+		}
+		return fe.NewThermalElement3D(shape, feParams), nil
+	case mesh.Fe3d6:
+		shape, err := fe.NewShape3d6(x)
+		if err != nil {
+			return nil, err
+		}
+		return fe.NewThermalElement3D(shape, feParams), nil
+	case mesh.Fe3d20:
+		shape, err := fe.NewShape3d20(x)
+		if err != nil {
+			return nil, err
+		}
+		return fe.NewThermalElement3D(shape, feParams), nil
+	case mesh.Fe3d27:
+		shape, err := fe.NewShape3d27(x)
+		if err != nil {
+			return nil, err
+		}
+		return fe.NewThermalElement3D(shape, feParams), nil
+	}
+	return nil, fmt.Errorf("bad finite element type")
+}
+
+// calcResult - the temperature and the heat flux averaged over the finite elements adjacent to the node
+func (fem *ThermalFEM) calcResult(t *mat.VecDense) error {
+	dim := fem.mesh.FeDim()
+	fem.res = mat.NewDense(dim+1, fem.mesh.NumVertex(), nil)
+	counter := make([]int, fem.mesh.NumVertex())
+	for i := 0; i < fem.mesh.NumVertex(); i++ {
+		fem.res.Set(0, i, t.AtVec(i))
+	}
+	msg := progress.NewProgress("Calculation of heat flux", 0, fem.mesh.NumFE(), 10)
+	for i := 0; i < fem.mesh.NumFE(); i++ {
+		msg.AddProgress()
+		elm, err := fem.createThermalFE(i)
+		if err != nil {
+			return err
+		}
+		feT := mat.NewVecDense(len(fem.mesh.FE[i]), nil)
+		for j := range fem.mesh.FE[i] {
+			feT.SetVec(j, t.AtVec(fem.mesh.FE[i][j]))
+		}
+		flux := elm.Calculate(feT)
+		for j := range fem.mesh.FE[i] {
+			for k := 0; k < dim; k++ {
+				fem.res.Set(k+1, fem.mesh.FE[i][j], fem.res.At(k+1, fem.mesh.FE[i][j])+flux.At(k, j))
+			}
+			counter[fem.mesh.FE[i][j]]++
+		}
+	}
+	for i := 1; i <= dim; i++ {
+		for j := 0; j < fem.mesh.NumVertex(); j++ {
+			fem.res.Set(i, j, fem.res.At(i, j)/float64(counter[j]))
+			if math.Abs(fem.res.At(i, j)) < fem.params.Eps {
+				fem.res.Set(i, j, 0)
+			}
+		}
+	}
+	return nil
+}
+
+func (fem *ThermalFEM) printSummary() {
+	fmt.Println("----------------------------------------------")
+	fmt.Println("Fun:\tmin\t\tmax")
+	for i, name := range *fem.ResultNames() {
+		fmt.Printf("%s\t%+e\t%+e\n", name, mat.Min(fem.res.RowView(i)), mat.Max(fem.res.RowView(i)))
+	}
+}
+
+// ResultNames - the temperature and the heat flux components
+func (fem *ThermalFEM) ResultNames() *[]string {
+	res := []string{"T", "Qx", "Qy", "Qz"}[:fem.mesh.FeDim()+1]
+	return &res
+}
+
+func (fem *ThermalFEM) SaveResult(name string) error {
+	return fem.saveResult(name, *fem.ResultNames(), nil)
+}
@@ -0,0 +1,110 @@
+package fem
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSlab - MESH-file of the slab [0, length] x [0, 1] (x [0, 1]) divided into nx x ny (x nz) quadrangles
+// (hexahedra), the ends x = 0 and x = length are the boundary elements
+func writeSlab(t *testing.T, length float64, nx, ny, nz int) string {
+	var b strings.Builder
+	node := func(i, j, k int) int {
+		return (k*(ny+1)+j)*(nx+1) + i
+	}
+	if nz == 0 {
+		fmt.Fprintf(&b, "fe2d4\n%d\n", (nx+1)*(ny+1))
+	} else {
+		fmt.Fprintf(&b, "fe3d8\n%d\n", (nx+1)*(ny+1)*(nz+1))
+	}
+	for k := 0; k <= nz; k++ {
+		for j := 0; j <= ny; j++ {
+			for i := 0; i <= nx; i++ {
+				x, y := length*float64(i)/float64(nx), float64(j)/float64(ny)
+				if nz == 0 {
+					fmt.Fprintf(&b, "%g %g\n", x, y)
+				} else {
+					fmt.Fprintf(&b, "%g %g %g\n", x, y, float64(k)/float64(nz))
+				}
+			}
+		}
+	}
+	if nz == 0 {
+		fmt.Fprintf(&b, "%d\n", nx*ny)
+		for j := 0; j < ny; j++ {
+			for i := 0; i < nx; i++ {
+				fmt.Fprintf(&b, "%d %d %d %d\n", node(i, j, 0), node(i+1, j, 0), node(i+1, j+1, 0), node(i, j+1, 0))
+			}
+		}
+		fmt.Fprintf(&b, "%d\n", 2*ny)
+		for _, i := range []int{0, nx} {
+			for j := 0; j < ny; j++ {
+				fmt.Fprintf(&b, "%d %d\n", node(i, j, 0), node(i, j+1, 0))
+			}
+		}
+	} else {
+		fmt.Fprintf(&b, "%d\n", nx*ny*nz)
+		for k := 0; k < nz; k++ {
+			for j := 0; j < ny; j++ {
+				for i := 0; i < nx; i++ {
+					fmt.Fprintf(&b, "%d %d %d %d %d %d %d %d\n", node(i, j, k), node(i+1, j, k), node(i+1, j+1, k),
+						node(i, j+1, k), node(i, j, k+1), node(i+1, j, k+1), node(i+1, j+1, k+1), node(i, j+1, k+1))
+				}
+			}
+		}
+		fmt.Fprintf(&b, "%d\n", 2*ny*nz)
+		for _, i := range []int{0, nx} {
+			for k := 0; k < nz; k++ {
+				for j := 0; j < ny; j++ {
+					fmt.Fprintf(&b, "%d %d %d %d\n", node(i, j, k), node(i, j+1, k), node(i, j+1, k+1), node(i, j, k+1))
+				}
+			}
+		}
+	}
+	name := filepath.Join(t.TempDir(), "slab.mesh")
+	if err := os.WriteFile(name, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+// The slab with the fixed temperature T0 at x = 0, the heat source Q and the convection to the ambient
+// temperature Ta at x = L: T = T0 + C x - Q x^2 / (2 k), C = (Q L - h (T0 - Ta - Q L^2 / (2 k))) / (k + h L)
+func TestThermalSlab(t *testing.T) {
+	const length, k, q, h, t0, ta = 2.0, 5.0, 3.0, 10.0, 100.0, 20.0
+	c := (q*length - h*(t0-ta-q*length*length/(2.0*k))) / (k + h*length)
+	meshes := []struct {
+		name       string
+		nx, ny, nz int
+	}{
+		{"quadrangles", 8, 2, 0},
+		{"hexahedra", 8, 2, 2},
+	}
+	for _, m := range meshes {
+		t.Run(m.name, func(t *testing.T) {
+			f := NewThermalFEM()
+			if err := f.SetMesh(writeSlab(t, length, m.nx, m.ny, m.nz)); err != nil {
+				t.Fatal(err)
+			}
+			f.AddThickness("1", "")
+			f.AddConductivity(fmt.Sprint(k), "")
+			f.AddHeatSource(fmt.Sprint(q), "")
+			f.AddFixedTemperature(fmt.Sprint(t0), "x == 0")
+			f.AddConvection(fmt.Sprint(h), fmt.Sprint(ta), fmt.Sprintf("x == %g", length))
+			if err := f.Calculate(); err != nil {
+				t.Fatal(err)
+			}
+			res := f.GetResult()
+			for i, x := range f.GetMesh().X {
+				want := t0 + c*x[0] - q*x[0]*x[0]/(2.0*k)
+				if got := res.At(0, i); math.Abs(got-want) > 1.0e-8*t0 {
+					t.Fatalf("temperature at x = %g is %g, want %g", x[0], got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package fem
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+	"wfem/cmd/fem/fe"
+	"wfem/cmd/fem/params"
+	"wfem/cmd/fem/progress"
+	"wfem/cmd/fem/solver"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// TransientFEM - time history analysis by the Newmark method (HHT-alpha for alpha < 0) with Rayleigh damping
+// C = a M + b K. Loads and prescribed displacements can depend on the time variable t. The matrices are factorized by
+// the sparse solver (the other solvers are not supported)
+type TransientFEM struct {
+	StaticFEM
+	timeStep         float64
+	endTime          float64
+	saveStep         int
+	alpha            float64
+	beta             float64
+	gamma            float64
+	massDamping      float64
+	stiffnessDamping float64
+	isLumped         bool
+	times            []float64
+}
+
+// NewTransientFEM - the average acceleration method (beta = 1/4, gamma = 1/2) without damping is used by default
+func NewTransientFEM() TransientFEM {
+	return TransientFEM{StaticFEM: NewStaticFEM(), saveStep: 1, beta: 0.25, gamma: 0.5}
+}
+
+// SetTime - time step, end time and the number of steps between the saved results
+func (fem *TransientFEM) SetTime(step, end float64, saveStep int) {
+	fem.timeStep = step
+	fem.endTime = end
+	fem.saveStep = saveStep
+}
+
+// SetNewmark - parameters of the Newmark method, the HHT dissipation is switched off
+func (fem *TransientFEM) SetNewmark(beta, gamma float64) {
+	fem.alpha = 0
+	fem.beta = beta
+	fem.gamma = gamma
+}
+
+// SetHHT - HHT-alpha method, alpha in [-1/3; 0], the Newmark parameters are chosen for unconditional stability
+func (fem *TransientFEM) SetHHT(alpha float64) error {
+	if alpha < -1.0/3.0 || alpha > 0 {
+		return fmt.Errorf("the HHT parameter must be in [-1/3; 0]")
+	}
+	fem.alpha = alpha
+	fem.beta = 0.25 * (1.0 - alpha) * (1.0 - alpha)
+	fem.gamma = 0.5 - alpha
+	return nil
+}
+
+// SetRayleighDamping - coefficients of the damping matrix C = a M + b K
+func (fem *TransientFEM) SetRayleighDamping(a, b float64) {
+	fem.massDamping = a
+	fem.stiffnessDamping = b
+}
+
+// SetLumpedMass - using the diagonal (lumped) mass matrices instead of the consistent ones
+func (fem *TransientFEM) SetLumpedMass(isLumped bool) {
+	fem.isLumped = isLumped
+}
+
+func (fem *TransientFEM) AddInitialDisplacement(value, predicate string, direct int) {
+	fem.params.AddInitialDisplacement(value, predicate, direct)
+}
+
+func (fem *TransientFEM) AddInitialVelocity(value, predicate string, direct int) {
+	fem.params.AddInitialVelocity(value, predicate, direct)
+}
+
+// GetTimes - time of the saved results
+func (fem *TransientFEM) GetTimes() []float64 {
+	return fem.times
+}
+
+func (fem *TransientFEM) Calculate() error {
+	var err error
+	fmt.Printf("Using threads: %d\n", fem.params.NumThread)
+	start := time.Now()
+	if fem.timeStep <= 0 || fem.endTime < fem.timeStep || fem.saveStep < 1 {
+		return fmt.Errorf("wrong time integration parameters")
+	}
+	if !fem.params.FindParameter(params.Density) {
+		return fmt.Errorf("density is not defined")
+	}
+	if err = fem.checkSparseSolver("transient"); err != nil {
+		return err
+	}
+	// The time is added to a copy of the variables, the map filled by AddVariable is left unchanged
+	variables := fem.params.Variables
+	fem.params.Variables = make(map[string]float64, len(variables)+1)
+	for name, value := range variables {
+		fem.params.Variables[name] = value
+	}
+	defer func() {
+		fem.params.Variables = variables
+	}()
+	if err = fem.checkSets(); err != nil {
+		return err
+	}
+	if err = fem.setDiscrete(); err != nil {
+		return err
+	}
+	fem.mesh.Renumber()
+	fem.res = nil
+	defer func() {
+		if fem.res != nil {
+			fem.mesh.RestoreNumbering(fem.res)
+		} else {
+			fem.mesh.RestoreNumbering()
+		}
+	}()
+	size := fem.mesh.NumVertex() * fem.mesh.Freedom()
+	stiffness := solver.NewSparseSolver(&fem.mesh, fem.mesh.Freedom())
+	mass := solver.NewSparseSolver(&fem.mesh, fem.mesh.Freedom())
+	fem.solver = stiffness
+	if err = fem.calcGlobalMatrix(); err != nil {
+		return err
+	}
+	if err = fem.assemble(mass, fem.mesh.Freedom(), "Building a global mass matrix", fem.feMatrix(func(_ int, elm fe.FiniteElement) *mat.Dense {
+		return elm.Mass(fem.isLumped)
+	})); err != nil {
+		return err
+	}
+	fem.addPointMasses(mass)
+	// The effective stiffness matrix, the prescribed displacements are applied by the penalty method,
+	// so that their values can be changed at every step
+	s, err := solver.New("sparse", &fem.mesh, solver.WithBoundaryMethod(solver.Penalty))
+	if err != nil {
+		return err
+	}
+	effective := s.(*solver.SparseSolver)
+	// Initial conditions
+	fem.params.Variables["t"] = 0
+	u := make([]float64, size)
+	v := make([]float64, size)
+	if err = fem.addNodeParameter(params.InitialDisplacement, "Using of initial displacements", fem.mesh.Freedom(), func(i int, value float64) {
+		u[i] = value
+	}); err != nil {
+		return err
+	}
+	if err = fem.addNodeParameter(params.InitialVelocity, "Using of initial velocities", fem.mesh.Freedom(), func(i int, value float64) {
+		v[i] = value
+	}); err != nil {
+		return err
+	}
+	load, err := fem.timeLoad(effective)
+	if err != nil {
+		return err
+	}
+	// Initial acceleration: M a = F - C v - K u, a = 0 for the constrained unknowns
+	ku := make([]float64, size)
+	kv := make([]float64, size)
+	mv := make([]float64, size)
+	initial := solver.NewSparseSolver(&fem.mesh, fem.mesh.Freedom())
+	initial.AddScaled(1.0, mass)
+	stiffness.MulVec(u, ku)
+	stiffness.MulVec(v, kv)
+	mass.MulVec(v, mv)
+	for i := 0; i < size; i++ {
+		initial.SetVector(i, load[i]-fem.massDamping*mv[i]-fem.stiffnessDamping*kv[i]-ku[i])
+	}
+	if err = fem.addBoundaryCondition(func(i int, _ float64) {
+		initial.SetBoundaryCondition(i, 0)
+	}); err != nil {
+		return err
+	}
+	acceleration, err := initial.Solve()
+	if err != nil {
+		return err
+	}
+	a := acceleration.RawVector().Data
+	// K* = M / (beta dt^2) + (1 + alpha) (gamma / (beta dt) C + K)
+	dt := fem.timeStep
+	c0 := 1.0 / (fem.beta * dt * dt)
+	c1 := fem.gamma / (fem.beta * dt)
+	effective.AddScaled(c0+(1.0+fem.alpha)*c1*fem.massDamping, mass)
+	effective.AddScaled((1.0+fem.alpha)*(1.0+c1*fem.stiffnessDamping), stiffness)
+	penalty := make(map[int]float64)
+	prescribed := make(map[int]float64)
+	if err = fem.addBoundaryCondition(func(i int, value float64) {
+		if _, ok := penalty[i]; !ok {
+			effective.SetBoundaryCondition(i, value)
+			penalty[i] = effective.GetMatrix(i, i)
+		}
+		prescribed[i] = value
+	}); err != nil {
+		return err
+	}
+	if err = effective.Factorize(); err != nil {
+		return err
+	}
+	var frames []*mat.Dense
+	fem.times = nil
+	if err = fem.saveFrame(&frames, 0, u); err != nil {
+		return err
+	}
+	isTimeLoad := fem.isTimeDependent(params.PointLoad, params.VolumeLoad, params.SurfaceLoad, params.PressureLoad)
+	isTimeCondition := fem.isTimeDependent(params.BoundaryCondition)
+	numSteps := int(math.Round(fem.endTime / dt))
+	uPredict := make([]float64, size)
+	vPredict := make([]float64, size)
+	p := make([]float64, size)
+	mx := make([]float64, size)
+	kx := make([]float64, size)
+	rhs := make([]float64, size)
+	msg := progress.NewProgress("Time integration", 0, numSteps, 10)
+	for step := 1; step <= numSteps; step++ {
+		msg.AddProgress()
+		t := float64(step) * dt
+		fem.params.Variables["t"] = t
+		// Predictors: u~ = u + dt v + dt^2 (1/2 - beta) a, v~ = v + dt (1 - gamma) a
+		for i := 0; i < size; i++ {
+			uPredict[i] = u[i] + dt*v[i] + dt*dt*(0.5-fem.beta)*a[i]
+			vPredict[i] = v[i] + dt*(1.0-fem.gamma)*a[i]
+			p[i] = c1*uPredict[i] - vPredict[i]
+		}
+		newLoad := load
+		if isTimeLoad {
+			if newLoad, err = fem.timeLoad(effective); err != nil {
+				return err
+			}
+		}
+		if isTimeCondition {
+			if err = fem.addBoundaryCondition(func(i int, value float64) {
+				prescribed[i] = value
+			}); err != nil {
+				return err
+			}
+		}
+		// F* = (1 + alpha) F(t + dt) - alpha F(t) + M (c0 u~ + (1 + alpha) a p + alpha a v) +
+		//      K ((1 + alpha) b p + alpha b v + alpha u)
+		for i := 0; i < size; i++ {
+			rhs[i] = c0*uPredict[i] + (1.0+fem.alpha)*fem.massDamping*p[i] + fem.alpha*fem.massDamping*v[i]
+			kx[i] = (1.0+fem.alpha)*fem.stiffnessDamping*p[i] + fem.alpha*fem.stiffnessDamping*v[i] + fem.alpha*u[i]
+		}
+		mass.MulVec(rhs, mx)
+		stiffness.MulVec(kx, rhs)
+		for i := 0; i < size; i++ {
+			rhs[i] += mx[i] + (1.0+fem.alpha)*newLoad[i] - fem.alpha*load[i]
+		}
+		for i, value := range prescribed {
+			rhs[i] = penalty[i] * value
+		}
+		u = effective.SolveVec(rhs)
+		for i := 0; i < size; i++ {
+			a[i] = c0 * (u[i] - uPredict[i])
+			v[i] = vPredict[i] + fem.gamma*dt*a[i]
+		}
+		load = newLoad
+		if step%fem.saveStep == 0 || step == numSteps {
+			if err = fem.saveFrame(&frames, t, u); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range u {
+		if math.IsNaN(u[i]) || math.IsInf(u[i], 0) {
+			return fmt.Errorf("the time integration is unstable")
+		}
+	}
+	// All frames in one matrix
+	rows, cols := frames[0].Dims()
+	fem.res = mat.NewDense(rows*len(frames), cols, nil)
+	for i := range frames {
+		fem.res.Slice(i*rows, (i+1)*rows, 0, cols).(*mat.Dense).Copy(frames[i])
+	}
+	fem.printSummary()
+	duration := time.Since(start)
+	fmt.Printf("Lead time: %0.2f sec\n\n", duration.Seconds())
+	return nil
+}
+
+// timeLoad - the load vector at the current time, the right-hand side of the solver is used for its assembling
+func (fem *TransientFEM) timeLoad(target solver.Solver) ([]float64, error) {
+	size := fem.mesh.NumVertex() * fem.mesh.Freedom()
+	for i := 0; i < size; i++ {
+		target.SetVector(i, 0)
+	}
+	fem.solver = target
+	if err := fem.addPointLoad(); err != nil {
+		return nil, err
+	}
+	if err := fem.addVolumeLoad(); err != nil {
+		return nil, err
+	}
+	if err := fem.addSurfaceLoad(); err != nil {
+		return nil, err
+	}
+	res := make([]float64, size)
+	for i := range res {
+		res[i] = target.GetVector(i)
+	}
+	return res, nil
+}
+
+var timeVariable = regexp.MustCompile(`\bt\b`)
+
+// isTimeDependent - checking if the value or the predicate of any parameter of the given types contains t
+func (fem *TransientFEM) isTimeDependent(types ...int) bool {
+	for _, param := range fem.params.Params {
+		for _, pType := range types {
+			if param.Type == pType && (timeVariable.MatchString(param.Value) || timeVariable.MatchString(param.Predicate)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// saveFrame - calculation of the results for the displacements u at the time t
+func (fem *TransientFEM) saveFrame(frames *[]*mat.Dense, t float64, u []float64) error {
+	if err := fem.calcResult(mat.NewVecDense(len(u), u)); err != nil {
+		return err
+	}
+	*frames = append(*frames, fem.res)
+	fem.times = append(fem.times, t)
+	return nil
+}
+
+func (fem *TransientFEM) printSummary() {
+	fmt.Println("----------------------------------------------")
+	fmt.Println("Fun:\tmin\t\tmax")
+	names := *fem.StaticFEM.ResultNames()
+	rows, _ := fem.res.Dims()
+	for k, name := range names {
+		values := make([]float64, 0, rows/len(names))
+		for i := k; i < rows; i += len(names) {
+			values = append(values, mat.Min(fem.res.RowView(i)), mat.Max(fem.res.RowView(i)))
+		}
+		fmt.Printf("%s\t%+e\t%+e\n", name, floats.Min(values), floats.Max(values))
+	}
+}
+
+// ResultNames - names of the results repeated for every saved time
+func (fem *TransientFEM) ResultNames() *[]string {
+	names := *fem.StaticFEM.ResultNames()
+	res := make([]string, 0, len(fem.times)*len(names))
+	for range fem.times {
+		res = append(res, names...)
+	}
+	return &res
+}
+
+// SaveResult - the results are saved as a time series, every frame with its time
+func (fem *TransientFEM) SaveResult(name string) error {
+	times := make([]float64, 0, len(fem.times)*fem.numResult())
+	for _, t := range fem.times {
+		for i := 0; i < fem.numResult(); i++ {
+			times = append(times, t)
+		}
+	}
+	return fem.saveResult(name, *fem.ResultNames(), times)
+}
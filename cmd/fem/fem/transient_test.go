@@ -0,0 +1,64 @@
+package fem
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"wfem/cmd/fem/params"
+)
+
+// The rod fixed at one end with the lumped mass is the oscillator with omega = sqrt(2 E / (rho L^2)), its free
+// vibrations from the initial displacement u0 are u0 cos(omega t)
+func TestTransientOscillator(t *testing.T) {
+	const e, rho, length, u0 = 2.0e+11, 7800.0, 2.0, 1.0e-3
+	omega := math.Sqrt(2.0*e/rho) / length
+	period := 2.0 * math.Pi / omega
+	f := NewTransientFEM()
+	if err := f.SetMesh(writeBar(t, 1, length)); err != nil {
+		t.Fatal(err)
+	}
+	f.SetTime(period/200.0, 2.0*period, 10)
+	f.SetLumpedMass(true)
+	f.AddYoungModulus(fmt.Sprint(e), "")
+	f.AddPoissonRatio("0", "")
+	f.AddDensity(fmt.Sprint(rho), "")
+	f.AddThickness("1.0e-4", "")
+	f.AddBoundaryCondition("0", "x == 0", params.X)
+	f.AddInitialDisplacement(fmt.Sprint(u0), "x == 2", params.X)
+	f.AddVariable("k", 1)
+	if err := f.Calculate(); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.params.Variables) != 1 {
+		t.Fatalf("variables are %v after the analysis", f.params.Variables)
+	}
+	times := f.GetTimes()
+	if len(times) != 41 {
+		t.Fatalf("%d frames are saved", len(times))
+	}
+	res := f.GetResult()
+	rows, _ := res.Dims()
+	numResult := rows / len(times)
+	for i, time := range times {
+		want := u0 * math.Cos(omega*time)
+		if got := res.At(i*numResult, 1); math.Abs(got-want) > 1.0e-3*u0 {
+			t.Fatalf("displacement at t = %g is %g, want %g", time, got, want)
+		}
+	}
+}
+
+func TestTransientRejectsOtherSolvers(t *testing.T) {
+	f := NewTransientFEM()
+	if err := f.SetMesh(writeBar(t, 4, 1.0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetSolver("dense"); err != nil {
+		t.Fatal(err)
+	}
+	f.SetTime(0.1, 1.0, 1)
+	f.AddYoungModulus("1", "")
+	f.AddDensity("1", "")
+	if err := f.Calculate(); err == nil {
+		t.Fatal("no error for the dense solver")
+	}
+}
@@ -0,0 +1,269 @@
+package mesh
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Positions of the nodes of the Nastran hexahedra in the Gmsh order: the mid-side nodes are listed by the edges of the
+// bottom face, the vertical edges and the edges of the top face (the tetrahedra are the same as the Abaqus ones)
+var bdfHexOrder = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 11, 12, 9, 13, 10, 14, 15, 16, 19, 17, 18}
+
+// Gmsh types of the linear Nastran elements and the greatest numbers of their nodes
+var bdfElements = map[string][2]int{"CTETRA": {4, 10}, "CHEXA": {5, 20}, "CPENTA": {6, 15}, "CTRIA3": {2, 3},
+	"CQUAD4": {3, 4}}
+
+// bdfCard - the name and the fields of the card of the bulk data, the continuations are appended to the fields
+type bdfCard struct {
+	name   string
+	fields []string
+}
+
+// bdfLine - the first field and the data fields of the line in the free (comma separated), small (8 characters) or
+// large (16 characters, the first field is marked by '*') format
+func bdfLine(s string) (string, []string) {
+	var fields []string
+	field := func(start, end int) string {
+		if end > len(s) {
+			end = len(s)
+		}
+		if start >= end {
+			return ""
+		}
+		return s[start:end]
+	}
+	width, num := 8, 8
+	if strings.Contains(strings.Split(field(0, 8), ",")[0], "*") {
+		width, num = 16, 4
+	}
+	if strings.Contains(s, ",") {
+		fields = strings.Split(s, ",")
+		if len(fields) > num+1 {
+			// The continuation mark
+			fields = fields[:num+1]
+		}
+	} else {
+		for i := 0; i <= num; i++ {
+			if i == 0 {
+				fields = append(fields, field(0, 8))
+			} else {
+				fields = append(fields, field(8+(i-1)*width, 8+i*width))
+			}
+		}
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields[0], fields[1:]
+}
+
+// bdfFloat - the real number of the field, the exponent may go without "E" ("1.-3") and the blank field is zero
+func bdfFloat(s string) (float64, error) {
+	s = strings.ToUpper(strings.ReplaceAll(s, "D", "E"))
+	if len(s) == 0 {
+		return 0.0, nil
+	}
+	if !strings.Contains(s, "E") {
+		if i := strings.LastIndexAny(s, "+-"); i > 0 {
+			s = s[:i] + "E" + s[i:]
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0.0, fmt.Errorf("wrong BDF-file format")
+	}
+	return v, nil
+}
+
+// bdfInt - the integer of the field, the blank field is zero
+func bdfInt(s string) (int, error) {
+	if len(s) == 0 {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("wrong BDF-file format")
+	}
+	return v, nil
+}
+
+// bdfCards - the cards of the bulk data section (or of the whole file if it has no "BEGIN BULK") up to ENDDATA
+func bdfCards(name string) ([]bdfCard, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file")
+	}
+	defer func() {
+		err = file.Close()
+	}()
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		s := scanner.Text()
+		if i := strings.Index(s, "$"); i >= 0 {
+			s = s[:i]
+		}
+		s = strings.TrimRight(s, " \t\r")
+		upper := strings.ToUpper(strings.TrimSpace(s))
+		if strings.HasPrefix(upper, "ENDDATA") {
+			break
+		}
+		if strings.HasPrefix(upper, "BEGIN BULK") {
+			lines = nil
+			continue
+		}
+		if strings.HasPrefix(upper, "INCLUDE") {
+			return nil, fmt.Errorf("INCLUDE of the BDF-file is not supported")
+		}
+		if len(upper) > 0 {
+			lines = append(lines, s)
+		}
+	}
+	var res []bdfCard
+	for _, s := range lines {
+		first, fields := bdfLine(s)
+		if s[0] == '+' || s[0] == '*' || s[0] == ' ' || s[0] == ',' {
+			// Continuation of the card
+			if len(res) == 0 {
+				return nil, fmt.Errorf("wrong BDF-file format")
+			}
+			res[len(res)-1].fields = append(res[len(res)-1].fields, fields...)
+			continue
+		}
+		res = append(res, bdfCard{name: strings.ToUpper(strings.TrimSuffix(first, "*")), fields: fields})
+	}
+	return res, nil
+}
+
+// bdfIds - the numbers of the list of SET1 or SET3, "THRU" makes the range of the numbers
+func bdfIds(fields []string) ([]int, error) {
+	var res []int
+	for i := 0; i < len(fields); i++ {
+		if len(fields[i]) == 0 {
+			continue
+		}
+		if strings.ToUpper(fields[i]) == "THRU" && len(res) > 0 && i+1 < len(fields) {
+			last, err := bdfInt(fields[i+1])
+			if err != nil {
+				return nil, err
+			}
+			ids, err := idRange(res[len(res)-1], last, 1)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, ids[1:]...)
+			i++
+			continue
+		}
+		v, err := bdfInt(fields[i])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+// loadBdf - Nastran bulk data file: the nodes (GRID in the basic coordinate system), the elements CTETRA (4 or 10
+// nodes), CHEXA (8 or 20 nodes), CPENTA (6 nodes) and the shells CTRIA3 and CQUAD4. The elements of each property are
+// the set "PID<n>", SET1 is the set of the nodes "SET<n>" and SET3 is the set of the nodes (GRID or POINT) or of the
+// elements (ELEM)
+func (m *Mesh) loadBdf(name string) error {
+	cards, err := bdfCards(name)
+	if err != nil {
+		return err
+	}
+	d := newDeck()
+	// The elements follow the nodes
+	for _, c := range cards {
+		if c.name != "GRID" {
+			continue
+		}
+		f := append(c.fields, make([]string, 5)...)
+		id, err := bdfInt(f[0])
+		if err != nil {
+			return err
+		}
+		cp, err := bdfInt(f[1])
+		if err != nil {
+			return err
+		}
+		if cp != 0 {
+			return fmt.Errorf("the coordinate system of the node %d is not supported", id)
+		}
+		var x [3]float64
+		for i := range x {
+			if x[i], err = bdfFloat(f[2+i]); err != nil {
+				return err
+			}
+		}
+		if err = d.addNode(id, x); err != nil {
+			return err
+		}
+	}
+	for _, c := range cards {
+		if c.name == "SET1" || c.name == "SET3" {
+			if len(c.fields) < 2 {
+				return fmt.Errorf("wrong BDF-file format")
+			}
+			sid, err := bdfInt(c.fields[0])
+			if err != nil {
+				return err
+			}
+			fields, sets := c.fields[1:], d.nodeSets
+			if c.name == "SET3" {
+				switch strings.ToUpper(fields[0]) {
+				case "GRID", "POINT":
+				case "ELEM":
+					sets = d.elementSets
+				default:
+					// The sets of the properties
+					continue
+				}
+				fields = fields[1:]
+			}
+			ids, err := bdfIds(fields)
+			if err != nil {
+				return err
+			}
+			d.addToSet(sets, fmt.Sprintf("SET%d", sid), ids...)
+			continue
+		}
+		t, ok := bdfElements[c.name]
+		if !ok {
+			continue
+		}
+		// The element number, the property and the nodes
+		f := append(c.fields, make([]string, 2+t[1])...)
+		ids := make([]int, 2+t[1])
+		for i := range ids {
+			if ids[i], err = bdfInt(f[i]); err != nil {
+				return err
+			}
+		}
+		elmType, numNodes := t[0], mshElements[t[0]][1]
+		var order []int
+		if numNodes < t[1] && ids[2+numNodes] != 0 {
+			// The mid-side nodes of the quadratic elements
+			switch elmType {
+			case 4:
+				order = inpOrders[11]
+			case 5:
+				order = bdfHexOrder
+			default:
+				return fmt.Errorf("unsupported type of the BDF-file element: %s with %d nodes", c.name, t[1])
+			}
+			elmType, numNodes = map[int]int{4: 11, 5: 17}[elmType], t[1]
+		}
+		d.shell = d.shell || mshElements[elmType][0] == 2
+		if err = d.addElement(ids[0], elmType, ids[2:2+numNodes], order); err != nil {
+			return err
+		}
+		d.addToSet(d.elementSets, fmt.Sprintf("PID%d", ids[1]), ids[0])
+	}
+	return m.createDeck(d)
+}
@@ -0,0 +1,148 @@
+package mesh
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// deck - the nodes, the elements (as the Gmsh ones) and the sets of the Abaqus or Nastran input file, the sets
+// list the numbers of the nodes and of the elements in the file
+type deck struct {
+	x           [][]float64
+	index       map[int]int
+	elements    []mshElement
+	position    map[int]int
+	nodeSets    map[string][]int
+	elementSets map[string][]int
+	// The names of the sets are case-insensitive, the first spelling is kept
+	names map[string]string
+	// The surface elements are the shells
+	shell bool
+}
+
+func newDeck() *deck {
+	return &deck{index: make(map[int]int), position: make(map[int]int), nodeSets: make(map[string][]int),
+		elementSets: make(map[string][]int), names: make(map[string]string)}
+}
+
+// addNode - the node with the number id and the coordinates x
+func (d *deck) addNode(id int, x [3]float64) error {
+	if _, ok := d.index[id]; ok {
+		return fmt.Errorf("the node %d is defined twice", id)
+	}
+	d.index[id] = len(d.x)
+	d.x = append(d.x, []float64{x[0], x[1], x[2]})
+	return nil
+}
+
+// addElement - the element with the number id of the Gmsh type elmType, order[i] - the position in nodes of its
+// i-th node (nil - the same order)
+func (d *deck) addElement(id, elmType int, nodes []int, order []int) error {
+	if _, ok := d.position[id]; ok {
+		return fmt.Errorf("the element %d is defined twice", id)
+	}
+	elm := mshElement{dim: mshElements[elmType][0], elmType: elmType, nodes: make([]int, len(nodes))}
+	for i := range nodes {
+		k := i
+		if order != nil {
+			k = order[i]
+		}
+		elm.nodes[i] = nodes[k]
+	}
+	d.position[id] = len(d.elements)
+	d.elements = append(d.elements, elm)
+	return nil
+}
+
+// setName - the name of the set as it is spelled first
+func (d *deck) setName(name string) string {
+	key := strings.ToUpper(name)
+	if res, ok := d.names[key]; ok {
+		return res
+	}
+	d.names[key] = name
+	return name
+}
+
+// addToSet - adding the numbers of the nodes or of the elements to the set
+func (d *deck) addToSet(sets map[string][]int, name string, ids ...int) {
+	name = d.setName(name)
+	sets[name] = append(sets[name], ids...)
+}
+
+// createDeck - the mesh of the deck: the element sets are the groups of the elements, the node sets add their nodes
+// to the element sets with the same names or make the sets of the nodes and of the elements all nodes of which are
+// in them
+func (m *Mesh) createDeck(d *deck) error {
+	setNames := make([]string, 0, len(d.elementSets))
+	for name := range d.elementSets {
+		setNames = append(setNames, name)
+	}
+	sort.Strings(setNames)
+	names := make(map[[2]int]string)
+	for i, name := range setNames {
+		for dim := 0; dim <= 3; dim++ {
+			names[[2]int{dim, i + 1}] = name
+		}
+		for _, id := range unique(d.elementSets[name]) {
+			k, ok := d.position[id]
+			if !ok {
+				return fmt.Errorf("the element %d of the set %s is not found", id, name)
+			}
+			d.elements[k].physical = append(d.elements[k].physical, i+1)
+		}
+	}
+	if err := m.createMsh(d.x, d.index, d.elements, names, d.shell); err != nil {
+		return err
+	}
+	nodeNames := make([]string, 0, len(d.nodeSets))
+	for name := range d.nodeSets {
+		nodeNames = append(nodeNames, name)
+	}
+	sort.Strings(nodeNames)
+	for _, name := range nodeNames {
+		var nodes []int
+		for _, id := range unique(d.nodeSets[name]) {
+			k, ok := d.index[id]
+			if !ok {
+				return fmt.Errorf("the node %d of the set %s is not found", id, name)
+			}
+			nodes = append(nodes, k)
+		}
+		set, ok := m.Sets[name]
+		if !ok {
+			m.AddSet(name, nodes)
+			continue
+		}
+		set.Nodes = unique(append(set.Nodes, nodes...))
+		m.Sets[name] = set
+	}
+	return nil
+}
+
+// idRange - the numbers from first to last with the step (the generated and THRU lists of the sets)
+func idRange(first, last, step int) ([]int, error) {
+	if step <= 0 || last < first {
+		return nil, fmt.Errorf("wrong range of the numbers: %d - %d", first, last)
+	}
+	var res []int
+	for id := first; id <= last; id += step {
+		res = append(res, id)
+	}
+	return res, nil
+}
+
+// unique - sorted numbers without repetitions
+func unique(ids []int) []int {
+	res := append([]int(nil), ids...)
+	sort.Ints(res)
+	n := 0
+	for i := range res {
+		if i == 0 || res[i] != res[n-1] {
+			res[n] = res[i]
+			n++
+		}
+	}
+	return res[:n]
+}
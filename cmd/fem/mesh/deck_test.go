@@ -0,0 +1,207 @@
+package mesh
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Vertices of the distorted tetrahedron and hexahedron
+var (
+	deckTetrahedron = [][3]float64{{0, 0, 0}, {1.2, 0.1, 0}, {0.2, 1.1, 0.1}, {0.1, 0.2, 0.9}}
+	deckHexahedron  = [][3]float64{{0, 0, 0}, {1.1, 0, 0.1}, {1.2, 1.1, 0}, {0.1, 0.9, 0}, {0, 0.1, 1}, {1, 0, 1.1},
+		{1.1, 1.2, 1.2}, {0, 1, 0.9}}
+)
+
+// Edges of the mid-side nodes in the Gmsh order
+var (
+	gmshTetrahedronEdges = [][2]int{{0, 1}, {1, 2}, {2, 0}, {0, 3}, {2, 3}, {1, 3}}
+	gmshHexahedronEdges  = [][2]int{{0, 1}, {0, 3}, {0, 4}, {1, 2}, {1, 5}, {2, 3}, {2, 6}, {3, 7}, {4, 5}, {4, 7},
+		{5, 6}, {6, 7}}
+)
+
+// Edges of the mid-side nodes of the Abaqus and Nastran elements
+var (
+	abaqusTetrahedronEdges = [][2]int{{0, 1}, {1, 2}, {2, 0}, {0, 3}, {1, 3}, {2, 3}}
+	abaqusHexahedronEdges  = [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}, {4, 5}, {5, 6}, {6, 7}, {7, 4}, {0, 4}, {1, 5},
+		{2, 6}, {3, 7}}
+	nastranHexahedronEdges = [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}, {0, 4}, {1, 5}, {2, 6}, {3, 7}, {4, 5}, {5, 6},
+		{6, 7}, {7, 4}}
+)
+
+// quadraticNodes - the vertices followed by the middles of the edges
+func quadraticNodes(vertices [][3]float64, edges [][2]int) [][3]float64 {
+	res := append([][3]float64(nil), vertices...)
+	for _, e := range edges {
+		var mid [3]float64
+		for k := range mid {
+			mid[k] = 0.5 * (vertices[e[0]][k] + vertices[e[1]][k])
+		}
+		res = append(res, mid)
+	}
+	return res
+}
+
+// inpElement - INP-file of the element of the given type, the nodes are numbered from 101, the element number is 7
+func inpElement(elmType string, nodes [][3]float64) string {
+	var b strings.Builder
+	b.WriteString("*NODE\n")
+	for i, x := range nodes {
+		fmt.Fprintf(&b, "%d, %g, %g, %g\n", 101+i, x[0], x[1], x[2])
+	}
+	fmt.Fprintf(&b, "*ELEMENT, TYPE=%s, ELSET=BODY\n7", elmType)
+	for i := range nodes {
+		if i == 15 {
+			// The continuation line
+			b.WriteString(",\n")
+			fmt.Fprintf(&b, "%d", 101+i)
+			continue
+		}
+		fmt.Fprintf(&b, ", %d", 101+i)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// bdfElement - free field BDF-file of the element of the given type, the nodes are numbered from 101, the element
+// number is 7
+func bdfElement(elmType string, nodes [][3]float64) string {
+	var b strings.Builder
+	b.WriteString("BEGIN BULK\n")
+	for i, x := range nodes {
+		fmt.Fprintf(&b, "GRID,%d,,%g,%g,%g\n", 101+i, x[0], x[1], x[2])
+	}
+	fields := []string{"7", "1"}
+	for i := range nodes {
+		fields = append(fields, fmt.Sprint(101+i))
+	}
+	// 8 fields on the first line and on the continuation lines
+	fmt.Fprintf(&b, "%s,%s\n", elmType, strings.Join(fields[:8], ","))
+	for i := 8; i < len(fields); i += 8 {
+		end := i + 8
+		if end > len(fields) {
+			end = len(fields)
+		}
+		fmt.Fprintf(&b, "+,%s\n", strings.Join(fields[i:end], ","))
+	}
+	b.WriteString("ENDDATA\n")
+	return b.String()
+}
+
+func TestDeckQuadraticOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		ext      string
+		content  string
+		feType   int
+		vertices [][3]float64
+		edges    [][2]int
+	}{
+		{"C3D10", ".inp", inpElement("C3D10", quadraticNodes(deckTetrahedron, abaqusTetrahedronEdges)), Fe3d10,
+			deckTetrahedron, gmshTetrahedronEdges},
+		{"C3D20R", ".inp", inpElement("C3D20R", quadraticNodes(deckHexahedron, abaqusHexahedronEdges)), Fe3d20,
+			deckHexahedron, gmshHexahedronEdges},
+		{"CTETRA", ".bdf", bdfElement("CTETRA", quadraticNodes(deckTetrahedron, abaqusTetrahedronEdges)), Fe3d10,
+			deckTetrahedron, gmshTetrahedronEdges},
+		{"CHEXA", ".bdf", bdfElement("CHEXA", quadraticNodes(deckHexahedron, nastranHexahedronEdges)), Fe3d20,
+			deckHexahedron, gmshHexahedronEdges},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "deck"+test.ext)
+			if err := os.WriteFile(name, []byte(test.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			var m Mesh
+			if err := m.Load(name); err != nil {
+				t.Fatal(err)
+			}
+			if m.FeType != test.feType || m.NumFE() != 1 {
+				t.Fatalf("the mesh has %d elements of the type %s", m.NumFE(), m.FeName())
+			}
+			want := quadraticNodes(test.vertices, test.edges)
+			for i, node := range m.FE[0] {
+				for k := 0; k < 3; k++ {
+					if math.Abs(m.X[node][k]-want[i][k]) > 1.0e-12 {
+						t.Fatalf("node %d of the element is %v, want %v", i, m.X[node], want[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+// The plate of two quadrangles with the node tags 1 - 3 at y = 0 and 4 - 6 at y = 1: the element sets are the groups
+// of the elements with their nodes (the shells are also the boundary elements), the node set of the right side x = 2
+// has no elements
+func TestDeckSets(t *testing.T) {
+	const inp = `** The plane stress plate
+*NODE
+1, 0, 0
+2, 1, 0
+3, 2, 0
+4, 0, 1
+5, 1, 1
+6, 2, 1
+*ELEMENT, TYPE=CPS4R, ELSET=Plate
+10, 1, 2, 5, 4
+11, 2, 3, 6, 5
+*NSET, NSET=Right, GENERATE
+3, 6, 3
+*ELSET, ELSET=Left
+10
+*STEP
+*END STEP
+`
+	const bdf = `$ The shell plate
+BEGIN BULK
+GRID,1,,0.,0.,0.
+GRID,2,,1.,0.,0.
+GRID,3,,2.,0.,0.
+GRID,4,,0.,1.,0.
+GRID,5,,1.,1.,0.
+GRID,6,,2.,1.,0.
+CQUAD4,10,1,1,2,5,4
+CQUAD4,11,2,2,3,6,5
+SET1,3,3,6
+ENDDATA
+`
+	tests := []struct {
+		name, ext, content string
+		feType             int
+		sets               map[string]Set
+	}{
+		{"INP", ".inp", inp, Fe2d4, map[string]Set{
+			"Plate": {Nodes: []int{0, 1, 2, 3, 4, 5}, FE: []int{0, 1}},
+			"Left":  {Nodes: []int{0, 1, 3, 4}, FE: []int{0}},
+			"Right": {Nodes: []int{2, 5}},
+		}},
+		{"BDF", ".bdf", bdf, Fe3d4s, map[string]Set{
+			"PID1": {Nodes: []int{0, 1, 3, 4}, FE: []int{0}, BE: []int{0}},
+			"PID2": {Nodes: []int{1, 2, 4, 5}, FE: []int{1}, BE: []int{1}},
+			"SET3": {Nodes: []int{2, 5}},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "deck"+test.ext)
+			if err := os.WriteFile(name, []byte(test.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			var m Mesh
+			if err := m.Load(name); err != nil {
+				t.Fatal(err)
+			}
+			if m.FeType != test.feType || !reflect.DeepEqual(m.FE, [][]int{{0, 1, 4, 3}, {1, 2, 5, 4}}) {
+				t.Fatalf("the mesh of the type %s has the elements %v", m.FeName(), m.FE)
+			}
+			if !reflect.DeepEqual(m.Sets, test.sets) {
+				t.Fatalf("sets are %v, want %v", m.Sets, test.sets)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package mesh
+
+// Spring - grounded (Node2 < 0) or two-node spring, Stiffness - its translational (X, Y, Z) and rotational
+// (RX, RY, RZ) stiffness in the global axes, the components the nodes do not have are ignored
+type Spring struct {
+	Node1, Node2 int
+	Stiffness    [6]float64
+}
+
+// PointMass - lumped mass of the node: Mass - the masses along X, Y, Z and the rotary inertia about them
+type PointMass struct {
+	Node int
+	Mass [6]float64
+}
+
+// RigidLink - multi-point constraint moving the slave nodes together with the master node as a rigid body
+type RigidLink struct {
+	Master int
+	Slaves []int
+}
+
+// Nodes - the master node followed by the slave ones
+func (l *RigidLink) Nodes() []int {
+	return append([]int{l.Master}, l.Slaves...)
+}
+
+// discreteCouplings - groups of the nodes coupled by the two-node springs and the rigid links
+func (m *Mesh) discreteCouplings() [][]int {
+	var res [][]int
+	for _, s := range m.Springs {
+		if s.Node2 >= 0 {
+			res = append(res, []int{s.Node1, s.Node2})
+		}
+	}
+	for i := range m.RigidLinks {
+		res = append(res, m.RigidLinks[i].Nodes())
+	}
+	return res
+}
+
+// permuteDiscrete - moving the nodes of the discrete elements to the positions permutation[i]
+func (m *Mesh) permuteDiscrete(permutation []int) {
+	for i := range m.Springs {
+		m.Springs[i].Node1 = permutation[m.Springs[i].Node1]
+		if m.Springs[i].Node2 >= 0 {
+			m.Springs[i].Node2 = permutation[m.Springs[i].Node2]
+		}
+	}
+	for i := range m.Masses {
+		m.Masses[i].Node = permutation[m.Masses[i].Node]
+	}
+	for i := range m.RigidLinks {
+		m.RigidLinks[i].Master = permutation[m.RigidLinks[i].Master]
+		for j := range m.RigidLinks[i].Slaves {
+			m.RigidLinks[i].Slaves[j] = permutation[m.RigidLinks[i].Slaves[j]]
+		}
+	}
+}
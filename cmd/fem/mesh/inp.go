@@ -0,0 +1,190 @@
+package mesh
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Gmsh types of the Abaqus elements (the stress, plane stress and strain, axisymmetric, heat transfer and shell ones)
+var inpTypes = map[string]int{
+	"C3D4": 4, "C3D6": 6, "C3D8": 5, "C3D10": 11, "C3D20": 17,
+	"CPS3": 2, "CPS4": 3, "CPS6": 9, "CPE3": 2, "CPE4": 3, "CPE6": 9, "CAX3": 2, "CAX4": 3, "CAX6": 9,
+	"DC3D4": 4, "DC3D6": 6, "DC3D8": 5, "DC3D10": 11, "DC3D20": 17, "DC2D3": 2, "DC2D4": 3, "DC2D6": 9,
+	"S3": 2, "S4": 3,
+}
+
+// Positions of the nodes of the Abaqus elements in the Gmsh order: the mid-side nodes of the tetrahedra on the edges
+// 2-3 and 1-3 are swapped, the mid-side nodes of the hexahedra are listed by the edges of the bottom face, of the top
+// face and the vertical edges
+var inpOrders = map[int][]int{
+	11: {0, 1, 2, 3, 4, 5, 6, 7, 9, 8},
+	17: {0, 1, 2, 3, 4, 5, 6, 7, 8, 11, 16, 9, 17, 10, 18, 19, 12, 15, 13, 14},
+}
+
+// inpType - Gmsh type of the Abaqus element, the letters of the reduced integration (R), hybrid (H), incompatible
+// modes (I), modified (M) and coupled temperature (T) variants are dropped
+func inpType(name string) (int, bool, error) {
+	name = strings.ToUpper(name)
+	for len(name) > 0 && strings.ContainsRune("RHIMT", rune(name[len(name)-1])) {
+		name = name[:len(name)-1]
+	}
+	elmType, ok := inpTypes[name]
+	if !ok {
+		return 0, false, fmt.Errorf("unsupported type of the INP-file element: %s", name)
+	}
+	return elmType, name[0] == 'S', nil
+}
+
+// inpKeyword - the keyword of the line of the INP-file and its parameters (upper case names, values as they are)
+func inpKeyword(s string) (string, map[string]string) {
+	fields := strings.Split(s[1:], ",")
+	keyword := strings.ToUpper(strings.TrimSpace(fields[0]))
+	parameters := make(map[string]string)
+	for _, f := range fields[1:] {
+		key, value, _ := strings.Cut(f, "=")
+		parameters[strings.ToUpper(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), "\"")
+	}
+	return keyword, parameters
+}
+
+// inpFields - the comma separated fields of the data line of the INP-file
+func inpFields(s string) []string {
+	fields := strings.Split(strings.TrimSuffix(s, ","), ",")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
+
+// inpSet - the numbers of the data line of *NSET or *ELSET, either the numbers and the names of the previously
+// defined sets or, if generate is set, the first and the last numbers and the step
+func (d *deck) inpSet(sets map[string][]int, fields []string, generate bool) ([]int, error) {
+	if generate {
+		values := []int{0, 0, 1}
+		for i := 0; i < len(fields) && i < 3; i++ {
+			v, err := strconv.Atoi(fields[i])
+			if err != nil {
+				return nil, fmt.Errorf("wrong INP-file format")
+			}
+			values[i] = v
+		}
+		return idRange(values[0], values[1], values[2])
+	}
+	var res []int
+	for _, f := range fields {
+		if len(f) == 0 {
+			continue
+		}
+		if v, err := strconv.Atoi(f); err == nil {
+			res = append(res, v)
+			continue
+		}
+		ids, ok := sets[d.setName(f)]
+		if !ok {
+			return nil, fmt.Errorf("the set %s is not defined", f)
+		}
+		res = append(res, ids...)
+	}
+	return res, nil
+}
+
+// loadInp - Abaqus input file: the nodes (*NODE), the elements (*ELEMENT) of the supported types and the sets of the
+// nodes (*NSET and NSET of *NODE) and of the elements (*ELSET and ELSET of *ELEMENT). The element sets are the sets
+// of the mesh, a node set adds the nodes and the elements all nodes of which are in it. The other keywords are skipped
+func (m *Mesh) loadInp(name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("error opening file")
+	}
+	defer func() {
+		err = file.Close()
+	}()
+	d := newDeck()
+	var keyword string
+	var parameters map[string]string
+	var elmType int
+	var fields []string
+	scanner := bufio.NewScanner(file)
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		s := strings.TrimSpace(scanner.Text())
+		if len(s) == 0 || strings.HasPrefix(s, "**") {
+			continue
+		}
+		if s[0] == '*' {
+			if len(fields) > 0 {
+				return fmt.Errorf("wrong INP-file format")
+			}
+			keyword, parameters = inpKeyword(s)
+			if keyword == "ELEMENT" {
+				var shell bool
+				if elmType, shell, err = inpType(parameters["TYPE"]); err != nil {
+					return err
+				}
+				d.shell = d.shell || shell
+			}
+			continue
+		}
+		switch keyword {
+		case "NODE":
+			f := inpFields(s)
+			id, err := strconv.Atoi(f[0])
+			if err != nil || len(f) < 3 {
+				return fmt.Errorf("wrong INP-file format")
+			}
+			var x [3]float64
+			for i := 1; i < len(f) && i <= 3; i++ {
+				if x[i-1], err = strconv.ParseFloat(f[i], 64); err != nil {
+					return fmt.Errorf("wrong INP-file format")
+				}
+			}
+			if err = d.addNode(id, x); err != nil {
+				return err
+			}
+			if set, ok := parameters["NSET"]; ok {
+				d.addToSet(d.nodeSets, set, id)
+			}
+		case "ELEMENT":
+			// The nodes of the element are continued on the next lines
+			fields = append(fields, inpFields(s)...)
+			numNodes := mshElements[elmType][1]
+			if len(fields) < numNodes+1 {
+				continue
+			}
+			ids := make([]int, numNodes+1)
+			for i := range ids {
+				if ids[i], err = strconv.Atoi(fields[i]); err != nil {
+					return fmt.Errorf("wrong INP-file format")
+				}
+			}
+			fields = nil
+			if err = d.addElement(ids[0], elmType, ids[1:], inpOrders[elmType]); err != nil {
+				return err
+			}
+			if set, ok := parameters["ELSET"]; ok {
+				d.addToSet(d.elementSets, set, ids[0])
+			}
+		case "NSET", "ELSET":
+			sets, set := d.nodeSets, parameters["NSET"]
+			if keyword == "ELSET" {
+				sets, set = d.elementSets, parameters["ELSET"]
+			}
+			if len(set) == 0 {
+				return fmt.Errorf("the name of the set is empty")
+			}
+			_, generate := parameters["GENERATE"]
+			ids, err := d.inpSet(sets, inpFields(s), generate)
+			if err != nil {
+				return err
+			}
+			d.addToSet(sets, set, ids...)
+		}
+	}
+	if len(fields) > 0 {
+		return fmt.Errorf("wrong INP-file format")
+	}
+	return m.createDeck(d)
+}
@@ -23,15 +23,45 @@ const (
 	Fe3d8
 	Fe3d3s
 	Fe3d4s
+	// Bars of the plane and space trusses (axial force only) and frames (beams)
+	Fe2d2t
+	Fe3d2t
+	Fe2d2b
+	Fe3d2b
+	// Quadratic triangle and tetrahedron, the vertices are followed by the mid-side nodes
+	Fe2d6
+	Fe3d10
+	// Wedge, serendipity and Lagrange hexahedra
+	Fe3d6
+	Fe3d20
+	Fe3d27
 	// Is1D2(), ...
 )
 
 type Mesh struct {
-	FeType  int
+	FeType int
+	// Types of the finite elements of the mesh made of the elements of different types (nil - all elements are of
+	// FeType), FeType is then the type with the greatest number of the nodes
+	Types   []int
 	X       [][]float64
 	FE      [][]int
 	BE      [][]int
 	MeshMap [][]int
+	// Discrete elements assembled alongside the finite elements
+	Springs    []Spring
+	Masses     []PointMass
+	RigidLinks []RigidLink
+	// Named groups of the nodes and elements (the physical groups of Gmsh)
+	Sets        map[string]Set
+	permutation []int
+}
+
+// ElementType - type of the index-th finite element
+func (m *Mesh) ElementType(index int) int {
+	if m.Types != nil {
+		return m.Types[index]
+	}
+	return m.FeType
 }
 
 func (m *Mesh) NumVertex() int {
@@ -68,6 +98,10 @@ func (m *Mesh) Load(name string) error {
 		err = m.loadMsh(name)
 	case ".MESH":
 		err = m.loadMesh(name)
+	case ".INP": // Abaqus
+		err = m.loadInp(name)
+	case ".BDF", ".NAS": // Nastran
+		err = m.loadBdf(name)
 	default:
 		return fmt.Errorf("wrong mesh-file format")
 	}
@@ -76,6 +110,9 @@ func (m *Mesh) Load(name string) error {
 		fmt.Println("Finite element type:", m.FeName())
 		fmt.Println("Number of nodes:", m.NumVertex())
 		fmt.Println("Number of finite element:", len(m.FE))
+		if len(m.Sets) > 0 {
+			fmt.Println("Sets:", strings.Join(m.SetNames(), ", "))
+		}
 		m.CreateMeshMap()
 	}
 	return err
@@ -223,6 +260,51 @@ func feParam(feType int) (beSize, feSize, feDim, freedom int, err error) {
 		feSize = 4
 		feDim = 3
 		freedom = 6
+	case Fe2d2t:
+		beSize = 1
+		feSize = 2
+		feDim = 2
+		freedom = 2
+	case Fe3d2t:
+		beSize = 1
+		feSize = 2
+		feDim = 3
+		freedom = 3
+	case Fe2d2b:
+		beSize = 1
+		feSize = 2
+		feDim = 2
+		freedom = 3
+	case Fe3d2b:
+		beSize = 1
+		feSize = 2
+		feDim = 3
+		freedom = 6
+	case Fe2d6:
+		beSize = 3
+		feSize = 6
+		feDim = 2
+		freedom = 2
+	case Fe3d10:
+		beSize = 6
+		feSize = 10
+		feDim = 3
+		freedom = 3
+	case Fe3d6:
+		beSize = 4
+		feSize = 6
+		feDim = 3
+		freedom = 3
+	case Fe3d20:
+		beSize = 8
+		feSize = 20
+		feDim = 3
+		freedom = 3
+	case Fe3d27:
+		beSize = 9
+		feSize = 27
+		feDim = 3
+		freedom = 3
 	default:
 		err = fmt.Errorf("unknown FE type")
 	}
@@ -260,6 +342,24 @@ func (m *Mesh) loadMesh(name string) error {
 		m.FeType = Fe3d3s
 	case "fe3d4s":
 		m.FeType = Fe3d4s
+	case "fe2d2t":
+		m.FeType = Fe2d2t
+	case "fe3d2t":
+		m.FeType = Fe3d2t
+	case "fe2d2b":
+		m.FeType = Fe2d2b
+	case "fe3d2b":
+		m.FeType = Fe3d2b
+	case "fe2d6":
+		m.FeType = Fe2d6
+	case "fe3d10":
+		m.FeType = Fe3d10
+	case "fe3d6":
+		m.FeType = Fe3d6
+	case "fe3d20":
+		m.FeType = Fe3d20
+	case "fe3d27":
+		m.FeType = Fe3d27
 	default:
 		return fmt.Errorf("unknown FE type")
 	}
@@ -329,188 +429,25 @@ func (m *Mesh) loadMesh(name string) error {
 	return nil
 }
 
-func (m *Mesh) loadMsh(name string) error {
-	var data []string
-	var numEntities, num, dim, minTag, elmType int
-	is2d := true
-	eps := 1.0e-10
-
-	file, err := os.Open(name)
-	if err != nil {
-		return fmt.Errorf("error opening file")
-	}
-	defer func() {
-		err = file.Close()
-	}()
-	scanner := bufio.NewScanner(file)
-	scanner.Split(bufio.ScanLines)
-
-	scanner.Scan()
-	if scanner.Text() != "$MeshFormat" {
-		return fmt.Errorf("wrong MSH-file format")
-	}
-
-	for scanner.Scan() {
-		if scanner.Text() == "$Nodes" {
-			break
-		}
-	}
-	if scanner.Text() == "" {
-		return fmt.Errorf("wrong MSH-file format")
-	}
-
-	// Number of section
-	scanner.Scan()
-
-	data = strings.Split(scanner.Text(), " ")
-	if numEntities, err = strconv.Atoi(data[0]); err != nil {
-		return err
-	}
-	if num, err = strconv.Atoi(data[1]); err != nil {
-		return err
-	}
-	m.X = make([][]float64, 0, num)
-	for i := 0; i < numEntities; i++ {
-		scanner.Scan()
-		// data = strings.Split(scanner.Text(), " ")
-		data = strings.Fields(scanner.Text())
-		if num, err = strconv.Atoi(data[3]); err != nil {
-			return err
-		}
-		// Ignoring tags
-		for j := 0; j < num; j++ {
-			scanner.Scan()
-		}
-		for j := 0; j < num; j++ {
-			x := make([]float64, 3)
-			scanner.Scan()
-			// data = strings.Split(scanner.Text(), " ")
-			data = strings.Fields(scanner.Text())
-			for k := 0; k < 3; k++ {
-				x[k], err = strconv.ParseFloat(data[k], 16)
-				if err != nil {
-					return err
-				}
-			}
-			if math.Abs(x[2]) > eps {
-				is2d = false
-			}
-			m.X = append(m.X, x)
-		}
-	}
-	scanner.Scan()
-	if scanner.Text() != "$EndNodes" {
-		return fmt.Errorf("wrong MSH-file format")
-	}
-
-	scanner.Scan()
-	if scanner.Text() != "$Elements" {
-		return fmt.Errorf("wrong MSH-file format")
-	}
-
-	// Number of section
-	scanner.Scan()
-	// data = strings.Split(scanner.Text(), " ")
-	data = strings.Fields(scanner.Text())
-	if numEntities, err = strconv.Atoi(data[0]); err != nil {
-		return err
-	}
-	if num, err = strconv.Atoi(data[1]); err != nil {
-		return err
-	}
-	if minTag, err = strconv.Atoi(data[2]); err != nil {
-		return err
-	}
-
-	m.BE = make([][]int, 0, num)
-	m.FE = make([][]int, 0, num)
-
-	for i := 0; i < numEntities; i++ {
-		scanner.Scan()
-		// data = strings.Split(scanner.Text(), " ")
-		data = strings.Fields(scanner.Text())
-		if dim, err = strconv.Atoi(data[0]); err != nil {
-			return err
-		}
-		if elmType, err = strconv.Atoi(data[2]); err != nil {
-			return err
-		}
-		if num, err = strconv.Atoi(data[3]); err != nil {
-			return err
-		}
-
-		for j := 0; j < num; j++ {
-			scanner.Scan()
-			if dim == 0 || (dim == 1 && is2d == false) {
-				continue
-			}
-			data = strings.Fields(scanner.Text())
-			// data = strings.Split(strings.Trim(scanner.Text(), " "), " ")
-
-			// Reading current element
-			elm := make([]int, len(data)-1)
-			for k := 1; k < len(data); k++ {
-				elm[k-1], err = strconv.Atoi(data[k])
-				if err != nil {
-					return err
-				}
-				elm[k-1] -= minTag
-			}
-
-			switch elmType {
-			case 1: // 2-node line
-				if is2d == true {
-					// Boundary element
-					m.BE = append(m.BE, elm)
-				}
-			case 2: // 3-node triangle
-				if is2d == true {
-					// Finite element
-					m.FE = append(m.FE, elm)
-				} else {
-					// Boundary element
-					m.BE = append(m.BE, elm)
-				}
-			case 4: // 4-node tetrahedron
-				if is2d == false {
-					// Finite element
-					m.FE = append(m.FE, elm)
-				} else {
-					return fmt.Errorf("this format of MSH-file is not supported")
-				}
-			default:
-				return fmt.Errorf("this format of MSH-file is not supported")
-			}
-		}
-	}
-	scanner.Scan()
-	if scanner.Text() != "$EndElements" {
-		return fmt.Errorf("wrong MSH-file format")
-	}
-
-	if is2d == true {
-		m.FeType = Fe2d3
-	} else {
-		m.FeType = Fe3d4
-		if len(m.FE) == 0 {
-			// Shell finite element
-			m.FE = m.BE
-			m.BE = m.BE[:0:0]
-			m.FeType = Fe3d3s
+// FeName - name of the type of the finite elements, the names of the types of the mixed mesh are joined by "+"
+func (m *Mesh) FeName() string {
+	if m.Types == nil {
+		return feName(m.FeType)
+	}
+	types := append([]int(nil), m.Types...)
+	sort.Ints(types)
+	var names []string
+	for i := range types {
+		if i == 0 || types[i] != types[i-1] {
+			names = append(names, feName(types[i]))
 		}
 	}
-
-	// Shrink to fit
-	m.BE = m.BE[:len(m.BE):len(m.BE)]
-	m.FE = m.FE[:len(m.FE):len(m.FE)]
-
-	//m.Save("d:/cube.mesh")
-	return nil
+	return strings.Join(names, "+")
 }
 
-func (m *Mesh) FeName() string {
+func feName(feType int) string {
 	ret := ""
-	switch m.FeType {
+	switch feType {
 	case Fe1d2:
 		ret = "fe1d2"
 	case Fe2d3:
@@ -525,11 +462,40 @@ func (m *Mesh) FeName() string {
 		ret = "fe3d3s"
 	case Fe3d4s:
 		ret = "fe3d4s"
+	case Fe2d2t:
+		ret = "fe2d2t"
+	case Fe3d2t:
+		ret = "fe3d2t"
+	case Fe2d2b:
+		ret = "fe2d2b"
+	case Fe3d2b:
+		ret = "fe3d2b"
+	case Fe2d6:
+		ret = "fe2d6"
+	case Fe3d10:
+		ret = "fe3d10"
+	case Fe3d6:
+		ret = "fe3d6"
+	case Fe3d20:
+		ret = "fe3d20"
+	case Fe3d27:
+		ret = "fe3d27"
 	}
 	return ret
 }
 
+// Save - writing the mesh in the MESH-file or, if the extension is .msh, in the Gmsh MSH-file
 func (m *Mesh) Save(name string) error {
+	if strings.ToUpper(filepath.Ext(name)) == ".MSH" {
+		return m.SaveMsh(name)
+	}
+	return m.saveMesh(name)
+}
+
+func (m *Mesh) saveMesh(name string) error {
+	if m.Types != nil {
+		return fmt.Errorf("the finite elements of different types cannot be written in the MESH-file")
+	}
 	file, err := os.Create(name)
 	if err != nil {
 		return fmt.Errorf("error opening file")
@@ -595,7 +561,7 @@ func (m *Mesh) Save(name string) error {
 
 func (m *Mesh) BeNormal(index int) [3]float64 {
 	var norm [3]float64
-	if m.Is1D() {
+	if m.Is1D() || m.IsTruss() || m.IsBeam() {
 		norm = [3]float64{1.0, 0.0, 0.0}
 	} else if m.Is2D() {
 		norm = [3]float64{
@@ -629,7 +595,7 @@ func (m *Mesh) BeCoord(index int) *mat.Dense {
 }
 
 func (m *Mesh) FeCoord(index int) *mat.Dense {
-	_, feSize, feDim, _, _ := feParam(m.FeType)
+	feSize, feDim := len(m.FE[index]), m.FeDim()
 	res := make([]float64, feSize*feDim)
 	for i := 0; i < feSize; i++ {
 		for j := 0; j < feDim; j++ {
@@ -640,7 +606,7 @@ func (m *Mesh) FeCoord(index int) *mat.Dense {
 }
 
 func (m *Mesh) FeCenter(index int) *mat.VecDense {
-	_, feSize, feDim, _, _ := feParam(m.FeType)
+	feSize, feDim := len(m.FE[index]), m.FeDim()
 	xc := mat.NewVecDense(feDim, nil)
 	// Center of finite element
 	for j := 0; j < feDim; j++ {
@@ -690,14 +656,15 @@ func (m *Mesh) Is1D() bool {
 }
 
 func (m *Mesh) Is2D() bool {
-	if m.FeType == Fe2d3 || m.FeType == Fe2d4 {
+	if m.FeType == Fe2d3 || m.FeType == Fe2d4 || m.FeType == Fe2d6 {
 		return true
 	}
 	return false
 }
 
 func (m *Mesh) Is3D() bool {
-	if m.FeType == Fe3d4 || m.FeType == Fe3d8 {
+	switch m.FeType {
+	case Fe3d4, Fe3d6, Fe3d8, Fe3d10, Fe3d20, Fe3d27:
 		return true
 	}
 	return false
@@ -710,21 +677,31 @@ func (m *Mesh) IsShell() bool {
 	return false
 }
 
+// IsTruss - bars of the plane or space truss
+func (m *Mesh) IsTruss() bool {
+	return m.FeType == Fe2d2t || m.FeType == Fe3d2t
+}
+
+// IsBeam - bars of the plane or space frame
+func (m *Mesh) IsBeam() bool {
+	return m.FeType == Fe2d2b || m.FeType == Fe3d2b
+}
+
 func (m *Mesh) BeVolume(index int) float64 {
 	var res float64
 	x := m.BeCoord(index)
 	switch m.FeType {
-	case Fe1d2:
+	case Fe1d2, Fe2d2t, Fe3d2t, Fe2d2b, Fe3d2b:
 		res = 1.0
-	case Fe2d3:
+	case Fe2d3, Fe2d6:
 		fallthrough
 	case Fe2d4:
 		res = util.Volume1d2(x)
-	case Fe3d4:
+	case Fe3d4, Fe3d10:
 		fallthrough
 	case Fe3d3s:
 		res = util.Volume2d3(x)
-	case Fe3d8:
+	case Fe3d8, Fe3d6, Fe3d20, Fe3d27:
 		fallthrough
 	case Fe3d4s:
 		res = util.Volume2d4(x)
@@ -735,10 +712,10 @@ func (m *Mesh) BeVolume(index int) float64 {
 func (m *Mesh) FeVolume(index int) float64 {
 	var res float64
 	x := m.FeCoord(index)
-	switch m.FeType {
-	case Fe1d2:
+	switch m.ElementType(index) {
+	case Fe1d2, Fe2d2t, Fe3d2t, Fe2d2b, Fe3d2b:
 		res = util.Volume1d2(x)
-	case Fe2d3:
+	case Fe2d3, Fe2d6:
 		fallthrough
 	case Fe3d3s:
 		res = util.Volume2d3(x)
@@ -746,9 +723,11 @@ func (m *Mesh) FeVolume(index int) float64 {
 		fallthrough
 	case Fe3d4s:
 		res = util.Volume2d4(x)
-	case Fe3d4:
+	case Fe3d4, Fe3d10:
 		res = util.Volume3d4(x)
-	case Fe3d8:
+	case Fe3d6:
+		res = util.Volume3d6(x)
+	case Fe3d8, Fe3d20, Fe3d27:
 		res = util.Volume3d8(x)
 	}
 	return res
@@ -775,20 +754,44 @@ func (m *Mesh) FeVolume(index int) float64 {
 func (m *Mesh) CreateMeshMap() {
 	m.MeshMap = make([][]int, m.NumVertex())
 	msg := progress.NewProgress("Creating mesh map", 0, m.NumFE(), 10)
-	for i := range m.FE {
-		msg.AddProgress()
-		for j := range m.FE[i] {
-			for k := range m.FE[i] {
-				if m.FE[i][k] < m.FE[i][j] {
+	couple := func(nodes []int) {
+		for _, j := range nodes {
+			for _, k := range nodes {
+				if k < j {
 					continue
 				}
-				if !slices.Contains(m.MeshMap[m.FE[i][j]], m.FE[i][k]) {
-					m.MeshMap[m.FE[i][j]] = append(m.MeshMap[m.FE[i][j]], m.FE[i][k])
+				if !slices.Contains(m.MeshMap[j], k) {
+					m.MeshMap[j] = append(m.MeshMap[j], k)
 				}
 			}
 		}
 	}
+	for i := range m.FE {
+		msg.AddProgress()
+		couple(m.FE[i])
+	}
+	// The nodes of the two-node springs and the rigid links are coupled as well
+	for _, nodes := range m.discreteCouplings() {
+		couple(nodes)
+	}
 	for i := 0; i < m.NumVertex(); i++ {
 		sort.Ints(m.MeshMap[i])
 	}
 }
+
+// Adjacency - full (symmetric) node adjacency, each list is sorted and contains the node itself
+func (m *Mesh) Adjacency() [][]int {
+	res := make([][]int, m.NumVertex())
+	for i := range m.MeshMap {
+		for _, j := range m.MeshMap[i] {
+			res[i] = append(res[i], j)
+			if i != j {
+				res[j] = append(res[j], i)
+			}
+		}
+	}
+	for i := range res {
+		sort.Ints(res[i])
+	}
+	return res
+}
@@ -0,0 +1,899 @@
+package mesh
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Types of the Gmsh elements used as the finite elements
+var mshTypes = map[int]int{2: Fe2d3, 3: Fe2d4, 9: Fe2d6, 4: Fe3d4, 5: Fe3d8, 6: Fe3d6, 11: Fe3d10, 17: Fe3d20, 12: Fe3d27}
+
+// Types of the finite elements which may be mixed in one mesh: the boundary elements of the types of one list are of
+// one size (the triangular faces of the solids are stored as the quadrangles with the repeated last node), the type of
+// the mesh is the last one of the list present in it
+var mixedTypes = [][]int{{Fe2d3, Fe2d4}, {Fe3d4, Fe3d6, Fe3d8}}
+
+// mixedType - type of the mesh made of the finite elements of the given types
+func mixedType(types map[int]bool) (int, error) {
+	for _, list := range mixedTypes {
+		res, count := -1, 0
+		for _, t := range list {
+			if types[t] {
+				res = t
+				count++
+			}
+		}
+		if count == len(types) {
+			return res, nil
+		}
+	}
+	var names []string
+	for t := range types {
+		names = append(names, feName(t))
+	}
+	sort.Strings(names)
+	return 0, fmt.Errorf("the finite elements %s cannot be mixed in one mesh", strings.Join(names, ", "))
+}
+
+// Dimension and number of the nodes of the Gmsh elements by their types
+var mshElements = map[int][2]int{
+	// Points and lines
+	15: {0, 1}, 1: {1, 2}, 8: {1, 3}, 26: {1, 4}, 27: {1, 5}, 28: {1, 6},
+	// Triangles and quadrangles
+	2: {2, 3}, 3: {2, 4}, 9: {2, 6}, 10: {2, 9}, 16: {2, 8}, 20: {2, 9}, 21: {2, 10}, 22: {2, 12}, 23: {2, 15},
+	// Tetrahedra, hexahedra, wedges and pyramids
+	4: {3, 4}, 5: {3, 8}, 6: {3, 6}, 7: {3, 5}, 11: {3, 10}, 12: {3, 27}, 13: {3, 18}, 14: {3, 14}, 17: {3, 20},
+	// Incomplete and high order elements
+	18: {3, 15}, 19: {3, 13}, 24: {2, 15}, 25: {2, 21}, 29: {3, 20}, 30: {3, 35}, 31: {3, 56}, 92: {3, 64},
+	93: {3, 125},
+}
+
+// mshElement - element of the MSH-file: its dimension, Gmsh type, tag, physical groups and node tags
+type mshElement struct {
+	dim, elmType, tag int
+	physical          []int
+	nodes             []int
+}
+
+// mshReader - reader of the sections of the ASCII or binary MSH-file, the binary numbers are int (4 bytes),
+// size_t (dataSize bytes) and double
+type mshReader struct {
+	*bufio.Reader
+	version  int
+	binary   bool
+	order    binary.ByteOrder
+	dataSize int
+}
+
+func (r *mshReader) line() (string, error) {
+	s, err := r.ReadString('\n')
+	if err != nil && (err != io.EOF || len(s) == 0) {
+		return "", err
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// end - skipping the rest of the section up to its end
+func (r *mshReader) end(section string) error {
+	for {
+		s, err := r.line()
+		if err != nil {
+			return fmt.Errorf("wrong MSH-file format")
+		}
+		if s == "$End"+section {
+			return nil
+		}
+	}
+}
+
+func (r *mshReader) word() (string, error) {
+	var res []byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(res) > 0 {
+				return string(res), nil
+			}
+			return "", fmt.Errorf("wrong MSH-file format")
+		}
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			if len(res) > 0 {
+				return string(res), nil
+			}
+			continue
+		}
+		res = append(res, c)
+	}
+}
+
+func (r *mshReader) readBytes(n int) ([]byte, error) {
+	res := make([]byte, n)
+	if _, err := io.ReadFull(r, res); err != nil {
+		return nil, fmt.Errorf("wrong MSH-file format")
+	}
+	return res, nil
+}
+
+// readInt - the number of the int type
+func (r *mshReader) readInt() (int, error) {
+	if !r.binary {
+		s, err := r.word()
+		if err != nil {
+			return 0, err
+		}
+		return strconv.Atoi(s)
+	}
+	b, err := r.readBytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return int(int32(r.order.Uint32(b))), nil
+}
+
+// readSize - the number of the size_t type (MSH 4)
+func (r *mshReader) readSize() (int, error) {
+	if !r.binary || r.dataSize == 4 {
+		return r.readInt()
+	}
+	b, err := r.readBytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return int(r.order.Uint64(b)), nil
+}
+
+func (r *mshReader) readFloat() (float64, error) {
+	if !r.binary {
+		s, err := r.word()
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseFloat(s, 64)
+	}
+	b, err := r.readBytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(r.order.Uint64(b)), nil
+}
+
+// readInts - n numbers read by the function
+func (r *mshReader) readInts(n int, read func() (int, error)) ([]int, error) {
+	var err error
+	res := make([]int, n)
+	for i := range res {
+		if res[i], err = read(); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// header - version and type of the MSH-file
+func (r *mshReader) header() error {
+	if s, err := r.line(); err != nil || s != "$MeshFormat" {
+		return fmt.Errorf("wrong MSH-file format")
+	}
+	s, err := r.line()
+	if err != nil {
+		return fmt.Errorf("wrong MSH-file format")
+	}
+	data := strings.Fields(s)
+	if len(data) != 3 {
+		return fmt.Errorf("wrong MSH-file format")
+	}
+	switch {
+	case strings.HasPrefix(data[0], "2."):
+		r.version = 2
+	case data[0] == "4.1":
+		r.version = 4
+	default:
+		return fmt.Errorf("the version %s of MSH-file is not supported", data[0])
+	}
+	if r.dataSize, err = strconv.Atoi(data[2]); err != nil || (r.dataSize != 4 && r.dataSize != 8) {
+		return fmt.Errorf("wrong MSH-file format")
+	}
+	if r.binary = data[1] == "1"; r.binary {
+		// The integer one written in the byte order of the file
+		b, err := r.readBytes(4)
+		if err != nil {
+			return err
+		}
+		switch {
+		case binary.LittleEndian.Uint32(b) == 1:
+			r.order = binary.LittleEndian
+		case binary.BigEndian.Uint32(b) == 1:
+			r.order = binary.BigEndian
+		default:
+			return fmt.Errorf("wrong MSH-file format")
+		}
+	}
+	return r.end("MeshFormat")
+}
+
+// physicalNames - names of the physical groups by their dimensions and tags
+func (r *mshReader) physicalNames(names map[[2]int]string) error {
+	s, err := r.line()
+	if err != nil {
+		return fmt.Errorf("wrong MSH-file format")
+	}
+	num, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < num; i++ {
+		if s, err = r.line(); err != nil {
+			return fmt.Errorf("wrong MSH-file format")
+		}
+		data := strings.Fields(s)
+		begin, end := strings.Index(s, "\""), strings.LastIndex(s, "\"")
+		if len(data) < 3 || begin < 0 || end <= begin {
+			return fmt.Errorf("wrong MSH-file format")
+		}
+		dim, err := strconv.Atoi(data[0])
+		if err != nil {
+			return err
+		}
+		tag, err := strconv.Atoi(data[1])
+		if err != nil {
+			return err
+		}
+		names[[2]int{dim, tag}] = s[begin+1 : end]
+	}
+	return r.end("PhysicalNames")
+}
+
+// entities - physical groups of the points, curves, surfaces and volumes by their dimensions and tags (MSH 4)
+func (r *mshReader) entities(physical map[[2]int][]int) error {
+	num, err := r.readInts(4, r.readSize)
+	if err != nil {
+		return err
+	}
+	for dim := range num {
+		for i := 0; i < num[dim]; i++ {
+			tag, err := r.readInt()
+			if err != nil {
+				return err
+			}
+			// The point or the bounding box
+			numCoord := 6
+			if dim == 0 {
+				numCoord = 3
+			}
+			for j := 0; j < numCoord; j++ {
+				if _, err = r.readFloat(); err != nil {
+					return err
+				}
+			}
+			n, err := r.readSize()
+			if err != nil {
+				return err
+			}
+			if physical[[2]int{dim, tag}], err = r.readInts(n, r.readInt); err != nil {
+				return err
+			}
+			if dim > 0 {
+				// Bounding entities
+				if n, err = r.readSize(); err != nil {
+					return err
+				}
+				if _, err = r.readInts(n, r.readInt); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return r.end("Entities")
+}
+
+// nodes - coordinates of the nodes, index - their indices by the tags
+func (r *mshReader) nodes(index map[int]int) ([][]float64, error) {
+	var res [][]float64
+	read := func(tag int, parametric int) error {
+		x := make([]float64, 3+parametric)
+		for k := range x {
+			var err error
+			if x[k], err = r.readFloat(); err != nil {
+				return err
+			}
+		}
+		index[tag] = len(res)
+		res = append(res, x[:3])
+		return nil
+	}
+	if r.version == 2 {
+		s, err := r.line()
+		if err != nil {
+			return nil, fmt.Errorf("wrong MSH-file format")
+		}
+		num, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < num; i++ {
+			tag, err := r.readInt()
+			if err != nil {
+				return nil, err
+			}
+			if err = read(tag, 0); err != nil {
+				return nil, err
+			}
+		}
+		return res, r.end("Nodes")
+	}
+	header, err := r.readInts(4, r.readSize)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < header[0]; i++ {
+		block, err := r.readInts(3, r.readInt)
+		if err != nil {
+			return nil, err
+		}
+		num, err := r.readSize()
+		if err != nil {
+			return nil, err
+		}
+		tags, err := r.readInts(num, r.readSize)
+		if err != nil {
+			return nil, err
+		}
+		// The parametric coordinates follow the coordinates of the nodes
+		parametric := 0
+		if block[2] != 0 {
+			parametric = block[0]
+		}
+		for _, tag := range tags {
+			if err = read(tag, parametric); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, r.end("Nodes")
+}
+
+// elements - elements of the MSH-file, physical - physical groups of the entities (MSH 4)
+func (r *mshReader) elements(physical map[[2]int][]int) ([]mshElement, error) {
+	var res []mshElement
+	if r.version == 2 {
+		s, err := r.line()
+		if err != nil {
+			return nil, fmt.Errorf("wrong MSH-file format")
+		}
+		num, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		read := func(tag, elmType, numTags int) error {
+			// The tags of the physical and elementary entities (and partitions) are followed by the nodes
+			t, ok := mshElements[elmType]
+			if !ok {
+				return fmt.Errorf("unsupported type of the MSH-file element: %d", elmType)
+			}
+			data, err := r.readInts(numTags+t[1], r.readInt)
+			if err != nil {
+				return err
+			}
+			elm := mshElement{dim: t[0], elmType: elmType, tag: tag, nodes: data[numTags:]}
+			if numTags > 0 && data[0] != 0 {
+				elm.physical = []int{data[0]}
+			}
+			res = append(res, elm)
+			return nil
+		}
+		for len(res) < num {
+			if !r.binary {
+				// The tag, type and number of the tags of the element
+				data, err := r.readInts(3, r.readInt)
+				if err != nil {
+					return nil, err
+				}
+				if err = read(data[0], data[1], data[2]); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			// The binary block of the elements: their type, number and number of the tags
+			block, err := r.readInts(3, r.readInt)
+			if err != nil {
+				return nil, err
+			}
+			for j := 0; j < block[1]; j++ {
+				tag, err := r.readInt()
+				if err != nil {
+					return nil, err
+				}
+				if err = read(tag, block[0], block[2]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return res, r.end("Elements")
+	}
+	header, err := r.readInts(4, r.readSize)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < header[0]; i++ {
+		block, err := r.readInts(3, r.readInt)
+		if err != nil {
+			return nil, err
+		}
+		num, err := r.readSize()
+		if err != nil {
+			return nil, err
+		}
+		t, ok := mshElements[block[2]]
+		if !ok {
+			return nil, fmt.Errorf("unsupported type of the MSH-file element: %d", block[2])
+		}
+		for j := 0; j < num; j++ {
+			data, err := r.readInts(1+t[1], r.readSize)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, mshElement{dim: block[0], elmType: block[2], tag: data[0],
+				physical: physical[[2]int{block[0], block[1]}], nodes: data[1:]})
+		}
+	}
+	return res, r.end("Elements")
+}
+
+// loadMsh - Gmsh MSH 2.2 or 4.1 file, ASCII or binary. The elements of the highest dimension are the finite
+// elements, the elements of the lower dimension are the boundary ones (the surface elements without the volumes are
+// the shells). The physical groups are the sets named by $PhysicalNames or by their tags
+func (m *Mesh) loadMsh(name string) error {
+	var x [][]float64
+	var elements []mshElement
+	file, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("error opening file")
+	}
+	defer func() {
+		err = file.Close()
+	}()
+	r := mshReader{Reader: bufio.NewReader(file)}
+	if err = r.header(); err != nil {
+		return err
+	}
+	index := make(map[int]int)
+	names := make(map[[2]int]string)
+	physical := make(map[[2]int][]int)
+	for {
+		s, err := r.line()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch s {
+		case "":
+			continue
+		case "$PhysicalNames":
+			err = r.physicalNames(names)
+		case "$Entities":
+			err = r.entities(physical)
+		case "$Nodes":
+			x, err = r.nodes(index)
+		case "$Elements":
+			elements, err = r.elements(physical)
+		default:
+			if !strings.HasPrefix(s, "$") {
+				return fmt.Errorf("wrong MSH-file format")
+			}
+			err = r.end(s[1:])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// The elements are grouped in the blocks by their entities and types, they are taken in the order of their tags
+	sort.SliceStable(elements, func(i, j int) bool { return elements[i].tag < elements[j].tag })
+	return m.createMsh(x, index, elements, names, false)
+}
+
+// createMsh - the mesh of the nodes x (their indices by the tags) and the elements of the MSH-file, the surface
+// elements are the shells if shell is set even though all nodes lie in the plane z = 0
+func (m *Mesh) createMsh(x [][]float64, index map[int]int, elements []mshElement, names map[[2]int]string, shell bool) error {
+	eps := 1.0e-10
+	is2d := !shell
+	for i := range x {
+		if math.Abs(x[i][2]) > eps {
+			is2d = false
+		}
+	}
+	meshDim := 0
+	for i := range elements {
+		if elements[i].dim > meshDim {
+			meshDim = elements[i].dim
+		}
+	}
+	if meshDim < 2 {
+		return fmt.Errorf("this format of MSH-file is not supported")
+	}
+	isShell := meshDim == 2 && !is2d
+	feType := -1
+	var types []int
+	present := make(map[int]bool)
+	m.X = x
+	m.FE = make([][]int, 0, len(elements))
+	m.BE = make([][]int, 0, len(elements))
+	sets := make(map[string]map[int]bool)
+	m.Sets = make(map[string]Set)
+	for _, e := range elements {
+		elm := make([]int, len(e.nodes))
+		for j, tag := range e.nodes {
+			k, ok := index[tag]
+			if !ok {
+				return fmt.Errorf("wrong MSH-file format")
+			}
+			elm[j] = k
+		}
+		var feIndex, beIndex = -1, -1
+		switch e.dim {
+		case meshDim:
+			// Finite element
+			t, ok := mshTypes[e.elmType]
+			if isShell {
+				t, ok = map[int]int{2: Fe3d3s, 3: Fe3d4s}[e.elmType]
+			}
+			if !ok {
+				return fmt.Errorf("unsupported type of the MSH-file element: %d", e.elmType)
+			}
+			feType = t
+			types = append(types, t)
+			present[t] = true
+			feIndex = len(m.FE)
+			m.FE = append(m.FE, elm)
+			if isShell {
+				beIndex = feIndex
+			}
+		case meshDim - 1:
+			// Boundary element
+			if !isShell {
+				beIndex = len(m.BE)
+				m.BE = append(m.BE, elm)
+			}
+		}
+		for _, p := range e.physical {
+			groupName, ok := names[[2]int{e.dim, p}]
+			if !ok {
+				groupName = strconv.Itoa(p)
+			}
+			set := m.Sets[groupName]
+			if sets[groupName] == nil {
+				sets[groupName] = make(map[int]bool)
+			}
+			for _, k := range elm {
+				sets[groupName][k] = true
+			}
+			if feIndex >= 0 {
+				set.FE = append(set.FE, feIndex)
+			}
+			if beIndex >= 0 {
+				set.BE = append(set.BE, beIndex)
+			}
+			m.Sets[groupName] = set
+		}
+	}
+	for groupName, nodes := range sets {
+		set := m.Sets[groupName]
+		for k := range nodes {
+			set.Nodes = append(set.Nodes, k)
+		}
+		sort.Ints(set.Nodes)
+		m.Sets[groupName] = set
+	}
+	m.FeType, m.Types = feType, nil
+	if len(present) > 1 {
+		var err error
+		if m.FeType, err = mixedType(present); err != nil {
+			return err
+		}
+		m.Types = types
+	}
+	if is2d {
+		for i := range m.X {
+			m.X[i] = m.X[i][:2]
+		}
+	}
+	if isShell {
+		m.BE = m.FE
+	}
+	for i := range m.FE {
+		if _, feSize, _, _, _ := feParam(m.ElementType(i)); len(m.FE[i]) != feSize {
+			return fmt.Errorf("this format of MSH-file is not supported")
+		}
+	}
+	beSize, _, _, _, _ := feParam(m.FeType)
+	for i := range m.BE {
+		if m.Is3D() && beSize == 4 && len(m.BE[i]) == 3 {
+			// The triangular faces of the wedges and of the tetrahedra of the mixed mesh are stored as the
+			// quadrangles with the repeated last node
+			m.BE[i] = append(m.BE[i], m.BE[i][2])
+		}
+		if len(m.BE[i]) != beSize {
+			return fmt.Errorf("this format of MSH-file is not supported")
+		}
+	}
+	if len(m.Sets) == 0 {
+		m.Sets = nil
+	}
+	// Shrink to fit
+	m.BE = m.BE[:len(m.BE):len(m.BE)]
+	m.FE = m.FE[:len(m.FE):len(m.FE)]
+	return nil
+}
+
+// Gmsh types of the written elements by their dimension and number of the nodes
+var mshWriteTypes = map[[2]int]int{
+	{0, 1}: 15, {1, 2}: 1, {1, 3}: 8,
+	{2, 3}: 2, {2, 4}: 3, {2, 6}: 9, {2, 8}: 16, {2, 9}: 10,
+	{3, 4}: 4, {3, 6}: 6, {3, 8}: 5, {3, 10}: 11, {3, 20}: 17, {3, 27}: 12,
+}
+
+// MshView - post-processing view of the MSH-file: Values[i] are the components of the i-th node (or of the i-th
+// finite element if Element is set), the views with the same name are the steps of one view
+type MshView struct {
+	Name    string
+	Step    int
+	Time    float64
+	Element bool
+	Values  [][]float64
+}
+
+// mshEntity - elements of one type of the entity of the MSH-file with their tags
+type mshEntity struct {
+	dim, tag, elmType int
+	physical          []int
+	elements          [][]int
+	tags              []int
+}
+
+// feShapeDim - dimension of the shape of the finite elements
+func (m *Mesh) feShapeDim() int {
+	switch {
+	case m.Is1D() || m.IsTruss() || m.IsBeam():
+		return 1
+	case m.Is2D() || m.IsShell():
+		return 2
+	}
+	return 3
+}
+
+// mshEntities - the finite elements, the boundary elements (except for the shells) and the nodes of the sets not
+// belonging to their elements grouped in the entities by the sets they belong to. The tags of the finite elements
+// are their indices plus one, the boundary elements and the points follow them
+func (m *Mesh) mshEntities() ([]mshEntity, error) {
+	names := m.SetNames()
+	var res []mshEntity
+	index := make(map[string]int)
+	elmTag := 0
+	add := func(dim int, elm []int, physical []int) error {
+		elmTag++
+		elmType, ok := mshWriteTypes[[2]int{dim, len(elm)}]
+		if !ok {
+			return fmt.Errorf("unsupported type of the element for the MSH-file")
+		}
+		// The entities are numbered by their sets in every dimension
+		key := fmt.Sprint(dim, physical)
+		tag, ok := index[key]
+		if !ok {
+			tag = len(index) + 1
+			index[key] = tag
+		}
+		for i := range res {
+			if res[i].dim == dim && res[i].tag == tag && res[i].elmType == elmType {
+				res[i].elements = append(res[i].elements, elm)
+				res[i].tags = append(res[i].tags, elmTag)
+				return nil
+			}
+		}
+		res = append(res, mshEntity{dim: dim, tag: tag, elmType: elmType, physical: physical, elements: [][]int{elm},
+			tags: []int{elmTag}})
+		return nil
+	}
+	// physical - the tags (the positions of the names plus one) of the sets containing the element
+	physical := func(kind, index int) []int {
+		var tags []int
+		for i, name := range names {
+			if m.InSet(name, kind, index) || (kind == SetFE && m.IsShell() && m.InSet(name, SetBE, index)) {
+				tags = append(tags, i+1)
+			}
+		}
+		return tags
+	}
+	dim := m.feShapeDim()
+	for i := range m.FE {
+		if err := add(dim, m.FE[i], physical(SetFE, i)); err != nil {
+			return nil, err
+		}
+	}
+	if !m.IsShell() {
+		for i := range m.BE {
+			elm := m.BE[i]
+			if m.Is3D() && len(elm) == 4 && elm[3] == elm[2] {
+				// The triangular face of the wedge or of the tetrahedron of the mixed mesh
+				elm = elm[:3]
+			}
+			if err := add(dim-1, elm, physical(SetBE, i)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// The nodes of the sets which are not the nodes of their elements are written as the points
+	points := make(map[int][]int)
+	for i, name := range names {
+		covered := make(map[int]bool)
+		for _, j := range m.Sets[name].FE {
+			for _, node := range m.FE[j] {
+				covered[node] = true
+			}
+		}
+		for _, j := range m.Sets[name].BE {
+			for _, node := range m.BE[j] {
+				covered[node] = true
+			}
+		}
+		for _, node := range m.Sets[name].Nodes {
+			if !covered[node] {
+				points[node] = append(points[node], i+1)
+			}
+		}
+	}
+	nodes := make([]int, 0, len(points))
+	for node := range points {
+		nodes = append(nodes, node)
+	}
+	sort.Ints(nodes)
+	for _, node := range nodes {
+		if err := add(0, []int{node}, points[node]); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// SaveMsh - writing the mesh and the views in the MSH 4.1 ASCII file. The sets are written as the physical groups
+// of the entities, the nodes are numbered from one
+func (m *Mesh) SaveMsh(name string, views ...MshView) error {
+	if m.NumFE() == 0 {
+		return fmt.Errorf("the mesh has no finite elements")
+	}
+	entities, err := m.mshEntities()
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("error creating MSH-file")
+	}
+	defer func() {
+		err = file.Close()
+	}()
+	w := bufio.NewWriter(file)
+	coord := func(node, j int) float64 {
+		if j < len(m.X[node]) {
+			return m.X[node][j]
+		}
+		return 0.0
+	}
+	fmt.Fprintf(w, "$MeshFormat\n4.1 0 8\n$EndMeshFormat\n")
+	// Physical groups of the sets in all dimensions of their entities
+	var groups []string
+	for i, setName := range m.SetNames() {
+		done := make(map[int]bool)
+		for _, e := range entities {
+			if !done[e.dim] && slices.Contains(e.physical, i+1) {
+				done[e.dim] = true
+				groups = append(groups, fmt.Sprintf("%d %d \"%s\"", e.dim, i+1, setName))
+			}
+		}
+	}
+	if len(groups) > 0 {
+		fmt.Fprintf(w, "$PhysicalNames\n%d\n%s\n$EndPhysicalNames\n", len(groups), strings.Join(groups, "\n"))
+	}
+	// Entities with the bounding box of the mesh
+	var boxMin, boxMax [3]float64
+	for i := range m.X {
+		for j := 0; j < 3; j++ {
+			if i == 0 || coord(i, j) < boxMin[j] {
+				boxMin[j] = coord(i, j)
+			}
+			if i == 0 || coord(i, j) > boxMax[j] {
+				boxMax[j] = coord(i, j)
+			}
+		}
+	}
+	var count [4]int
+	var tags [4][]int
+	physical := make(map[[2]int][]int)
+	for _, e := range entities {
+		key := [2]int{e.dim, e.tag}
+		if _, ok := physical[key]; !ok {
+			physical[key] = e.physical
+			count[e.dim]++
+			tags[e.dim] = append(tags[e.dim], e.tag)
+		}
+	}
+	fmt.Fprintf(w, "$Entities\n%d %d %d %d\n", count[0], count[1], count[2], count[3])
+	for dim := range tags {
+		for _, tag := range tags[dim] {
+			p := physical[[2]int{dim, tag}]
+			if dim == 0 {
+				fmt.Fprintf(w, "%d %g %g %g", tag, boxMin[0], boxMin[1], boxMin[2])
+			} else {
+				fmt.Fprintf(w, "%d %g %g %g %g %g %g", tag, boxMin[0], boxMin[1], boxMin[2], boxMax[0], boxMax[1], boxMax[2])
+			}
+			fmt.Fprintf(w, " %d", len(p))
+			for _, t := range p {
+				fmt.Fprintf(w, " %d", t)
+			}
+			if dim > 0 {
+				fmt.Fprintf(w, " 0")
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	fmt.Fprintf(w, "$EndEntities\n")
+	// All nodes belong to the first entity of the finite elements
+	fmt.Fprintf(w, "$Nodes\n1 %d 1 %d\n%d %d 0 %d\n", m.NumVertex(), m.NumVertex(), entities[0].dim, entities[0].tag, m.NumVertex())
+	for i := range m.X {
+		fmt.Fprintf(w, "%d\n", i+1)
+	}
+	for i := range m.X {
+		fmt.Fprintf(w, "%s %s %s\n", strconv.FormatFloat(coord(i, 0), 'g', -1, 64),
+			strconv.FormatFloat(coord(i, 1), 'g', -1, 64), strconv.FormatFloat(coord(i, 2), 'g', -1, 64))
+	}
+	fmt.Fprintf(w, "$EndNodes\n")
+	var numElements int
+	for _, e := range entities {
+		numElements += len(e.elements)
+	}
+	fmt.Fprintf(w, "$Elements\n%d %d 1 %d\n", len(entities), numElements, numElements)
+	for _, e := range entities {
+		fmt.Fprintf(w, "%d %d %d %d\n", e.dim, e.tag, e.elmType, len(e.elements))
+		for i, elm := range e.elements {
+			fmt.Fprintf(w, "%d", e.tags[i])
+			for _, node := range elm {
+				fmt.Fprintf(w, " %d", node+1)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	fmt.Fprintf(w, "$EndElements\n")
+	// Views
+	for _, v := range views {
+		section := "NodeData"
+		if v.Element {
+			section = "ElementData"
+		}
+		numComponents := 1
+		if len(v.Values) > 0 {
+			numComponents = len(v.Values[0])
+		}
+		fmt.Fprintf(w, "$%s\n1\n\"%s\"\n1\n%g\n3\n%d\n%d\n%d\n", section, v.Name, v.Time, v.Step, numComponents, len(v.Values))
+		for i := range v.Values {
+			fmt.Fprintf(w, "%d", i+1)
+			for _, value := range v.Values[i] {
+				fmt.Fprintf(w, " %0.8e", value)
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "$End%s\n", section)
+	}
+	if err = w.Flush(); err != nil {
+		return fmt.Errorf("error writing MSH-file")
+	}
+	return err
+}
@@ -0,0 +1,343 @@
+package mesh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// mshBlock - elements of one type of the MSH-file: the dimension and tag of their entity (physical group), Gmsh
+// type, tags of the elements and their nodes
+type mshBlock struct {
+	dim, entity, elmType int
+	tags                 []int
+	nodes                [][]int
+}
+
+// mshWriter - the numbers of the MSH-file written as text or in the binary form (little endian, size_t of 8 bytes)
+type mshWriter struct {
+	bytes.Buffer
+	binary bool
+}
+
+func (w *mshWriter) numbers(isSize bool, values ...int) {
+	for _, v := range values {
+		switch {
+		case !w.binary:
+			fmt.Fprintf(w, "%d\n", v)
+		case isSize:
+			_ = binary.Write(w, binary.LittleEndian, uint64(v))
+		default:
+			_ = binary.Write(w, binary.LittleEndian, int32(v))
+		}
+	}
+}
+
+func (w *mshWriter) floats(values ...float64) {
+	for _, v := range values {
+		if w.binary {
+			_ = binary.Write(w, binary.LittleEndian, v)
+			continue
+		}
+		fmt.Fprintf(w, "%g\n", v)
+	}
+}
+
+// section - the section of the MSH-file, its binary data ends with the new line
+func (w *mshWriter) section(name string, body func()) {
+	fmt.Fprintf(w, "$%s\n", name)
+	body()
+	if w.binary {
+		w.WriteString("\n")
+	}
+	fmt.Fprintf(w, "$End%s\n", name)
+}
+
+// writeMshFile - MSH-file of the version 2.2 or 4.1 with the nodes x (by their tags) and the blocks of the elements,
+// the entity of the block is also its physical group, names - names of the physical groups by the dimension and tag
+func writeMshFile(t *testing.T, version string, isBinary bool, x map[int][3]float64, blocks []mshBlock, names map[[2]int]string) string {
+	var tags []int
+	for tag := range x {
+		tags = append(tags, tag)
+	}
+	sort.Ints(tags)
+	w := &mshWriter{}
+	fmt.Fprintf(w, "$MeshFormat\n%s %d 8\n", version, map[bool]int{false: 0, true: 1}[isBinary])
+	if isBinary {
+		_ = binary.Write(w, binary.LittleEndian, int32(1))
+		w.WriteString("\n")
+	}
+	w.WriteString("$EndMeshFormat\n")
+	fmt.Fprintf(w, "$PhysicalNames\n%d\n", len(names))
+	for _, b := range blocks {
+		if name, ok := names[[2]int{b.dim, b.entity}]; ok {
+			fmt.Fprintf(w, "%d %d \"%s\"\n", b.dim, b.entity, name)
+		}
+	}
+	w.WriteString("$EndPhysicalNames\n")
+	w.binary = isBinary
+	numElements := 0
+	for _, b := range blocks {
+		numElements += len(b.tags)
+	}
+	if version == "2.2" {
+		w.section("Nodes", func() {
+			fmt.Fprintf(w, "%d\n", len(tags))
+			for _, tag := range tags {
+				p := x[tag]
+				w.numbers(false, tag)
+				w.floats(p[:]...)
+			}
+		})
+		w.section("Elements", func() {
+			fmt.Fprintf(w, "%d\n", numElements)
+			for _, b := range blocks {
+				if w.binary {
+					w.numbers(false, b.elmType, len(b.tags), 2)
+				}
+				for i, tag := range b.tags {
+					if w.binary {
+						w.numbers(false, tag, b.entity, b.entity)
+					} else {
+						w.numbers(false, tag, b.elmType, 2, b.entity, b.entity)
+					}
+					w.numbers(false, b.nodes[i]...)
+				}
+			}
+		})
+		return saveMsh(t, w.Bytes())
+	}
+	w.section("Entities", func() {
+		var num [4]int
+		for _, b := range blocks {
+			num[b.dim]++
+		}
+		w.numbers(true, num[:]...)
+		for dim := 0; dim < 4; dim++ {
+			for _, b := range blocks {
+				if b.dim != dim {
+					continue
+				}
+				w.numbers(false, b.entity)
+				if dim == 0 {
+					w.floats(0, 0, 0)
+				} else {
+					w.floats(0, 0, 0, 2, 1, 0)
+				}
+				w.numbers(true, 1)
+				w.numbers(false, b.entity)
+				if dim > 0 {
+					w.numbers(true, 0)
+				}
+			}
+		}
+	})
+	w.section("Nodes", func() {
+		w.numbers(true, 1, len(tags), tags[0], tags[len(tags)-1])
+		w.numbers(false, 2, 1, 0)
+		w.numbers(true, len(tags))
+		w.numbers(true, tags...)
+		for _, tag := range tags {
+			p := x[tag]
+			w.floats(p[:]...)
+		}
+	})
+	w.section("Elements", func() {
+		w.numbers(true, len(blocks), numElements, 1, numElements)
+		for _, b := range blocks {
+			w.numbers(false, b.dim, b.entity, b.elmType)
+			w.numbers(true, len(b.tags))
+			for i, tag := range b.tags {
+				w.numbers(true, tag)
+				w.numbers(true, b.nodes[i]...)
+			}
+		}
+	})
+	return saveMsh(t, w.Bytes())
+}
+
+func saveMsh(t *testing.T, data []byte) string {
+	name := filepath.Join(t.TempDir(), "test.msh")
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+// The plate of two quadrangles with the gap in the node tags, its right side and the corner point are the physical
+// groups, the quadrangles follow the line in the file, their block is written first
+func TestLoadMsh(t *testing.T) {
+	x := map[int][3]float64{1: {0, 0, 0}, 2: {1, 0, 0}, 3: {2, 0, 0}, 5: {0, 1, 0}, 6: {1, 1, 0}, 7: {2, 1, 0}}
+	blocks := []mshBlock{
+		{2, 1, 3, []int{3, 4}, [][]int{{1, 2, 6, 5}, {2, 3, 7, 6}}},
+		{0, 3, 15, []int{1}, [][]int{{1}}},
+		{1, 2, 1, []int{2}, [][]int{{3, 7}}},
+	}
+	names := map[[2]int]string{{2, 1}: "plate", {1, 2}: "right"}
+	want := map[string]Set{
+		"plate": {Nodes: []int{0, 1, 2, 3, 4, 5}, FE: []int{0, 1}},
+		"right": {Nodes: []int{2, 5}, BE: []int{0}},
+		"3":     {Nodes: []int{0}},
+	}
+	for _, version := range []string{"2.2", "4.1"} {
+		for _, isBinary := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s binary=%v", version, isBinary), func(t *testing.T) {
+				var m Mesh
+				if err := m.Load(writeMshFile(t, version, isBinary, x, blocks, names)); err != nil {
+					t.Fatal(err)
+				}
+				if m.FeType != Fe2d4 || m.Types != nil {
+					t.Fatalf("type of the mesh is %s", m.FeName())
+				}
+				if got := m.X[5]; !reflect.DeepEqual(got, []float64{2, 1}) {
+					t.Fatalf("node 5 is %v", got)
+				}
+				if !reflect.DeepEqual(m.FE, [][]int{{0, 1, 4, 3}, {1, 2, 5, 4}}) || !reflect.DeepEqual(m.BE, [][]int{{2, 5}}) {
+					t.Fatalf("elements are %v, %v", m.FE, m.BE)
+				}
+				if !reflect.DeepEqual(m.Sets, want) {
+					t.Fatalf("sets are %v, want %v", m.Sets, want)
+				}
+			})
+		}
+	}
+}
+
+// The triangles mix with the quadrangles, the linear triangles do not mix with the quadratic ones
+func TestLoadMshMixed(t *testing.T) {
+	x := map[int][3]float64{1: {0, 0, 0}, 2: {1, 0, 0}, 3: {2, 0, 0}, 4: {0, 1, 0}, 5: {1, 1, 0}, 6: {0.5, 0, 0},
+		7: {0.5, 0.5, 0}, 8: {0, 0.5, 0}}
+	tests := []struct {
+		name   string
+		blocks []mshBlock
+		feName string
+	}{
+		{"triangle and quadrangle", []mshBlock{{2, 1, 3, []int{2}, [][]int{{2, 3, 5, 4}}}, {2, 1, 2, []int{1}, [][]int{{1, 2, 4}}}},
+			"fe2d3+fe2d4"},
+		{"linear and quadratic triangles", []mshBlock{{2, 1, 2, []int{1}, [][]int{{2, 3, 5}}},
+			{2, 1, 9, []int{2}, [][]int{{1, 2, 4, 6, 7, 8}}}}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var m Mesh
+			err := m.Load(writeMshFile(t, "4.1", false, x, test.blocks, nil))
+			if test.feName == "" {
+				if err == nil {
+					t.Fatal("the mixed mesh is accepted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.FeName() != test.feName || m.FeType != Fe2d4 {
+				t.Fatalf("type of the mesh is %s", m.FeName())
+			}
+			// The elements are in the order of their tags
+			if !reflect.DeepEqual(m.Types, []int{Fe2d3, Fe2d4}) || m.ElementType(1) != Fe2d4 {
+				t.Fatalf("types of the elements are %v", m.Types)
+			}
+			if v := m.FeVolume(0) + m.FeVolume(1); math.Abs(v-1.5) > 1.0e-12 {
+				t.Fatalf("area of the mesh is %g", v)
+			}
+		})
+	}
+}
+
+// The hexahedron [0, 1]^3 and the wedge attached to its face x = 1, the faces z = 0 are the physical group "bottom"
+const mixedSolidMsh = `$MeshFormat
+2.2 0 8
+$EndMeshFormat
+$PhysicalNames
+2
+2 1 "bottom"
+3 2 "body"
+$EndPhysicalNames
+$Nodes
+10
+1 0 0 0
+2 1 0 0
+3 1 1 0
+4 0 1 0
+5 0 0 1
+6 1 0 1
+7 1 1 1
+8 0 1 1
+9 2 0 0
+10 2 1 0
+$EndNodes
+$Elements
+4
+1 3 2 1 1 1 4 3 2
+2 3 2 1 1 2 3 10 9
+3 5 2 2 1 1 2 3 4 5 6 7 8
+4 6 2 2 1 2 9 6 3 10 7
+$EndElements
+`
+
+// The meshes written to the MSH-files are read back with the same nodes, elements and sets
+func TestMshRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		feType  int
+		mixed   bool
+		sets    []string
+	}{
+		{name: "cube.msh", file: "../../../data/cube.msh", feType: Fe3d4},
+		{name: "cube.mesh", file: "../../../data/cube.mesh", feType: Fe3d8},
+		{name: "quad.mesh", file: "../../../data/quad.mesh", feType: Fe2d4},
+		{name: "hexahedron and wedge", content: mixedSolidMsh, feType: Fe3d8, mixed: true,
+			sets: []string{"bottom", "body"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			name := test.file
+			if len(test.content) > 0 {
+				name = filepath.Join(dir, "mesh.msh")
+				if err := os.WriteFile(name, []byte(test.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var m, res Mesh
+			if err := m.Load(name); err != nil {
+				t.Fatal(err)
+			}
+			if m.FeType != test.feType || (m.Types != nil) != test.mixed {
+				t.Fatalf("type of the mesh is %s", m.FeName())
+			}
+			for _, set := range test.sets {
+				if len(m.Sets[set].Nodes) == 0 {
+					t.Fatalf("the set %s is empty", set)
+				}
+			}
+			saved := filepath.Join(dir, "saved.msh")
+			if err := m.Save(saved); err != nil {
+				t.Fatal(err)
+			}
+			if err := res.Load(saved); err != nil {
+				t.Fatal(err)
+			}
+			if res.FeType != m.FeType || !reflect.DeepEqual(res.Types, m.Types) {
+				t.Fatalf("type of the mesh read back is %s, want %s", res.FeName(), m.FeName())
+			}
+			if !reflect.DeepEqual(res.X, m.X) {
+				t.Fatal("the nodes differ")
+			}
+			if !reflect.DeepEqual(res.FE, m.FE) || !reflect.DeepEqual(res.BE, m.BE) {
+				t.Fatal("the elements differ")
+			}
+			if !reflect.DeepEqual(res.Sets, m.Sets) {
+				t.Fatalf("the sets %v differ from %v", res.Sets, m.Sets)
+			}
+		})
+	}
+}
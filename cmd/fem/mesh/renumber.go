@@ -0,0 +1,185 @@
+package mesh
+
+import (
+	"fmt"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Profile - number of elements in the lower triangle profile of the node connectivity matrix
+func (m *Mesh) Profile() int {
+	first := make([]int, m.NumVertex())
+	for i := range first {
+		first[i] = i
+	}
+	for i := range m.MeshMap {
+		for _, j := range m.MeshMap[i] {
+			if i < first[j] {
+				first[j] = i
+			}
+		}
+	}
+	profile := 0
+	for i := range first {
+		profile += i - first[i] + 1
+	}
+	return profile
+}
+
+// levelStructure - breadth-first search from the root node, the neighbors are visited in ascending order of degree
+func levelStructure(adjacency [][]int, root int, visited []bool) [][]int {
+	mark := make([]bool, len(adjacency))
+	mark[root] = true
+	levels := [][]int{{root}}
+	for {
+		var next []int
+		for _, i := range levels[len(levels)-1] {
+			begin := len(next)
+			for _, j := range adjacency[i] {
+				if !visited[j] && !mark[j] {
+					mark[j] = true
+					next = append(next, j)
+				}
+			}
+			children := next[begin:]
+			sort.SliceStable(children, func(a, b int) bool { return len(adjacency[children[a]]) < len(adjacency[children[b]]) })
+		}
+		if len(next) == 0 {
+			return levels
+		}
+		levels = append(levels, next)
+	}
+}
+
+// peripheralNode - pseudo-peripheral node of the connected component (George and Liu)
+func peripheralNode(adjacency [][]int, root int, visited []bool) int {
+	levels := levelStructure(adjacency, root, visited)
+	for {
+		// The node of minimum degree in the last level
+		last := levels[len(levels)-1]
+		candidate := last[0]
+		for _, i := range last {
+			if len(adjacency[i]) < len(adjacency[candidate]) {
+				candidate = i
+			}
+		}
+		newLevels := levelStructure(adjacency, candidate, visited)
+		if len(newLevels) <= len(levels) {
+			return root
+		}
+		root, levels = candidate, newLevels
+	}
+}
+
+// Renumber - Reverse Cuthill-McKee node renumbering for reducing the matrix profile.
+// The original numbering is restored by RestoreNumbering
+func (m *Mesh) Renumber() {
+	if m.NumVertex() == 0 {
+		return
+	}
+	adjacency := m.Adjacency()
+	visited := make([]bool, m.NumVertex())
+	order := make([]int, 0, m.NumVertex())
+	for len(order) < m.NumVertex() {
+		// The unvisited node of minimum degree is a start of the next connected component
+		root := -1
+		for i := range visited {
+			if !visited[i] && (root < 0 || len(adjacency[i]) < len(adjacency[root])) {
+				root = i
+			}
+		}
+		for _, level := range levelStructure(adjacency, peripheralNode(adjacency, root, visited), visited) {
+			for _, i := range level {
+				visited[i] = true
+			}
+			order = append(order, level...)
+		}
+	}
+	// Reverse ordering
+	permutation := make([]int, m.NumVertex())
+	for i, j := range order {
+		permutation[j] = m.NumVertex() - 1 - i
+	}
+	profile := m.Profile()
+	m.permute(permutation)
+	newProfile := m.Profile()
+	if newProfile >= profile {
+		// Renumbering does not make sense
+		m.permute(inverse(permutation))
+		return
+	}
+	fmt.Printf("Matrix profile (nodes): %d -> %d\n", profile, newProfile)
+	if m.permutation == nil {
+		m.permutation = permutation
+	} else {
+		for i := range m.permutation {
+			m.permutation[i] = permutation[m.permutation[i]]
+		}
+	}
+}
+
+// permute - moving the node i to the position permutation[i]
+func (m *Mesh) permute(permutation []int) {
+	x := make([][]float64, len(m.X))
+	for i := range m.X {
+		x[permutation[i]] = m.X[i]
+	}
+	m.X = x
+	for i := range m.FE {
+		for j := range m.FE[i] {
+			m.FE[i][j] = permutation[m.FE[i][j]]
+		}
+	}
+	if !m.IsShell() {
+		// The boundary elements of shells are the finite elements
+		for i := range m.BE {
+			for j := range m.BE[i] {
+				m.BE[i][j] = permutation[m.BE[i][j]]
+			}
+		}
+	}
+	m.permuteDiscrete(permutation)
+	m.permuteSets(permutation)
+	m.CreateMeshMap()
+}
+
+// RestoreNumbering - restoring the original node numbering of the mesh and
+// the node results (the columns of the matrices)
+func (m *Mesh) RestoreNumbering(results ...*mat.Dense) {
+	if m.permutation == nil {
+		return
+	}
+	back := inverse(m.permutation)
+	for _, res := range results {
+		rows, cols := res.Dims()
+		tmp := mat.DenseCopyOf(res)
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				res.Set(i, back[j], tmp.At(i, j))
+			}
+		}
+	}
+	m.permute(back)
+	m.permutation = nil
+}
+
+// PermuteNodeValues - the node values given in the original numbering are arranged in the current one
+func (m *Mesh) PermuteNodeValues(values []float64) []float64 {
+	if m.permutation == nil {
+		return values
+	}
+	res := make([]float64, len(values))
+	for i := range values {
+		res[m.permutation[i]] = values[i]
+	}
+	return res
+}
+
+func inverse(permutation []int) []int {
+	res := make([]int, len(permutation))
+	for i, j := range permutation {
+		res[j] = i
+	}
+	return res
+}
@@ -0,0 +1,126 @@
+package mesh
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// shuffledGrid - the grid of nx x ny quadrangles (nz > 0 - of nx x ny x nz hexahedra) of the unit size with the
+// randomly numbered nodes, the boundary elements are the sides y = 0 and the set "left" is the nodes x = 0
+func shuffledGrid(nx, ny, nz int, seed int64) *Mesh {
+	m := &Mesh{FeType: Fe2d4}
+	if nz > 0 {
+		m.FeType = Fe3d8
+	}
+	num := (nx + 1) * (ny + 1) * (nz + 1)
+	order := rand.New(rand.NewSource(seed)).Perm(num)
+	node := func(i, j, k int) int {
+		return order[(k*(ny+1)+j)*(nx+1)+i]
+	}
+	m.X = make([][]float64, num)
+	var left []int
+	for k := 0; k <= nz; k++ {
+		for j := 0; j <= ny; j++ {
+			for i := 0; i <= nx; i++ {
+				m.X[node(i, j, k)] = []float64{float64(i), float64(j)}
+				if nz > 0 {
+					m.X[node(i, j, k)] = append(m.X[node(i, j, k)], float64(k))
+				}
+				if i == 0 {
+					left = append(left, node(i, j, k))
+				}
+			}
+		}
+	}
+	for i := 0; i < nx; i++ {
+		if nz == 0 {
+			for j := 0; j < ny; j++ {
+				m.FE = append(m.FE, []int{node(i, j, 0), node(i+1, j, 0), node(i+1, j+1, 0), node(i, j+1, 0)})
+			}
+			m.BE = append(m.BE, []int{node(i, 0, 0), node(i+1, 0, 0)})
+			continue
+		}
+		for k := 0; k < nz; k++ {
+			for j := 0; j < ny; j++ {
+				m.FE = append(m.FE, []int{node(i, j, k), node(i+1, j, k), node(i+1, j+1, k), node(i, j+1, k),
+					node(i, j, k+1), node(i+1, j, k+1), node(i+1, j+1, k+1), node(i, j+1, k+1)})
+			}
+			m.BE = append(m.BE, []int{node(i, 0, k), node(i+1, 0, k), node(i+1, 0, k+1), node(i, 0, k+1)})
+		}
+	}
+	m.CreateMeshMap()
+	m.AddSet("left", left)
+	return m
+}
+
+func copyMesh(m *Mesh) *Mesh {
+	res := &Mesh{Sets: make(map[string]Set)}
+	for _, x := range m.X {
+		res.X = append(res.X, append([]float64(nil), x...))
+	}
+	for _, elm := range m.FE {
+		res.FE = append(res.FE, append([]int(nil), elm...))
+	}
+	for _, elm := range m.BE {
+		res.BE = append(res.BE, append([]int(nil), elm...))
+	}
+	for name, s := range m.Sets {
+		res.Sets[name] = Set{Nodes: append([]int(nil), s.Nodes...), FE: append([]int(nil), s.FE...),
+			BE: append([]int(nil), s.BE...)}
+	}
+	return res
+}
+
+func TestRenumberRestoreNumbering(t *testing.T) {
+	tests := []struct {
+		name       string
+		nx, ny, nz int
+	}{
+		{"quadrangles", 12, 5, 0},
+		{"hexahedra", 6, 3, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := shuffledGrid(test.nx, test.ny, test.nz, 1)
+			original := copyMesh(m)
+			profile := m.Profile()
+			m.Renumber()
+			if m.Profile() >= profile {
+				t.Fatalf("profile %d is not reduced (%d)", m.Profile(), profile)
+			}
+			// The coordinates of the nodes given in the original numbering follow them
+			x := make([]float64, len(original.X))
+			for i := range original.X {
+				x[i] = original.X[i][0]
+			}
+			for i, v := range m.PermuteNodeValues(x) {
+				if v != m.X[i][0] {
+					t.Fatalf("permuted value of the node %d is %g, want %g", i, v, m.X[i][0])
+				}
+			}
+			// The node results found in the new numbering are the coordinates of the nodes
+			dim := len(m.X[0])
+			res := mat.NewDense(dim, m.NumVertex(), nil)
+			for i := range m.X {
+				for k := 0; k < dim; k++ {
+					res.Set(k, i, m.X[i][k])
+				}
+			}
+			m.RestoreNumbering(res)
+			for i := range original.X {
+				for k := 0; k < dim; k++ {
+					if res.At(k, i) != original.X[i][k] {
+						t.Fatalf("result %d of the node %d is %g, want %g", k, i, res.At(k, i), original.X[i][k])
+					}
+				}
+			}
+			if !reflect.DeepEqual(m.X, original.X) || !reflect.DeepEqual(m.FE, original.FE) ||
+				!reflect.DeepEqual(m.BE, original.BE) || !reflect.DeepEqual(m.Sets, original.Sets) {
+				t.Fatal("the numbering of the mesh is not restored")
+			}
+		})
+	}
+}
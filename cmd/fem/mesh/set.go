@@ -0,0 +1,83 @@
+package mesh
+
+import "sort"
+
+// Set - named group of the mesh: its nodes and the indices of its finite and boundary elements
+type Set struct {
+	Nodes []int
+	FE    []int
+	BE    []int
+}
+
+// Kinds of the members of the sets
+const (
+	SetNode = iota
+	SetFE
+	SetBE
+)
+
+// SetNames - names of the sets in alphabetical order
+func (m *Mesh) SetNames() []string {
+	res := make([]string, 0, len(m.Sets))
+	for name := range m.Sets {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// InSet - whether the node, the finite element or the boundary element (kind) with the index belongs to the set
+func (m *Mesh) InSet(name string, kind, index int) bool {
+	set, ok := m.Sets[name]
+	if !ok {
+		return false
+	}
+	members := set.Nodes
+	switch kind {
+	case SetFE:
+		members = set.FE
+	case SetBE:
+		members = set.BE
+	}
+	i := sort.SearchInts(members, index)
+	return i < len(members) && members[i] == index
+}
+
+// AddSet - the set of the nodes and of the finite and boundary elements all nodes of which are among them
+func (m *Mesh) AddSet(name string, nodes []int) {
+	set := Set{Nodes: append([]int(nil), nodes...)}
+	sort.Ints(set.Nodes)
+	inSet := func(elm []int) bool {
+		for _, node := range elm {
+			if !m.InSet(name, SetNode, node) {
+				return false
+			}
+		}
+		return true
+	}
+	if m.Sets == nil {
+		m.Sets = make(map[string]Set)
+	}
+	m.Sets[name] = set
+	for i := range m.FE {
+		if inSet(m.FE[i]) {
+			set.FE = append(set.FE, i)
+		}
+	}
+	for i := range m.BE {
+		if inSet(m.BE[i]) {
+			set.BE = append(set.BE, i)
+		}
+	}
+	m.Sets[name] = set
+}
+
+// permuteSets - moving the nodes of the sets to the positions permutation[i]
+func (m *Mesh) permuteSets(permutation []int) {
+	for _, s := range m.Sets {
+		for i := range s.Nodes {
+			s.Nodes[i] = permutation[s.Nodes[i]]
+		}
+		sort.Ints(s.Nodes)
+	}
+}
@@ -0,0 +1,212 @@
+package mesh
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+)
+
+// VtkArray - data array of the VTU-file: Values[i] are the components of the i-th node (of the i-th finite element
+// if Cell is set), the arrays of nine components are the tensors
+type VtkArray struct {
+	Name   string
+	Cell   bool
+	Values [][]float64
+}
+
+// PvdDataSet - VTU-file of the PVD-collection with its time (the step, the frequency or the load factor) and part
+type PvdDataSet struct {
+	File string
+	Time float64
+	Part int
+}
+
+// vtkCell - VTK type of the cell of the finite element of the given type and the order of its nodes (nil - the same
+// as in the mesh)
+func vtkCell(feType int) (int, []int, error) {
+	switch feType {
+	case Fe1d2, Fe2d2t, Fe3d2t, Fe2d2b, Fe3d2b:
+		return 3, nil, nil
+	case Fe2d3, Fe3d3s:
+		return 5, nil, nil
+	case Fe2d4, Fe3d4s:
+		return 9, nil, nil
+	case Fe2d6:
+		return 22, nil, nil
+	case Fe3d4:
+		return 10, nil, nil
+	case Fe3d8:
+		return 12, nil, nil
+	case Fe3d6:
+		return 13, nil, nil
+	case Fe3d10:
+		// The mid-side nodes of the edges 2-3 and 1-3 are swapped
+		return 24, []int{0, 1, 2, 3, 4, 5, 6, 7, 9, 8}, nil
+	case Fe3d20:
+		// VTK lists the edges of the bottom face, of the top face and the vertical edges
+		return 25, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 11, 13, 9, 16, 18, 19, 17, 10, 12, 14, 15}, nil
+	case Fe3d27:
+		// and then the faces x = -1, x = 1, y = -1, y = 1, z = -1, z = 1
+		return 29, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 11, 13, 9, 16, 18, 19, 17, 10, 12, 14, 15, 22, 23, 21, 24, 20, 25,
+			26}, nil
+	}
+	return 0, nil, fmt.Errorf("unsupported type of the finite element for the VTU-file")
+}
+
+// vtkWriter - writer of the data arrays of the VTU-file in the ASCII or base64 encoded binary format
+type vtkWriter struct {
+	*bufio.Writer
+	binary bool
+}
+
+// array - the data array of the type Float64, Int64 or UInt8 with the values
+func (w *vtkWriter) array(vtkType, name string, components int, values []float64) {
+	format := "ascii"
+	if w.binary {
+		format = "binary"
+	}
+	fmt.Fprintf(w, "<DataArray type=\"%s\"", vtkType)
+	if len(name) > 0 {
+		fmt.Fprintf(w, " Name=\"%s\"", name)
+	}
+	fmt.Fprintf(w, " NumberOfComponents=\"%d\" format=\"%s\">\n", components, format)
+	if w.binary {
+		size := 8
+		if vtkType == "UInt8" {
+			size = 1
+		}
+		data := make([]byte, 0, size*len(values))
+		for _, value := range values {
+			switch vtkType {
+			case "Float64":
+				data = binary.LittleEndian.AppendUint64(data, math.Float64bits(value))
+			case "Int64":
+				data = binary.LittleEndian.AppendUint64(data, uint64(int64(value)))
+			default:
+				data = append(data, byte(value))
+			}
+		}
+		// The header (the number of bytes of the data) is encoded separately
+		header := binary.LittleEndian.AppendUint64(nil, uint64(len(data)))
+		fmt.Fprintf(w, "%s%s\n", base64.StdEncoding.EncodeToString(header), base64.StdEncoding.EncodeToString(data))
+	} else {
+		for i, value := range values {
+			if vtkType == "Float64" {
+				fmt.Fprint(w, strconv.FormatFloat(value, 'g', -1, 64))
+			} else {
+				fmt.Fprint(w, int64(value))
+			}
+			if (i+1)%components == 0 {
+				fmt.Fprintln(w)
+			} else {
+				fmt.Fprint(w, " ")
+			}
+		}
+	}
+	fmt.Fprintf(w, "</DataArray>\n")
+}
+
+// SaveVtu - writing the mesh (the finite elements) and the data arrays in the VTU-file of ParaView, the data are
+// ASCII or base64 encoded binary
+func (m *Mesh) SaveVtu(name string, isBinary bool, arrays ...VtkArray) error {
+	for i := range m.FE {
+		if _, _, err := vtkCell(m.ElementType(i)); err != nil {
+			return err
+		}
+	}
+	file, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("error creating VTU-file")
+	}
+	defer func() {
+		err = file.Close()
+	}()
+	w := vtkWriter{Writer: bufio.NewWriter(file), binary: isBinary}
+	fmt.Fprintf(w, "<?xml version=\"1.0\"?>\n")
+	fmt.Fprintf(w, "<VTKFile type=\"UnstructuredGrid\" version=\"1.0\" byte_order=\"LittleEndian\" header_type=\"UInt64\">\n")
+	fmt.Fprintf(w, "<UnstructuredGrid>\n<Piece NumberOfPoints=\"%d\" NumberOfCells=\"%d\">\n", m.NumVertex(), m.NumFE())
+	for _, cell := range []bool{false, true} {
+		section := "PointData"
+		if cell {
+			section = "CellData"
+		}
+		fmt.Fprintf(w, "<%s>\n", section)
+		for _, a := range arrays {
+			if a.Cell != cell {
+				continue
+			}
+			components := 1
+			if len(a.Values) > 0 {
+				components = len(a.Values[0])
+			}
+			values := make([]float64, 0, components*len(a.Values))
+			for i := range a.Values {
+				values = append(values, a.Values[i]...)
+			}
+			w.array("Float64", a.Name, components, values)
+		}
+		fmt.Fprintf(w, "</%s>\n", section)
+	}
+	// The points always have three coordinates
+	points := make([]float64, 0, 3*m.NumVertex())
+	for i := range m.X {
+		for j := 0; j < 3; j++ {
+			if j < len(m.X[i]) {
+				points = append(points, m.X[i][j])
+			} else {
+				points = append(points, 0.0)
+			}
+		}
+	}
+	fmt.Fprintf(w, "<Points>\n")
+	w.array("Float64", "", 3, points)
+	fmt.Fprintf(w, "</Points>\n")
+	var connectivity, offsets, types []float64
+	for i := range m.FE {
+		cellType, order, _ := vtkCell(m.ElementType(i))
+		for j := range m.FE[i] {
+			k := j
+			if order != nil {
+				k = order[j]
+			}
+			connectivity = append(connectivity, float64(m.FE[i][k]))
+		}
+		offsets = append(offsets, float64(len(connectivity)))
+		types = append(types, float64(cellType))
+	}
+	fmt.Fprintf(w, "<Cells>\n")
+	w.array("Int64", "connectivity", 1, connectivity)
+	w.array("Int64", "offsets", 1, offsets)
+	w.array("UInt8", "types", 1, types)
+	fmt.Fprintf(w, "</Cells>\n</Piece>\n</UnstructuredGrid>\n</VTKFile>\n")
+	if err = w.Flush(); err != nil {
+		return fmt.Errorf("error writing VTU-file")
+	}
+	return err
+}
+
+// SavePvd - writing the PVD-collection of the VTU-files of the steps or of the cases of the problem, the names of
+// the files are relative to the collection
+func SavePvd(name string, dataSets []PvdDataSet) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("error creating PVD-file")
+	}
+	defer func() {
+		err = file.Close()
+	}()
+	w := bufio.NewWriter(file)
+	fmt.Fprintf(w, "<?xml version=\"1.0\"?>\n<VTKFile type=\"Collection\" version=\"1.0\">\n<Collection>\n")
+	for _, d := range dataSets {
+		fmt.Fprintf(w, "<DataSet timestep=\"%s\" part=\"%d\" file=\"%s\"/>\n", strconv.FormatFloat(d.Time, 'g', -1, 64), d.Part, d.File)
+	}
+	fmt.Fprintf(w, "</Collection>\n</VTKFile>\n")
+	if err = w.Flush(); err != nil {
+		return fmt.Errorf("error writing PVD-file")
+	}
+	return err
+}
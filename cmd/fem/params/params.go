@@ -1,6 +1,8 @@
 package params
 
 import (
+	"strings"
+
 	"gonum.org/v1/gonum/mat"
 	"wfem/cmd/parser"
 )
@@ -10,6 +12,10 @@ const (
 	X = 1
 	Y = 2
 	Z = 4
+	// Rotations about the axes (frames and shells)
+	RX = 8
+	RY = 16
+	RZ = 32
 )
 
 // Type of parameters
@@ -22,15 +28,65 @@ const (
 	Thickness
 	YoungModulus
 	PoissonRatio
+	Density
+	InitialDisplacement
+	InitialVelocity
+	Conductivity
+	HeatSource
+	HeatFlux
+	Convection
+	AmbientTemperature
+	Temperature
+	ThermalExpansion
+	Area
+	InertiaY
+	InertiaZ
+	TorsionConstant
+	ShearCoefficient
+	Orientation
+	// Orthotropic material: the Young's moduli, the shear moduli and the Poisson's ratios in the material axes
+	YoungModulus1
+	YoungModulus2
+	YoungModulus3
+	ShearModulus12
+	ShearModulus13
+	ShearModulus23
+	PoissonRatio12
+	PoissonRatio13
+	PoissonRatio23
+	MaterialAxis
+	MaterialPlane
+	// Component of the anisotropic elastic matrix, Direct is its index 6 * row + column
+	ElasticConstant
 )
 
+// Parameter - value of the given type applied where the predicate is true. Set - name of the node, element or
+// boundary set of the mesh the parameter is restricted to (empty - the whole mesh)
 type Parameter struct {
 	Type      int
 	Value     string
 	Predicate string
+	Set       string
 	Direct    int
 }
 
+// SplitSet - the set name and the predicate of the selection "@name" (the whole set), "@name: predicate" (the part
+// of the set where the predicate is true) or "predicate" (no set)
+func SplitSet(selection string) (string, string) {
+	selection = strings.TrimSpace(selection)
+	if !strings.HasPrefix(selection, "@") {
+		return "", selection
+	}
+	name, predicate, _ := strings.Cut(selection[1:], ":")
+	return strings.TrimSpace(name), strings.TrimSpace(predicate)
+}
+
+// add - the predicate of the parameter may reference a set of the mesh
+func (p *FEMParameters) add(prm Parameter) {
+	prm.Set, prm.Predicate = SplitSet(prm.Predicate)
+	p.Params = append(p.Params, prm)
+}
+
 func (p Parameter) GetValue(x *mat.VecDense, variables *map[string]float64) (float64, error) {
 	var value float64
 	var err error
@@ -105,40 +161,169 @@ func (p *FEMParameters) AddVariable(name string, value float64) {
 }
 
 func (p *FEMParameters) AddYoungModulus(value, predicate string) {
-	p.Params = append(p.Params, Parameter{Type: YoungModulus, Value: value, Predicate: predicate})
+	p.add(Parameter{Type: YoungModulus, Value: value, Predicate: predicate})
 }
 
 func (p *FEMParameters) AddPoissonRatio(value, predicate string) {
-	p.Params = append(p.Params, Parameter{Type: PoissonRatio, Value: value, Predicate: predicate})
+	p.add(Parameter{Type: PoissonRatio, Value: value, Predicate: predicate})
+}
+
+func (p *FEMParameters) AddDensity(value, predicate string) {
+	p.add(Parameter{Type: Density, Value: value, Predicate: predicate})
 }
 
 func (p *FEMParameters) AddThickness(value, predicate string) {
-	p.Params = append(p.Params, Parameter{Type: Thickness, Value: value, Predicate: predicate})
+	p.add(Parameter{Type: Thickness, Value: value, Predicate: predicate})
 }
 
 func (p *FEMParameters) AddPressureLoad(value, predicate string) {
-	p.Params = append(p.Params, Parameter{Type: PressureLoad, Value: value, Predicate: predicate, Direct: X | Y | Z})
+	p.add(Parameter{Type: PressureLoad, Value: value, Predicate: predicate, Direct: X | Y | Z})
 }
 
 func (p *FEMParameters) AddConcentratedLoad(value, predicate string, direct int) {
-	p.Params = append(p.Params, Parameter{Type: PointLoad, Value: value, Predicate: predicate, Direct: direct})
+	p.add(Parameter{Type: PointLoad, Value: value, Predicate: predicate, Direct: direct})
 }
 
 func (p *FEMParameters) AddSurfaceLoad(value, predicate string, direct int) {
-	p.Params = append(p.Params, Parameter{Type: SurfaceLoad, Value: value, Predicate: predicate, Direct: direct})
+	p.add(Parameter{Type: SurfaceLoad, Value: value, Predicate: predicate, Direct: direct})
 }
 
 func (p *FEMParameters) AddVolumeLoad(value, predicate string, direct int) {
-	p.Params = append(p.Params, Parameter{Type: VolumeLoad, Value: value, Predicate: predicate, Direct: direct})
+	p.add(Parameter{Type: VolumeLoad, Value: value, Predicate: predicate, Direct: direct})
 }
 
 func (p *FEMParameters) AddBoundaryCondition(value, predicate string, direct int) {
-	p.Params = append(p.Params, Parameter{Type: BoundaryCondition, Value: value, Predicate: predicate, Direct: direct})
+	p.add(Parameter{Type: BoundaryCondition, Value: value, Predicate: predicate, Direct: direct})
+}
+
+func (p *FEMParameters) AddInitialDisplacement(value, predicate string, direct int) {
+	p.add(Parameter{Type: InitialDisplacement, Value: value, Predicate: predicate, Direct: direct})
+}
+
+func (p *FEMParameters) AddInitialVelocity(value, predicate string, direct int) {
+	p.add(Parameter{Type: InitialVelocity, Value: value, Predicate: predicate, Direct: direct})
+}
+
+func (p *FEMParameters) AddConductivity(value, predicate string) {
+	p.add(Parameter{Type: Conductivity, Value: value, Predicate: predicate})
+}
+
+func (p *FEMParameters) AddHeatSource(value, predicate string) {
+	p.add(Parameter{Type: HeatSource, Value: value, Predicate: predicate, Direct: X})
+}
+
+func (p *FEMParameters) AddHeatFlux(value, predicate string) {
+	p.add(Parameter{Type: HeatFlux, Value: value, Predicate: predicate, Direct: X})
+}
+
+// AddConvection - the heat transfer coefficient and the ambient temperature on the boundary
+func (p *FEMParameters) AddConvection(coefficient, temperature, predicate string) {
+	p.add(Parameter{Type: Convection, Value: coefficient, Predicate: predicate, Direct: X})
+	p.add(Parameter{Type: AmbientTemperature, Value: temperature, Predicate: predicate, Direct: X})
+}
+
+// AddTemperature - temperature of the nodes relative to the stress-free state
+func (p *FEMParameters) AddTemperature(value, predicate string) {
+	p.add(Parameter{Type: Temperature, Value: value, Predicate: predicate})
+}
+
+func (p *FEMParameters) AddThermalExpansion(value, predicate string) {
+	p.add(Parameter{Type: ThermalExpansion, Value: value, Predicate: predicate})
+}
+
+// AddArea - cross-sectional area of the truss and frame bars
+func (p *FEMParameters) AddArea(value, predicate string) {
+	p.add(Parameter{Type: Area, Value: value, Predicate: predicate})
+}
+
+// AddInertia - moment of inertia of the bar section about the local y or z (direct - Y or Z) axis
+func (p *FEMParameters) AddInertia(value, predicate string, direct int) {
+	if direct&Y == Y {
+		p.add(Parameter{Type: InertiaY, Value: value, Predicate: predicate})
+	}
+	if direct&Z == Z {
+		p.add(Parameter{Type: InertiaZ, Value: value, Predicate: predicate})
+	}
+}
+
+func (p *FEMParameters) AddTorsionConstant(value, predicate string) {
+	p.add(Parameter{Type: TorsionConstant, Value: value, Predicate: predicate})
+}
+
+// AddShearCoefficient - shear correction factor of the Timoshenko beam
+func (p *FEMParameters) AddShearCoefficient(value, predicate string) {
+	p.add(Parameter{Type: ShearCoefficient, Value: value, Predicate: predicate})
+}
+
+// AddOrientation - components (direct) of the vector lying in the local x-y plane of the frame bar
+func (p *FEMParameters) AddOrientation(value, predicate string, direct int) {
+	p.add(Parameter{Type: Orientation, Value: value, Predicate: predicate, Direct: direct})
+}
+
+// AddOrthotropicYoungModulus - Young's modulus of the orthotropic material along the material axis 1, 2 or 3
+// (direct - X, Y or Z)
+func (p *FEMParameters) AddOrthotropicYoungModulus(value, predicate string, direct int) {
+	for i, pType := range []int{YoungModulus1, YoungModulus2, YoungModulus3} {
+		if direct&(1<<i) != 0 {
+			p.add(Parameter{Type: pType, Value: value, Predicate: predicate})
+		}
+	}
+}
+
+// materialPlanes - parameter types of the material planes 1-2, 1-3 and 2-3 given by the pairs of the directions
+func materialPlanes(direct int, types [3]int) []int {
+	var res []int
+	for i, plane := range [3]int{X | Y, X | Z, Y | Z} {
+		if direct&plane == plane {
+			res = append(res, types[i])
+		}
+	}
+	return res
+}
+
+// AddShearModulus - shear modulus of the orthotropic material in the material plane 1-2, 1-3 or 2-3
+// (direct - X | Y, X | Z or Y | Z)
+func (p *FEMParameters) AddShearModulus(value, predicate string, direct int) {
+	for _, pType := range materialPlanes(direct, [3]int{ShearModulus12, ShearModulus13, ShearModulus23}) {
+		p.add(Parameter{Type: pType, Value: value, Predicate: predicate})
+	}
+}
+
+// AddOrthotropicPoissonRatio - Poisson's ratio nu12, nu13 or nu23 of the orthotropic material
+// (direct - X | Y, X | Z or Y | Z)
+func (p *FEMParameters) AddOrthotropicPoissonRatio(value, predicate string, direct int) {
+	for _, pType := range materialPlanes(direct, [3]int{PoissonRatio12, PoissonRatio13, PoissonRatio23}) {
+		p.add(Parameter{Type: pType, Value: value, Predicate: predicate})
+	}
+}
+
+// AddMaterialAxis - components (direct) of the vector along the material axis 1
+func (p *FEMParameters) AddMaterialAxis(value, predicate string, direct int) {
+	p.add(Parameter{Type: MaterialAxis, Value: value, Predicate: predicate, Direct: direct})
+}
+
+// AddMaterialPlane - components (direct) of the vector lying in the material 1-2 plane
+func (p *FEMParameters) AddMaterialPlane(value, predicate string, direct int) {
+	p.add(Parameter{Type: MaterialPlane, Value: value, Predicate: predicate, Direct: direct})
+}
+
+// AddElasticConstant - component (row, column) of the symmetric 6x6 elastic matrix of the anisotropic material
+func (p *FEMParameters) AddElasticConstant(value, predicate string, row, column int) {
+	p.add(Parameter{Type: ElasticConstant, Value: value, Predicate: predicate, Direct: 6*row + column})
 }
 
 func (p *FEMParameters) GetParamValue(x *mat.VecDense, pType int) (float64, error) {
+	return p.GetSetParamValue(x, pType, nil)
+}
+
+// GetSetParamValue - value of the first parameter of the type suitable for the point x, inSet tells whether the
+// point belongs to the set with the given name (nil - the parameters restricted to the sets are skipped)
+func (p *FEMParameters) GetSetParamValue(x *mat.VecDense, pType int, inSet func(string) bool) (float64, error) {
 	for i := range p.Params {
 		if p.Params[i].Type == pType {
+			if len(p.Params[i].Set) > 0 && (inSet == nil || !inSet(p.Params[i].Set)) {
+				continue
+			}
 			isOk, err := p.Params[i].GetPredicate(x, &p.Variables)
 			if err != nil {
 				return 0, err
@@ -0,0 +1,48 @@
+package solver
+
+// Methods of applying the boundary conditions
+const (
+	Elimination int = iota
+	Penalty
+)
+
+const penalty = 1.0e+12
+
+// setBoundaryCondition - applying the prescribed value of the unknown to the system of equations.
+// The elimination method zeroes the row and the column of the unknown and moves the column
+// contribution to the right-hand side; coupled - the equations that can contain the unknown.
+// The penalty method multiplies the diagonal element by a large number. The unknown without the stiffness
+// (e.g. a bar node displacement across all its bars) gets the unit diagonal element
+func setBoundaryCondition(s Solver, method int, index int, value float64, coupled []int) {
+	if s.GetMatrix(index, index) == 0.0 {
+		s.SetMatrix(index, index, 1.0)
+	}
+	if method == Penalty {
+		diag := s.GetMatrix(index, index) * penalty
+		s.SetMatrix(index, index, diag)
+		s.SetVector(index, diag*value)
+		return
+	}
+	for _, i := range coupled {
+		if i == index {
+			continue
+		}
+		if a := s.GetMatrix(i, index); a != 0.0 {
+			s.AddVector(i, -a*value)
+			s.SetMatrix(i, index, 0.0)
+			s.SetMatrix(index, i, 0.0)
+		}
+	}
+	s.SetVector(index, value*s.GetMatrix(index, index))
+}
+
+// coupled - the unknowns of the nodes adjacent to the node of the unknown index
+func coupled(adjacency [][]int, freedom, index int) []int {
+	res := make([]int, 0, len(adjacency[index/freedom])*freedom)
+	for _, j := range adjacency[index/freedom] {
+		for k := 0; k < freedom; k++ {
+			res = append(res, j*freedom+k)
+		}
+	}
+	return res
+}
@@ -0,0 +1,77 @@
+package solver
+
+import (
+	"math"
+	"testing"
+	"wfem/cmd/fem/mesh"
+)
+
+// springChain - three springs of the stiffness k between four nodes, the point loads f1 and f2 of the inner
+// nodes, the displacements u0 and u3 of the end nodes are prescribed
+func springChain(s Solver, k, f1, f2, u0, u3 float64) {
+	for i := 0; i < 3; i++ {
+		s.AddMatrix(i, i, k)
+		s.AddMatrix(i+1, i+1, k)
+		s.AddMatrix(i, i+1, -k)
+		s.AddMatrix(i+1, i, -k)
+	}
+	s.AddVector(1, f1)
+	s.AddVector(2, f2)
+	s.SetBoundaryCondition(0, u0)
+	s.SetBoundaryCondition(3, u3)
+}
+
+func TestPrescribedDisplacements(t *testing.T) {
+	m := &mesh.Mesh{FeType: mesh.Fe1d2, X: [][]float64{{0}, {1}, {2}, {3}}, FE: [][]int{{0, 1}, {1, 2}, {2, 3}}}
+	m.CreateMeshMap()
+	const k, f1, f2, u0, u3 = 2.0, 1.0, -3.0, 0.5, 2.0
+	// The columns of the prescribed unknowns move to the right-hand side: b1 = f1 + k u0, b2 = f2 + k u3, the
+	// inner displacements are the solution of [2k -k; -k 2k] u = b
+	b1, b2 := f1+k*u0, f2+k*u3
+	u1, u2 := (2.0*k*b1+k*b2)/(3.0*k*k), (k*b1+2.0*k*b2)/(3.0*k*k)
+	tests := []struct {
+		name   string
+		solver string
+		method int
+	}{
+		{"sparse elimination", "sparse", Elimination},
+		{"sparse penalty", "sparse", Penalty},
+		{"dense elimination", "dense", Elimination},
+		{"dense penalty", "dense", Penalty},
+		{"pcg elimination", "pcg", Elimination},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := New(test.solver, m, WithEps(1.0e-14), WithBoundaryMethod(test.method))
+			if err != nil {
+				t.Fatal(err)
+			}
+			springChain(s, k, f1, f2, u0, u3)
+			if test.method == Elimination {
+				if s.GetVector(1) != b1 || s.GetVector(2) != b2 {
+					t.Fatalf("right-hand side is %g, %g, want %g, %g", s.GetVector(1), s.GetVector(2), b1, b2)
+				}
+				if s.GetMatrix(1, 0) != 0 || s.GetMatrix(2, 3) != 0 {
+					t.Fatal("the columns of the prescribed unknowns are not eliminated")
+				}
+			}
+			x, err := s.Solve()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, want := range []float64{u0, u1, u2, u3} {
+				if math.Abs(x.AtVec(i)-want) > 1.0e-9 {
+					t.Fatalf("displacement %d is %g, want %g", i, x.AtVec(i), want)
+				}
+			}
+		})
+	}
+}
+
+func TestPCGRejectsPenalty(t *testing.T) {
+	m := &mesh.Mesh{FeType: mesh.Fe1d2, X: [][]float64{{0}, {1}}, FE: [][]int{{0, 1}}}
+	m.CreateMeshMap()
+	if _, err := New("pcg", m, WithBoundaryMethod(Penalty)); err == nil {
+		t.Fatal("no error for the penalty method")
+	}
+}
@@ -7,13 +7,25 @@ import (
 	"wfem/cmd/fem/progress"
 )
 
+func init() {
+	Register("dense", func(mesh *mesh.Mesh, options Options) (Solver, error) {
+		ds := NewDenseSolver(mesh, options.Freedom)
+		ds.boundaryMethod = options.BoundaryMethod
+		return ds, nil
+	})
+}
+
 type DenseSolver struct {
-	matrix mat.SymDense
-	vector mat.VecDense
+	matrix         mat.SymDense
+	vector         mat.VecDense
+	freedom        int
+	adjacency      [][]int
+	boundaryMethod int
 }
 
-func NewDenseSolver(mesh *mesh.Mesh) *DenseSolver {
-	return &DenseSolver{matrix: *mat.NewSymDense(mesh.NumVertex()*mesh.Freedom(), nil), vector: *mat.NewVecDense(mesh.NumVertex()*mesh.Freedom(), nil)}
+func NewDenseSolver(mesh *mesh.Mesh, freedom int) *DenseSolver {
+	return &DenseSolver{matrix: *mat.NewSymDense(mesh.NumVertex()*freedom, nil), vector: *mat.NewVecDense(mesh.NumVertex()*freedom, nil),
+		freedom: freedom, adjacency: mesh.Adjacency()}
 }
 
 func (ds *DenseSolver) SetMatrix(i, j int, value float64) {
@@ -45,16 +57,7 @@ func (ds *DenseSolver) GetVector(i int) float64 {
 }
 
 func (ds *DenseSolver) SetBoundaryCondition(index int, value float64) {
-	rows, _ := ds.matrix.Dims()
-	for i := 0; i < rows; i++ {
-		if i != index {
-			if ds.matrix.At(index, i) != 0.0 {
-				ds.matrix.SetSym(index, i, value)
-				ds.matrix.SetSym(i, index, value)
-			}
-		}
-	}
-	ds.vector.SetVec(index, value*ds.matrix.At(index, index))
+	setBoundaryCondition(ds, ds.boundaryMethod, index, value, coupled(ds.adjacency, ds.freedom, index))
 }
 
 //func printMatrix(m *mat.SymDense, v *mat.VecDense) {
@@ -1,3 +1,5 @@
+//go:build eigen
+
 #include <Eigen/Sparse>
 #ifdef __linux__
     #include <Eigen/PardisoSupport>
@@ -53,19 +55,6 @@ double GetVector(int i)
     return vec(i);
 }
 
-void SetBoundaryCondition(int index, double value)
-{
-    for (Eigen::SparseMatrix<double>::InnerIterator i(mat, index); i; ++i)
-    {
-        if (i.row() not_eq i.col())
-        {
-            mat.coeffRef(i.row(), i.col()) = value;
-            mat.coeffRef(i.col(), i.row()) = value;
-        }
-    }
-    vec(index) = value * mat.coeffRef(index, index);
-}
-
 int SolveEigen(double *res)
 {
 #ifdef __linux__
@@ -1,3 +1,5 @@
+//go:build eigen
+
 package solver
 
 /*
@@ -17,19 +19,30 @@ import (
 	"gonum.org/v1/gonum/mat"
 )
 
+func init() {
+	Register("eigen", func(mesh *mesh.Mesh, options Options) (Solver, error) {
+		es := NewEigenSolver(mesh, options.Freedom)
+		es.boundaryMethod = options.BoundaryMethod
+		return es, nil
+	})
+}
+
 type EigenSolver struct {
-	size int
+	size           int
+	freedom        int
+	adjacency      [][]int
+	boundaryMethod int
 }
 
-func NewEigenSolver(mesh *mesh.Mesh) *EigenSolver {
+func NewEigenSolver(mesh *mesh.Mesh, freedom int) *EigenSolver {
 	maxNonZero := 0
 	for i := range mesh.MeshMap {
 		if len(mesh.MeshMap[i]) > maxNonZero {
 			maxNonZero = len(mesh.MeshMap[i])
 		}
 	}
-	C.InitMatrix((C.int)(mesh.NumVertex()*mesh.Freedom()), (C.int)(2*maxNonZero*mesh.Freedom()))
-	return &EigenSolver{size: mesh.NumVertex() * mesh.Freedom()}
+	C.InitMatrix((C.int)(mesh.NumVertex()*freedom), (C.int)(2*maxNonZero*freedom))
+	return &EigenSolver{size: mesh.NumVertex() * freedom, freedom: freedom, adjacency: mesh.Adjacency()}
 }
 
 func (_ *EigenSolver) SetMatrix(i, j int, value float64) {
@@ -57,7 +70,7 @@ func (es *EigenSolver) GetVector(i int) float64 {
 }
 
 func (es *EigenSolver) SetBoundaryCondition(i int, value float64) {
-	C.SetBoundaryCondition((C.int)(i), (C.double)(value))
+	setBoundaryCondition(es, es.boundaryMethod, i, value, coupled(es.adjacency, es.freedom, i))
 }
 
 func (es *EigenSolver) Solve() (*mat.VecDense, error) {
@@ -8,7 +8,6 @@ extern "C" {
 #endif
 
 void InitMatrix(int, int);
-void SetBoundaryCondition(int, double);
 void SetMatrix(int, int, double);
 void AddMatrix(int, int, double);
 void SetVector(int, double);
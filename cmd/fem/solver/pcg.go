@@ -0,0 +1,264 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"wfem/cmd/fem/mesh"
+	"wfem/cmd/fem/progress"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Preconditioners
+const (
+	Jacobi int = iota
+	IncompleteCholesky
+)
+
+func init() {
+	Register("pcg", func(mesh *mesh.Mesh, options Options) (Solver, error) {
+		if options.BoundaryMethod == Penalty {
+			// The convergence criterion is distorted by the penalty equations
+			return nil, fmt.Errorf("the penalty method is not supported by the pcg solver")
+		}
+		return NewPCGSolver(mesh, options.Freedom, options.Eps, options.Preconditioner), nil
+	})
+}
+
+// PCGSolver - preconditioned conjugate gradient method, the matrix is stored in the CSR format
+type PCGSolver struct {
+	rows           []int
+	cols           []int
+	matrix         []float64
+	vector         []float64
+	eps            float64
+	maxIterations  int
+	preconditioner int
+	iterations     int
+	residual       float64
+	freedom        int
+	adjacency      [][]int
+	// Incomplete Cholesky factor (lower triangle in the CSR format)
+	lRows []int
+	lCols []int
+	l     []float64
+	diag  []float64
+}
+
+func NewPCGSolver(mesh *mesh.Mesh, freedom int, eps float64, preconditioner int) *PCGSolver {
+	size := mesh.NumVertex() * freedom
+	adjacency := mesh.Adjacency()
+	ps := &PCGSolver{rows: make([]int, size+1), vector: make([]float64, size), eps: eps, maxIterations: size,
+		preconditioner: preconditioner, freedom: freedom, adjacency: adjacency}
+	for i := range adjacency {
+		for k := 0; k < freedom; k++ {
+			ps.rows[i*freedom+k+1] = ps.rows[i*freedom+k] + len(adjacency[i])*freedom
+		}
+	}
+	ps.cols = make([]int, ps.rows[size])
+	ps.matrix = make([]float64, ps.rows[size])
+	for i := range adjacency {
+		for k := 0; k < freedom; k++ {
+			pos := ps.rows[i*freedom+k]
+			for _, j := range adjacency[i] {
+				for l := 0; l < freedom; l++ {
+					ps.cols[pos] = j*freedom + l
+					pos++
+				}
+			}
+		}
+	}
+	return ps
+}
+
+// Iterations - number of iterations made by the last solution
+func (ps *PCGSolver) Iterations() int {
+	return ps.iterations
+}
+
+// Residual - relative residual of the last solution
+func (ps *PCGSolver) Residual() float64 {
+	return ps.residual
+}
+
+func (ps *PCGSolver) position(i, j int) int {
+	cols := ps.cols[ps.rows[i]:ps.rows[i+1]]
+	if k := sort.SearchInts(cols, j); k < len(cols) && cols[k] == j {
+		return ps.rows[i] + k
+	}
+	return -1
+}
+
+func (ps *PCGSolver) SetMatrix(i, j int, value float64) {
+	if k := ps.position(i, j); k >= 0 {
+		ps.matrix[k] = value
+	}
+}
+
+func (ps *PCGSolver) AddMatrix(i, j int, value float64) {
+	if k := ps.position(i, j); k >= 0 {
+		ps.matrix[k] += value
+	}
+}
+
+func (ps *PCGSolver) SetVector(i int, value float64) {
+	ps.vector[i] = value
+}
+
+func (ps *PCGSolver) AddVector(i int, value float64) {
+	ps.vector[i] += value
+}
+
+func (ps *PCGSolver) GetMatrix(i, j int) float64 {
+	if k := ps.position(i, j); k >= 0 {
+		return ps.matrix[k]
+	}
+	return 0
+}
+
+func (ps *PCGSolver) GetVector(i int) float64 {
+	return ps.vector[i]
+}
+
+func (ps *PCGSolver) SetBoundaryCondition(index int, value float64) {
+	setBoundaryCondition(ps, Elimination, index, value, coupled(ps.adjacency, ps.freedom, index))
+}
+
+func (ps *PCGSolver) mulVec(x, y []float64) {
+	for i := range y {
+		y[i] = 0
+		for k := ps.rows[i]; k < ps.rows[i+1]; k++ {
+			y[i] += ps.matrix[k] * x[ps.cols[k]]
+		}
+	}
+}
+
+func (ps *PCGSolver) createPreconditioner() error {
+	ps.diag = make([]float64, len(ps.vector))
+	for i := range ps.diag {
+		ps.diag[i] = ps.GetMatrix(i, i)
+		if ps.diag[i] <= 0 {
+			return fmt.Errorf("a matrix is not positive definite")
+		}
+	}
+	if ps.preconditioner == IncompleteCholesky {
+		// In case of breakdown the diagonal is shifted (Manteuffel)
+		for shift := 0.0; shift < 1.0; shift = math.Max(2.0*shift, 1.0e-3) {
+			if ps.incompleteCholesky(shift) {
+				return nil
+			}
+		}
+		return fmt.Errorf("incomplete Cholesky factorization failed")
+	}
+	return nil
+}
+
+// incompleteCholesky - IC(0) factorization of the matrix (1 + shift) * D + (A - D)
+func (ps *PCGSolver) incompleteCholesky(shift float64) bool {
+	size := len(ps.vector)
+	ps.lRows = make([]int, size+1)
+	ps.lCols = ps.lCols[:0]
+	ps.l = ps.l[:0]
+	for i := 0; i < size; i++ {
+		for k := ps.rows[i]; k < ps.rows[i+1] && ps.cols[k] <= i; k++ {
+			ps.lCols = append(ps.lCols, ps.cols[k])
+			ps.l = append(ps.l, ps.matrix[k])
+		}
+		ps.lRows[i+1] = len(ps.l)
+	}
+	for i := 0; i < size; i++ {
+		ps.l[ps.lRows[i+1]-1] = (1.0 + shift) * ps.diag[i]
+		for k := ps.lRows[i]; k < ps.lRows[i+1]; k++ {
+			j := ps.lCols[k]
+			// Dot product of the rows i and j over the common columns less than j
+			s := 0.0
+			p, q := ps.lRows[i], ps.lRows[j]
+			for p < k && q < ps.lRows[j+1]-1 {
+				switch {
+				case ps.lCols[p] < ps.lCols[q]:
+					p++
+				case ps.lCols[p] > ps.lCols[q]:
+					q++
+				default:
+					s += ps.l[p] * ps.l[q]
+					p++
+					q++
+				}
+			}
+			if j < i {
+				ps.l[k] = (ps.l[k] - s) / ps.l[ps.lRows[j+1]-1]
+			} else {
+				if ps.l[k]-s <= 0 {
+					return false
+				}
+				ps.l[k] = math.Sqrt(ps.l[k] - s)
+			}
+		}
+	}
+	return true
+}
+
+func (ps *PCGSolver) precondition(r, z []float64) {
+	if ps.preconditioner != IncompleteCholesky {
+		for i := range z {
+			z[i] = r[i] / ps.diag[i]
+		}
+		return
+	}
+	copy(z, r)
+	for i := range z {
+		row := ps.lRows[i+1] - 1
+		for k := ps.lRows[i]; k < row; k++ {
+			z[i] -= ps.l[k] * z[ps.lCols[k]]
+		}
+		z[i] /= ps.l[row]
+	}
+	for i := len(z) - 1; i >= 0; i-- {
+		row := ps.lRows[i+1] - 1
+		z[i] /= ps.l[row]
+		for k := ps.lRows[i]; k < row; k++ {
+			z[ps.lCols[k]] -= ps.l[k] * z[i]
+		}
+	}
+}
+
+func (ps *PCGSolver) Solve() (*mat.VecDense, error) {
+	size := len(ps.vector)
+	if err := ps.createPreconditioner(); err != nil {
+		return nil, err
+	}
+	msg := progress.NewUnlimitedProgress("Solution of the system of equations")
+	x := make([]float64, size)
+	r := make([]float64, size)
+	z := make([]float64, size)
+	p := make([]float64, size)
+	q := make([]float64, size)
+	copy(r, ps.vector)
+	norm := floats.Norm(ps.vector, 2)
+	if norm == 0 {
+		msg.StopProgress()
+		return mat.NewVecDense(size, x), nil
+	}
+	ps.precondition(r, z)
+	copy(p, z)
+	rz := floats.Dot(r, z)
+	ps.residual = 1.0
+	for ps.iterations = 0; ps.iterations < ps.maxIterations && ps.residual > ps.eps; ps.iterations++ {
+		ps.mulVec(p, q)
+		alpha := rz / floats.Dot(p, q)
+		floats.AddScaled(x, alpha, p)
+		floats.AddScaled(r, -alpha, q)
+		ps.residual = floats.Norm(r, 2) / norm
+		ps.precondition(r, z)
+		rzNew := floats.Dot(r, z)
+		floats.AddScaledTo(p, z, rzNew/rz, p)
+		rz = rzNew
+	}
+	msg.StopProgress()
+	if ps.residual > ps.eps {
+		return nil, fmt.Errorf("the iterative process does not converge")
+	}
+	return mat.NewVecDense(size, x), nil
+}
@@ -0,0 +1,40 @@
+package solver
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPCGSolverMatchDense(t *testing.T) {
+	tests := []struct {
+		name           string
+		preconditioner int
+	}{
+		{"jacobi", Jacobi},
+		{"incomplete cholesky", IncompleteCholesky},
+	}
+	const eps = 1.0e-12
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := gridMesh(6, 4)
+			ps, dense := NewPCGSolver(m, m.Freedom(), eps, test.preconditioner), NewDenseSolver(m, m.Freedom())
+			assembleRandom(m, []int{0, 1, 7, 20}, ps, dense)
+			want, err := dense.Solve()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ps.Solve()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ps.Iterations() == 0 || ps.Residual() > eps {
+				t.Fatalf("%d iterations, residual %g", ps.Iterations(), ps.Residual())
+			}
+			for i := 0; i < want.Len(); i++ {
+				if math.Abs(want.AtVec(i)-got.AtVec(i)) > 1.0e-8*math.Max(1.0, math.Abs(want.AtVec(i))) {
+					t.Fatalf("unknown %d is %g, want %g", i, got.AtVec(i), want.AtVec(i))
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package solver
 
 import (
+	"fmt"
+	"sort"
+	"wfem/cmd/fem/mesh"
+
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -14,3 +18,87 @@ type Solver interface {
 	GetVector(int) float64
 	Solve() (*mat.VecDense, error)
 }
+
+// Options - settings passed to the solver constructor
+type Options struct {
+	Eps            float64
+	Preconditioner int
+	BoundaryMethod int
+	Freedom        int
+}
+
+type Option func(*Options)
+
+// WithEps - convergence tolerance of the iterative solvers
+func WithEps(eps float64) Option {
+	return func(o *Options) {
+		o.Eps = eps
+	}
+}
+
+// WithPreconditioner - preconditioner of the iterative solvers (Jacobi, IncompleteCholesky)
+func WithPreconditioner(preconditioner int) Option {
+	return func(o *Options) {
+		o.Preconditioner = preconditioner
+	}
+}
+
+// WithBoundaryMethod - method of applying the boundary conditions (Elimination, Penalty)
+func WithBoundaryMethod(method int) Option {
+	return func(o *Options) {
+		o.BoundaryMethod = method
+	}
+}
+
+// WithFreedom - number of the unknowns per node (by default it is defined by the finite element type of the mesh)
+func WithFreedom(freedom int) Option {
+	return func(o *Options) {
+		o.Freedom = freedom
+	}
+}
+
+var solvers = map[string]func(*mesh.Mesh, Options) (Solver, error){}
+
+var preconditioners = map[string]int{"jacobi": Jacobi, "ic": IncompleteCholesky}
+
+// Register - adding the solver constructor to the list of available solvers
+func Register(name string, create func(*mesh.Mesh, Options) (Solver, error)) {
+	solvers[name] = create
+}
+
+// Names - sorted names of the registered solvers
+func Names() []string {
+	names := make([]string, 0, len(solvers))
+	for name := range solvers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsRegistered - checking the solver name
+func IsRegistered(name string) bool {
+	_, ok := solvers[name]
+	return ok
+}
+
+// PreconditionerByName - preconditioner by its name ("jacobi" or "ic")
+func PreconditionerByName(name string) (int, error) {
+	if preconditioner, ok := preconditioners[name]; ok {
+		return preconditioner, nil
+	}
+	return 0, fmt.Errorf("unknown preconditioner: %s", name)
+}
+
+// New - creating the registered solver by its name
+func New(name string, mesh *mesh.Mesh, opts ...Option) (Solver, error) {
+	create, ok := solvers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown solver: %s", name)
+	}
+	options := Options{Eps: 1.0e-10, Preconditioner: Jacobi, BoundaryMethod: Elimination, Freedom: mesh.Freedom()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return create(mesh, options)
+}
@@ -0,0 +1,192 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"wfem/cmd/fem/mesh"
+	"wfem/cmd/fem/progress"
+
+	"golang.org/x/exp/slices"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+)
+
+func init() {
+	Register("sparse", func(mesh *mesh.Mesh, options Options) (Solver, error) {
+		ss := NewSparseSolver(mesh, options.Freedom)
+		ss.boundaryMethod = options.BoundaryMethod
+		return ss, nil
+	})
+}
+
+// SparseSolver - symmetric matrix in skyline (profile) storage with Cholesky factorization.
+// Only the lower triangle is stored: row i holds the columns first[i]..i
+type SparseSolver struct {
+	first          []int
+	index          []int
+	matrix         []float64
+	vector         []float64
+	freedom        int
+	adjacency      [][]int
+	boundaryMethod int
+}
+
+func NewSparseSolver(mesh *mesh.Mesh, freedom int) *SparseSolver {
+	size := mesh.NumVertex() * freedom
+	// The first node connected with the current one
+	firstNode := make([]int, mesh.NumVertex())
+	for i := range firstNode {
+		firstNode[i] = i
+	}
+	for i := range mesh.MeshMap {
+		for _, j := range mesh.MeshMap[i] {
+			if i < firstNode[j] {
+				firstNode[j] = i
+			}
+		}
+	}
+	ss := &SparseSolver{first: make([]int, size), index: make([]int, size+1), vector: make([]float64, size),
+		freedom: freedom, adjacency: mesh.Adjacency()}
+	for i := 0; i < size; i++ {
+		ss.first[i] = firstNode[i/freedom] * freedom
+		ss.index[i+1] = ss.index[i] + i - ss.first[i] + 1
+	}
+	ss.matrix = make([]float64, ss.index[size])
+	return ss
+}
+
+// Size - number of equations
+func (ss *SparseSolver) Size() int {
+	return len(ss.vector)
+}
+
+// position - index of the element (i, j), i >= j, in the profile or -1 if it is outside the profile
+func (ss *SparseSolver) position(i, j int) int {
+	if j < ss.first[i] {
+		return -1
+	}
+	return ss.index[i] + j - ss.first[i]
+}
+
+func (ss *SparseSolver) SetMatrix(i, j int, value float64) {
+	if i >= j {
+		if k := ss.position(i, j); k >= 0 {
+			ss.matrix[k] = value
+		}
+	}
+}
+
+func (ss *SparseSolver) AddMatrix(i, j int, value float64) {
+	if i >= j {
+		if k := ss.position(i, j); k >= 0 {
+			ss.matrix[k] += value
+		}
+	}
+}
+
+func (ss *SparseSolver) SetVector(i int, value float64) {
+	ss.vector[i] = value
+}
+
+func (ss *SparseSolver) AddVector(i int, value float64) {
+	ss.vector[i] += value
+}
+
+func (ss *SparseSolver) GetMatrix(i, j int) float64 {
+	if i < j {
+		i, j = j, i
+	}
+	if k := ss.position(i, j); k >= 0 {
+		return ss.matrix[k]
+	}
+	return 0
+}
+
+func (ss *SparseSolver) GetVector(i int) float64 {
+	return ss.vector[i]
+}
+
+func (ss *SparseSolver) SetBoundaryCondition(index int, value float64) {
+	setBoundaryCondition(ss, ss.boundaryMethod, index, value, coupled(ss.adjacency, ss.freedom, index))
+}
+
+// MulVec - product y = A x of the assembled (not factorized) matrix and the vector
+func (ss *SparseSolver) MulVec(x, y []float64) {
+	for i := range y {
+		y[i] = 0
+	}
+	for i := 0; i < ss.Size(); i++ {
+		row := ss.matrix[ss.index[i]:ss.index[i+1]]
+		y[i] += floats.Dot(row, x[ss.first[i]:i+1])
+		floats.AddScaled(y[ss.first[i]:i], x[i], row[:len(row)-1])
+	}
+}
+
+// AddScaled - adding the matrix of other multiplied by alpha, both solvers must be created for the same mesh
+func (ss *SparseSolver) AddScaled(alpha float64, other *SparseSolver) {
+	floats.AddScaled(ss.matrix, alpha, other.matrix)
+}
+
+// Factorize - Cholesky factorization of the matrix for the subsequent solutions by SolveVec
+func (ss *SparseSolver) Factorize() error {
+	return ss.factorize()
+}
+
+// SolveVec - solution of the system with the factorized matrix and the right-hand side b
+func (ss *SparseSolver) SolveVec(b []float64) []float64 {
+	return ss.substitute(b)
+}
+
+func (ss *SparseSolver) factorize() error {
+	msg := progress.NewProgress("Matrix factorization", 0, ss.Size(), 10)
+	for i := 0; i < ss.Size(); i++ {
+		msg.AddProgress()
+		row := ss.matrix[ss.index[i]:ss.index[i+1]]
+		for j := ss.first[i]; j < i; j++ {
+			begin := ss.first[i]
+			if ss.first[j] > begin {
+				begin = ss.first[j]
+			}
+			col := ss.matrix[ss.index[j]:ss.index[j+1]]
+			s := row[j-ss.first[i]] - floats.Dot(row[begin-ss.first[i]:j-ss.first[i]], col[begin-ss.first[j]:j-ss.first[j]])
+			row[j-ss.first[i]] = s / col[len(col)-1]
+		}
+		s := row[len(row)-1] - floats.Dot(row[:len(row)-1], row[:len(row)-1])
+		if s <= 0 {
+			return fmt.Errorf("a matrix is not positive definite")
+		}
+		row[len(row)-1] = math.Sqrt(s)
+	}
+	return nil
+}
+
+func (ss *SparseSolver) substitute(b []float64) []float64 {
+	x := slices.Clone(b)
+	// Forward substitution
+	for i := 0; i < ss.Size(); i++ {
+		row := ss.matrix[ss.index[i]:ss.index[i+1]]
+		x[i] = (x[i] - floats.Dot(row[:len(row)-1], x[ss.first[i]:i])) / row[len(row)-1]
+	}
+	// Back substitution
+	for i := ss.Size() - 1; i >= 0; i-- {
+		row := ss.matrix[ss.index[i]:ss.index[i+1]]
+		x[i] /= row[len(row)-1]
+		floats.AddScaled(x[ss.first[i]:i], -x[i], row[:len(row)-1])
+	}
+	return x
+}
+
+func (ss *SparseSolver) Solve() (*mat.VecDense, error) {
+	if err := ss.factorize(); err != nil {
+		return nil, err
+	}
+	msg := progress.NewUnlimitedProgress("Solution of the system of equations")
+	defer msg.StopProgress()
+	x := ss.substitute(ss.vector)
+	for i := range x {
+		if math.IsNaN(x[i]) || math.IsInf(x[i], 0) {
+			return nil, fmt.Errorf("matrix is near singular")
+		}
+	}
+	return mat.NewVecDense(ss.Size(), x), nil
+}
@@ -0,0 +1,115 @@
+package solver
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"wfem/cmd/fem/mesh"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// gridMesh - the rectangle divided into nx x ny quadrangles, the nodes are numbered row by row
+func gridMesh(nx, ny int) *mesh.Mesh {
+	m := &mesh.Mesh{FeType: mesh.Fe2d4}
+	for j := 0; j <= ny; j++ {
+		for i := 0; i <= nx; i++ {
+			m.X = append(m.X, []float64{float64(i), float64(j)})
+		}
+	}
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			k := j*(nx+1) + i
+			m.FE = append(m.FE, []int{k, k + 1, k + nx + 2, k + nx + 1})
+		}
+	}
+	m.CreateMeshMap()
+	return m
+}
+
+// assembleRandom - the same symmetric positive definite system with the sparsity of the mesh is built in all
+// solvers: the local matrices are B^T B + I with random B, the unknowns fixed are set to zero
+func assembleRandom(m *mesh.Mesh, fixed []int, solvers ...Solver) {
+	rnd := rand.New(rand.NewSource(1))
+	freedom := m.Freedom()
+	size := len(m.FE[0]) * freedom
+	for _, fe := range m.FE {
+		b := mat.NewDense(size, size, nil)
+		for i := 0; i < size; i++ {
+			for j := 0; j < size; j++ {
+				b.Set(i, j, rnd.Float64()-0.5)
+			}
+		}
+		var local mat.Dense
+		local.Mul(b.T(), b)
+		for i := 0; i < size; i++ {
+			local.Set(i, i, local.At(i, i)+1.0)
+		}
+		for i := 0; i < size; i++ {
+			for j := 0; j < size; j++ {
+				for _, s := range solvers {
+					s.AddMatrix(fe[i/freedom]*freedom+i%freedom, fe[j/freedom]*freedom+j%freedom, local.At(i, j))
+				}
+			}
+		}
+	}
+	for i := 0; i < m.NumVertex()*freedom; i++ {
+		value := rnd.Float64() - 0.5
+		for _, s := range solvers {
+			s.AddVector(i, value)
+		}
+	}
+	for _, index := range fixed {
+		for _, s := range solvers {
+			s.SetBoundaryCondition(index, 0)
+		}
+	}
+}
+
+func TestSparseSolverMatchDense(t *testing.T) {
+	tests := []struct {
+		name   string
+		nx, ny int
+		fixed  []int
+	}{
+		{"free", 4, 3, nil},
+		{"fixed", 6, 2, []int{0, 1, 2, 15, 16}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := gridMesh(test.nx, test.ny)
+			sparse, dense := NewSparseSolver(m, m.Freedom()), NewDenseSolver(m, m.Freedom())
+			assembleRandom(m, test.fixed, sparse, dense)
+			want, err := dense.Solve()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := sparse.Solve()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < want.Len(); i++ {
+				if math.Abs(want.AtVec(i)-got.AtVec(i)) > 1.0e-10*math.Max(1.0, math.Abs(want.AtVec(i))) {
+					t.Fatalf("unknown %d is %g, want %g", i, got.AtVec(i), want.AtVec(i))
+				}
+			}
+			for _, index := range test.fixed {
+				if got.AtVec(index) != 0 {
+					t.Fatalf("fixed unknown %d is %g", index, got.AtVec(index))
+				}
+			}
+		})
+	}
+}
+
+func TestSparseSolverNotPositiveDefinite(t *testing.T) {
+	m := gridMesh(2, 2)
+	ss := NewSparseSolver(m, m.Freedom())
+	for i := 0; i < ss.Size(); i++ {
+		ss.AddMatrix(i, i, 1.0)
+	}
+	ss.AddMatrix(3, 3, -2.0)
+	if _, err := ss.Solve(); err == nil {
+		t.Fatal("no error for the indefinite matrix")
+	}
+}
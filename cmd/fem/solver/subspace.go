@@ -0,0 +1,136 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"wfem/cmd/fem/progress"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Subspace - subspace iteration method for the num largest eigenvalues mu of the generalized problem B x = mu K x.
+// The matrix K is to be factorized (SparseSolver.Factorize), the matrix B is given by the product y = B x.
+// The eigenvalues are sorted in descending order, the eigenvectors (columns) are normalized so that x' K x = 1.
+// The number of the iterations made is returned as well
+func Subspace(k *SparseSolver, mulB func(x, y []float64), num int, eps float64, maxIterations int) ([]float64, *mat.Dense, int, error) {
+	size := k.Size()
+	if num > size {
+		num = size
+	}
+	if num <= 0 {
+		return nil, nil, 0, fmt.Errorf("wrong number of eigenvalues: %d", num)
+	}
+	q := 2 * num
+	if q > num+8 {
+		q = num + 8
+	}
+	if q > size {
+		q = size
+	}
+	// Starting vectors
+	rnd := rand.New(rand.NewSource(1))
+	x := mat.NewDense(size, q, nil)
+	for i := 0; i < size; i++ {
+		for j := 0; j < q; j++ {
+			x.Set(i, j, rnd.Float64()-0.5)
+		}
+	}
+	col := make([]float64, size)
+	tmp := make([]float64, size)
+	mu := make([]float64, q)
+	msg := progress.NewUnlimitedProgress("Subspace iteration")
+	for iteration := 1; iteration <= maxIterations; iteration++ {
+		y := mat.NewDense(size, q, nil)
+		bx := mat.NewDense(size, q, nil)
+		by := mat.NewDense(size, q, nil)
+		// Y = K^-1 B X
+		for j := 0; j < q; j++ {
+			mat.Col(col, j, x)
+			mulB(col, tmp)
+			bx.SetCol(j, tmp)
+			y.SetCol(j, k.SolveVec(tmp))
+			mat.Col(col, j, y)
+			mulB(col, tmp)
+			by.SetCol(j, tmp)
+		}
+		// Projection of the problem onto the subspace: Br z = mu Kr z, Kr = Y' K Y = Y' B X, Br = Y' B Y
+		var kr, br mat.Dense
+		kr.Mul(y.T(), bx)
+		br.Mul(y.T(), by)
+		// K-orthonormal basis of the subspace T = V D^-1/2 (Kr = V D V'). If the rank of B is less than
+		// the subspace dimension, the vectors become linearly dependent and the dependent ones are dropped
+		var kEigen mat.EigenSym
+		if !kEigen.Factorize(symmetric(&kr), true) {
+			msg.StopProgress()
+			return nil, nil, 0, fmt.Errorf("eigenvalue decomposition failed")
+		}
+		var v mat.Dense
+		kEigen.VectorsTo(&v)
+		kValues := kEigen.Values(nil)
+		var basis []int
+		for j := range kValues {
+			if kValues[j] > 1.0e-12*kValues[q-1] {
+				basis = append(basis, j)
+			}
+		}
+		if len(basis) == 0 {
+			msg.StopProgress()
+			return nil, nil, 0, fmt.Errorf("all eigenvalues are zero")
+		}
+		if len(basis) < q {
+			q = len(basis)
+			if num > q {
+				num = q
+			}
+		}
+		t := mat.NewDense(len(kValues), q, nil)
+		for j, k := range basis {
+			for i := range kValues {
+				t.Set(i, j, v.At(i, k)/math.Sqrt(kValues[k]))
+			}
+		}
+		// Standard problem C w = mu w, C = T' Br T, z = T w
+		var c mat.Dense
+		c.Product(t.T(), &br, t)
+		var eigen mat.EigenSym
+		if !eigen.Factorize(symmetric(&c), true) {
+			msg.StopProgress()
+			return nil, nil, 0, fmt.Errorf("eigenvalue decomposition failed")
+		}
+		var w, z mat.Dense
+		eigen.VectorsTo(&w)
+		z.Mul(t, &w)
+		var ritz mat.Dense
+		ritz.Mul(y, &z)
+		// Descending order of the eigenvalues
+		values := eigen.Values(nil)
+		x = mat.NewDense(size, q, nil)
+		isConverged := true
+		for j := 0; j < q; j++ {
+			value := values[q-1-j]
+			if j < num && math.Abs(value-mu[j]) > eps*math.Abs(value) {
+				isConverged = false
+			}
+			mu[j] = value
+			x.SetCol(j, mat.Col(col, q-1-j, &ritz))
+		}
+		if isConverged {
+			msg.StopProgress()
+			return mu[:num], mat.DenseCopyOf(x.Slice(0, size, 0, num)), iteration, nil
+		}
+	}
+	msg.StopProgress()
+	return nil, nil, 0, fmt.Errorf("the iterative process does not converge")
+}
+
+func symmetric(a *mat.Dense) *mat.SymDense {
+	n, _ := a.Dims()
+	res := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			res.SetSym(i, j, 0.5*(a.At(i, j)+a.At(j, i)))
+		}
+	}
+	return res
+}
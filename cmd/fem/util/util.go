@@ -152,6 +152,21 @@ func Volume3d8(x *mat.Dense) float64 {
 	return v
 }
 
+// Volume3d6 - volume of a wedge (triangular prism)
+func Volume3d6(x *mat.Dense) float64 {
+	ref := [3][4]int{{0, 1, 2, 3}, {1, 2, 3, 4}, {2, 3, 4, 5}}
+	v := 0.0
+	for i := 0; i < 3; i++ {
+		mt := mat.NewDense(3, 3, []float64{
+			x.At(ref[i][1], 0) - x.At(ref[i][0], 0), x.At(ref[i][1], 1) - x.At(ref[i][0], 1), x.At(ref[i][1], 2) - x.At(ref[i][0], 2),
+			x.At(ref[i][2], 0) - x.At(ref[i][0], 0), x.At(ref[i][2], 1) - x.At(ref[i][0], 1), x.At(ref[i][2], 2) - x.At(ref[i][0], 2),
+			x.At(ref[i][3], 0) - x.At(ref[i][0], 0), x.At(ref[i][3], 1) - x.At(ref[i][0], 1), x.At(ref[i][3], 2) - x.At(ref[i][0], 2),
+		})
+		v += math.Abs(mat.Det(mt)) / 6.0
+	}
+	return v
+}
+
 // TransformMatrix - create transformation matrix for shell finite element
 func TransformMatrix(x *mat.Dense) *mat.Dense {
 	m := mat.NewDense(3, 3, nil)
@@ -218,6 +233,13 @@ func Add(lhs, rhs mat.Matrix) *mat.Dense {
 	return res
 }
 
+func Sub(lhs, rhs mat.Matrix) *mat.Dense {
+	rows, cols := lhs.Dims()
+	res := mat.NewDense(rows, cols, nil)
+	res.Sub(lhs, rhs)
+	return res
+}
+
 func Scale(lhs float64, rhs mat.Matrix) *mat.Dense {
 	rows, cols := rhs.Dims()
 	res := mat.NewDense(rows, cols, nil)
@@ -10,6 +10,7 @@ import (
 	"runtime"
 	"strconv"
 	"wfem/cmd/fem/params"
+	"wfem/cmd/fem/solver"
 )
 
 type condition struct {
@@ -23,18 +24,20 @@ type result struct {
 }
 
 type report struct {
-	DateTime, FeName, Mesh                                                                          string
-	NumFE, NumVertex                                                                                int
-	Variables                                                                                       map[string]float64
-	YoungModulus, PoissonRatio, VolumeLoad, SurfaceLoad, PointLoad, PressureLoad, BoundaryCondition []condition
-	Res                                                                                             []result
+	DateTime, FeName, Mesh, MeshSets, Solver, Mode, Shell, Integration                                            string
+	NumFE, NumVertex                                                                                              int
+	Variables                                                                                                     map[string]float64
+	YoungModulus, PoissonRatio, VolumeLoad, SurfaceLoad, PointLoad, PressureLoad, BoundaryCondition, Spring, Sets []condition
+	Res                                                                                                           []result
 }
 
 type problemInfo struct {
-	Mesh                                                                                                                  []string
-	YoungModulus, PoissonRatio, Thickness, VolumeLoad, SurfaceLoad, PointLoad, PressureLoad, BoundaryCondition, Variables string
-	Threads                                                                                                               int
-	Eps                                                                                                                   float64
+	Mesh                                                                                                                                []string
+	YoungModulus, PoissonRatio, Thickness, VolumeLoad, SurfaceLoad, PointLoad, PressureLoad, BoundaryCondition, Spring, Variables, Sets string
+	Solver, Preconditioner, Mode, Shell, Integration                                                                                    string
+	Threads                                                                                                                             int
+	Eps                                                                                                                                 float64
+	Solvers                                                                                                                             []string `json:"-"`
 }
 
 var tmpl *template.Template
@@ -78,7 +81,8 @@ func newProblemPageHandler(writer http.ResponseWriter, request *http.Request) {
 	if err = request.ParseForm(); err != nil {
 		log.Fatal("500 Internal Server Error: ", err)
 	}
-	problem := problemInfo{Eps: 1.0e-10, Threads: runtime.NumCPU()}
+	problem := problemInfo{Eps: 1.0e-10, Threads: runtime.NumCPU(), Solver: "sparse", Preconditioner: "jacobi",
+		Mode: "plane_stress", Shell: "mindlin", Integration: "full"}
 
 	problemName := request.FormValue("problem")
 	if len(problemName) > 0 {
@@ -88,6 +92,7 @@ func newProblemPageHandler(writer http.ResponseWriter, request *http.Request) {
 	} else if problem.Mesh, err = scanDir("downloads"); err != nil {
 		log.Fatal("500 Internal Server Error: ", err)
 	}
+	problem.Solvers = solver.Names()
 	if err = tmpl.ExecuteTemplate(writer, "problem.html", &problem); err != nil {
 		log.Fatal("500 Internal Server Error: ", err)
 	}
@@ -8,8 +8,10 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"wfem/cmd/fem/fe"
 	"wfem/cmd/fem/fem"
 	"wfem/cmd/fem/params"
+	"wfem/cmd/fem/solver"
 )
 
 func resultsPageHandler(writer http.ResponseWriter, request *http.Request) {
@@ -63,6 +65,12 @@ func getParam(request *http.Request, paramName string, cond *[]condition, isDire
 							dir |= params.Y
 						case "Z":
 							dir |= params.Z
+						case "RX":
+							dir |= params.RX
+						case "RY":
+							dir |= params.RY
+						case "RZ":
+							dir |= params.RZ
 						default:
 							return dir, fmt.Errorf("invalid direction")
 						}
@@ -84,11 +92,12 @@ func getParam(request *http.Request, paramName string, cond *[]condition, isDire
 
 func resultProcessRequest(_ http.ResponseWriter, request *http.Request) error {
 	var (
-		numThreads                                                                                                 int
-		eps                                                                                                        float64
-		meshName                                                                                                   string
-		thickness, youngModulus, poissonRatio, volumeLoad, pointLoad, surfaceLoad, pressureLoad, boundaryCondition []condition
-		variables                                                                                                  map[string]float64
+		numThreads                                                                                                               int
+		eps                                                                                                                      float64
+		meshName, solverName, preconditionerName, modeName, shellName, integrationName                                           string
+		preconditioner, mode, shell, integration                                                                                 int
+		thickness, youngModulus, poissonRatio, volumeLoad, pointLoad, surfaceLoad, pressureLoad, boundaryCondition, spring, sets []condition
+		variables                                                                                                                map[string]float64
 	)
 
 	// Mesh
@@ -109,6 +118,48 @@ func resultProcessRequest(_ http.ResponseWriter, request *http.Request) error {
 	} else {
 		eps = x
 	}
+	// Solver
+	if solverName = request.FormValue("solver"); len(solverName) == 0 {
+		solverName = "sparse"
+	}
+	if !solver.IsRegistered(solverName) {
+		return fmt.Errorf("parameter 'Solver' is invalid")
+	}
+	if preconditionerName = request.FormValue("preconditioner"); len(preconditionerName) == 0 {
+		preconditionerName = "jacobi"
+	}
+	if x, err := solver.PreconditionerByName(preconditionerName); err != nil {
+		return fmt.Errorf("parameter 'Preconditioner' is invalid")
+	} else {
+		preconditioner = x
+	}
+	// Analysis mode of the 2D problem
+	if modeName = request.FormValue("mode"); len(modeName) == 0 {
+		modeName = "plane_stress"
+	}
+	if x, err := fe.Mode2DByName(modeName); err != nil {
+		return fmt.Errorf("parameter 'Analysis mode' is invalid")
+	} else {
+		mode = x
+	}
+	// Formulation of the shell elements
+	if shellName = request.FormValue("shell"); len(shellName) == 0 {
+		shellName = "mindlin"
+	}
+	if x, err := fe.ShellFormulationByName(shellName); err != nil {
+		return fmt.Errorf("parameter 'Shell formulation' is invalid")
+	} else {
+		shell = x
+	}
+	// Integration rule of the elements
+	if integrationName = request.FormValue("integration"); len(integrationName) == 0 {
+		integrationName = "full"
+	}
+	if x, err := fe.IntegrationByName(integrationName); err != nil {
+		return fmt.Errorf("parameter 'Integration' is invalid")
+	} else {
+		integration = x
+	}
 	// Variables
 	fields := strings.Split(request.FormValue("variables"), "\n")
 	variables = map[string]float64{}
@@ -129,6 +180,10 @@ func resultProcessRequest(_ http.ResponseWriter, request *http.Request) error {
 		}
 	}
 
+	// Named sets of the nodes and elements
+	if _, err := getParam(request, "sets", &sets, false); err != nil {
+		return err
+	}
 	// Young modulus
 	if ok, err := getParam(request, "young_modulus", &youngModulus, false); err != nil {
 		return err
@@ -169,6 +224,10 @@ func resultProcessRequest(_ http.ResponseWriter, request *http.Request) error {
 	} else if !ok {
 		return fmt.Errorf("parameter 'Boundary condition' is invalid")
 	}
+	// Grounded springs
+	if _, err := getParam(request, "spring", &spring, true); err != nil {
+		return err
+	}
 
 	f := fem.NewStaticFEM()
 	err := f.SetMesh("downloads/" + meshName)
@@ -177,6 +236,12 @@ func resultProcessRequest(_ http.ResponseWriter, request *http.Request) error {
 	}
 	f.SetNumThread(numThreads)
 	f.SetEps(eps)
+	f.SetMode2D(mode)
+	f.SetShellFormulation(shell)
+	f.SetIntegration(integration)
+	if err = f.SetSolver(solverName, solver.WithPreconditioner(preconditioner)); err != nil {
+		return err
+	}
 	for i := range youngModulus {
 		f.AddYoungModulus(youngModulus[i].Value, youngModulus[i].Predicate)
 	}
@@ -201,15 +266,26 @@ func resultProcessRequest(_ http.ResponseWriter, request *http.Request) error {
 	for i := range boundaryCondition {
 		f.AddBoundaryCondition(boundaryCondition[i].Value, boundaryCondition[i].Predicate, boundaryCondition[i].Direction)
 	}
+	for i := range spring {
+		f.AddSpring(spring[i].Value, spring[i].Predicate, spring[i].Direction)
+	}
 	for name, value := range variables {
 		f.AddVariable(name, value)
 	}
+	for i := range sets {
+		if err = f.DefineSet(sets[i].Value, sets[i].Predicate); err != nil {
+			return err
+		}
+	}
 
-	problem := problemInfo{Mesh: []string{meshName}, Threads: numThreads, Eps: eps, YoungModulus: strCondition(&youngModulus),
+	problem := problemInfo{Mesh: []string{meshName}, Threads: numThreads, Eps: eps, Solver: solverName,
+		Preconditioner: preconditionerName, Mode: modeName, Shell: shellName,
+		Integration: integrationName, YoungModulus: strCondition(&youngModulus),
 		PoissonRatio: strCondition(&poissonRatio), Thickness: strCondition(&thickness),
 		VolumeLoad: strCondition(&volumeLoad), SurfaceLoad: strCondition(&surfaceLoad),
 		PointLoad: strCondition(&pointLoad), PressureLoad: strCondition(&pressureLoad),
-		BoundaryCondition: strCondition(&boundaryCondition), Variables: strVariables(&variables),
+		BoundaryCondition: strCondition(&boundaryCondition), Spring: strCondition(&spring), Variables: strVariables(&variables),
+		Sets: strCondition(&sets),
 	}
 	if err = saveJson(&problem); err != nil {
 		return err
@@ -225,10 +301,11 @@ func resultProcessRequest(_ http.ResponseWriter, request *http.Request) error {
 		return r
 	}(f.GetResult(), f.ResultNames()); res != nil {
 		rep = report{DateTime: time.Now().Format("01-02-2006 15:04:05"), FeName: f.GetMesh().FeName(),
-			NumFE: f.GetMesh().NumFE(), NumVertex: f.GetMesh().NumVertex(), YoungModulus: youngModulus,
+			NumFE: f.GetMesh().NumFE(), NumVertex: f.GetMesh().NumVertex(), Solver: solverName, Mode: modeName, Shell: shellName,
+			Integration: integrationName, YoungModulus: youngModulus,
 			PoissonRatio: poissonRatio, VolumeLoad: volumeLoad, SurfaceLoad: surfaceLoad, PointLoad: pointLoad,
-			PressureLoad: pressureLoad, BoundaryCondition: boundaryCondition, Variables: variables, Mesh: problem.Mesh[0],
-			Res: res}
+			PressureLoad: pressureLoad, BoundaryCondition: boundaryCondition, Spring: spring, Sets: sets, Variables: variables, Mesh: problem.Mesh[0],
+			MeshSets: strings.Join(f.GetMesh().SetNames(), ", "), Res: res}
 	}
 	return nil
 }